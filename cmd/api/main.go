@@ -4,11 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/barcode"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/fiscal"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/googlesheets"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/novaposhta"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/payments"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/pdf"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/storage"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
@@ -19,6 +28,7 @@ import (
 	_ "github.com/horoshi10v/tires-shop/docs"
 
 	"github.com/horoshi10v/tires-shop/internal/config"
+	"github.com/horoshi10v/tires-shop/internal/domain"
 	"github.com/horoshi10v/tires-shop/internal/repository/models"
 	"github.com/horoshi10v/tires-shop/internal/repository/pg"
 	"github.com/horoshi10v/tires-shop/internal/service"
@@ -64,6 +74,15 @@ func main() {
 		&models.Order{},
 		&models.OrderItem{},
 		&models.OrderMessage{},
+		&models.OrderNote{},
+		&models.Customer{},
+		&models.OrderReturn{},
+		&models.OrderReturnItem{},
+		&models.Shipment{},
+		&models.Payment{},
+		&models.OrderLedgerEntry{},
+		&models.FiscalReceipt{},
+		&models.PromoCode{},
 		&models.AdminNotification{},
 		&models.AuditLog{},
 		&models.User{},
@@ -71,6 +90,11 @@ func main() {
 		&models.TransferItem{},
 		&models.SearchSuggestionStat{},
 		&models.LotAnalyticsEvent{},
+		&models.StorageContract{},
+		&models.WarehouseLocation{},
+		&models.LotLocationMove{},
+		&models.PhotoAsset{},
+		&models.PhotoUploadSession{},
 	); err != nil {
 		log.Error("migration failed", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -100,7 +124,7 @@ func main() {
 	clientBotSender := telegram.NewSender(log, cfg.Auth.ClientTelegramBotToken)
 	adminBotSender := telegram.NewSender(log, cfg.Auth.TelegramBotToken)
 
-	if err := telegram.EnsureWebhook(cfg.Auth.ClientTelegramBotToken, cfg.Telegram.ClientBotWebhookURL); err != nil {
+	if err := telegram.EnsureWebhook(cfg.Auth.ClientTelegramBotToken, cfg.Telegram.ClientBotWebhookURL, cfg.Telegram.ClientBotWebhookSecret); err != nil {
 		log.Error("failed to ensure client bot webhook", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
@@ -108,19 +132,36 @@ func main() {
 		log.Info("client bot webhook ensured", slog.String("url", cfg.Telegram.ClientBotWebhookURL))
 	}
 
-	qrGenerator := qrcode.NewQRGenerator()
+	qrGenerator := qrcode.NewQRGenerator(qrcode.LinkConfig{
+		URLTemplate: cfg.QRLinks.URLTemplate,
+		BotUsername: cfg.QRLinks.ClientBotUsername,
+		MiniAppName: cfg.QRLinks.MiniAppName,
+	})
 
-	minioStorage, err := storage.NewMinioStorage(
-		cfg.Storage.Endpoint,
-		cfg.Storage.AccessKey,
-		cfg.Storage.SecretKey,
-		cfg.Storage.BucketName,
-		cfg.Storage.PublicURL,
-		cfg.Storage.UseSSL,
-		log,
-	)
+	labelRenderer, err := pdf.NewLabelRenderer(cfg.Labels.FontPath, cfg.Labels.TemplatesPath)
+	if err != nil {
+		log.Error("failed to init label renderer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	documentRenderer, err := pdf.NewDocumentRenderer(cfg.Labels.FontPath, pdf.CompanyDetails{
+		Name:           cfg.Documents.CompanyName,
+		TaxID:          cfg.Documents.CompanyTaxID,
+		Address:        cfg.Documents.CompanyAddress,
+		Phone:          cfg.Documents.CompanyPhone,
+		IBAN:           cfg.Documents.CompanyIBAN,
+		Bank:           cfg.Documents.CompanyBank,
+		WarrantyMonths: cfg.Documents.WarrantyMonths,
+		WarrantyTerms:  cfg.Documents.WarrantyTerms,
+	})
 	if err != nil {
-		log.Error("failed to init minio storage", slog.String("error", err.Error()))
+		log.Error("failed to init document renderer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	photoStorage, err := newPhotoStorage(cfg, log)
+	if err != nil {
+		log.Error("failed to init photo storage", slog.String("driver", cfg.Storage.Driver), slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
@@ -141,18 +182,104 @@ func main() {
 	userService := service.NewUserService(userRepo, log) // Added
 	userHandler := v1.NewUserHandler(userService)        // Added
 
+	photoAssetRepo := pg.NewPhotoAssetRepository(db)
+	photoUploadSessionRepo := pg.NewPhotoUploadSessionRepository(db)
+	photoService := service.NewPhotoService(photoStorage, photoAssetRepo, photoUploadSessionRepo, log, service.PhotoOptions{
+		OrphanGrace:   cfg.Images.OrphanGrace,
+		SweepInterval: cfg.Images.SweepInterval,
+		PresignTTL:    cfg.Images.PresignTTL,
+		MaxUploadSize: cfg.Images.MaxUploadSize,
+		Workers:       cfg.Images.Workers,
+	})
+	photoService.Start(context.Background())
+
 	lotRepo := pg.NewLotRepository(db)
-	lotService := service.NewLotService(lotRepo, log, qrGenerator)
+	lotService := service.NewLotService(lotRepo, log, qrGenerator, barcode.NewCode128Generator(), photoService)
 	lotHandler := v1.NewLotHandler(lotService)
-	uploadHandler := v1.NewUploadHandler(minioStorage)
+	labelService := service.NewLabelService(lotRepo, qrGenerator, labelRenderer, log)
+	labelHandler := v1.NewLabelHandler(labelService)
+	uploadHandler := v1.NewUploadHandler(photoService)
 
 	orderRepo := pg.NewOrderRepository(db)
 	adminNotificationRepo := pg.NewAdminNotificationRepository(db)
 	adminNotificationService := service.NewAdminNotificationService(adminNotificationRepo, userRepo, adminBotSender, log)
-	orderService := service.NewOrderService(orderRepo, log, tgNotifier, clientBotSender, adminNotificationService)
-	orderHandler := v1.NewOrderHandler(orderService)
+	orderDocumentService := service.NewOrderDocumentService(orderRepo, documentRenderer, clientBotSender, log)
+	orderDocumentHandler := v1.NewOrderDocumentHandler(orderDocumentService)
+	orderLedgerRepo := pg.NewOrderLedgerRepository(db)
+	fiscalReceiptRepo := pg.NewFiscalReceiptRepository(db)
+	fiscalService := service.NewFiscalService(
+		fiscal.NewCheckbox(cfg.Fiscal.APIURL, fiscal.Credentials{
+			LicenseKey: cfg.Fiscal.LicenseKey,
+			Login:      cfg.Fiscal.CashierLogin,
+			Password:   cfg.Fiscal.CashierPassword,
+		}, log),
+		fiscalReceiptRepo,
+		orderLedgerRepo,
+		tgNotifier,
+		log,
+		service.FiscalOptions{
+			Enabled:       cfg.Fiscal.LicenseKey != "" && cfg.Fiscal.CashierLogin != "",
+			RetryInterval: cfg.Fiscal.RetryInterval,
+			MaxAttempts:   cfg.Fiscal.MaxAttempts,
+			AutoOpenShift: cfg.Fiscal.AutoOpenShift,
+		},
+	)
+	fiscalService.Start(context.Background())
+	fiscalHandler := v1.NewFiscalHandler(fiscalService)
+	orderAssignmentService := service.NewOrderAssignmentService(
+		pg.NewOrderAssignmentRepository(db),
+		orderRepo,
+		userRepo,
+		adminNotificationService,
+		adminBotSender,
+		log,
+		service.OrderSLAOptions{
+			SLA: domain.OrderSLA{
+				FirstResponse: cfg.OrderSLA.FirstResponse,
+				Processing:    cfg.OrderSLA.Processing,
+			},
+			AutoAssign:    cfg.OrderSLA.AutoAssign,
+			CheckInterval: cfg.OrderSLA.CheckInterval,
+		},
+	)
+	orderAssignmentService.Start(context.Background())
+	orderAssignmentHandler := v1.NewOrderAssignmentHandler(orderAssignmentService)
+	orderNoteService := service.NewOrderNoteService(pg.NewOrderNoteRepository(db), orderRepo, userRepo, adminBotSender, log)
+	orderNoteHandler := v1.NewOrderNoteHandler(orderNoteService)
+	customerService := service.NewCustomerService(pg.NewCustomerRepository(db), orderRepo, log)
+	customerService.Start(context.Background())
+	customerHandler := v1.NewCustomerHandler(customerService)
+	buyerNotificationService := service.NewBuyerNotificationService(orderRepo, clientBotSender, log, newBuyerNotificationOptions(cfg))
+	orderService := service.NewOrderService(orderRepo, log, tgNotifier, clientBotSender, adminNotificationService, orderDocumentService, fiscalService, orderAssignmentService, customerService, buyerNotificationService, newBuyerCancelPolicy(cfg))
 	adminNotificationHandler := v1.NewAdminNotificationHandler(adminNotificationService)
 
+	paymentRepo := pg.NewPaymentRepository(db)
+	paymentService := service.NewPaymentService(newPaymentProviders(cfg, log), paymentRepo, orderRepo, orderService, tgNotifier, clientBotSender, log, service.PaymentOptions{
+		CallbackBaseURL:   cfg.Payments.CallbackBaseURL,
+		ResultURL:         cfg.Payments.ResultURL,
+		LinkTTL:           cfg.Payments.LinkTTL,
+		PrepaymentPercent: cfg.Payments.PrepaymentPercent,
+	})
+	paymentHandler := v1.NewPaymentHandler(paymentService)
+
+	orderLedgerService := service.NewOrderLedgerService(orderLedgerRepo, tgNotifier, log)
+	orderLedgerHandler := v1.NewOrderLedgerHandler(orderLedgerService)
+	orderHandler := v1.NewOrderHandler(orderService, paymentService, cfg.Telegram.ClientBotWebhookSecret)
+
+	shippingProvider := novaposhta.NewClient(cfg.NovaPoshta.APIURL, cfg.NovaPoshta.APIKey, novaposhta.Sender{
+		Ref:        cfg.NovaPoshta.SenderRef,
+		ContactRef: cfg.NovaPoshta.SenderContactRef,
+		Phone:      cfg.NovaPoshta.SenderPhone,
+		CityRef:    cfg.NovaPoshta.SenderCityRef,
+		AddressRef: cfg.NovaPoshta.SenderAddressRef,
+	}, log)
+	shipmentRepo := pg.NewShipmentRepository(db)
+	shippingService := service.NewShippingService(shippingProvider, shipmentRepo, orderRepo, lotRepo, tgNotifier, clientBotSender, log, cfg.NovaPoshta.TrackInterval)
+	if cfg.NovaPoshta.APIKey != "" {
+		shippingService.Start(context.Background())
+	}
+	shippingHandler := v1.NewShippingHandler(shippingService)
+
 	reportRepo := pg.NewReportRepository(db)
 	reportService := service.NewReportService(reportRepo, log)
 	reportHandler := v1.NewReportHandler(reportService)
@@ -162,14 +289,38 @@ func main() {
 	auditHandler := v1.NewAuditHandler(auditService)
 
 	transferRepo := pg.NewTransferRepository(db)
-	transferService := service.NewTransferService(transferRepo, log, tgNotifier)
+	transferService := service.NewTransferService(transferRepo, log, tgNotifier, qrGenerator)
 	transferHandler := v1.NewTransferHandler(transferService)
 
 	warehouseRepo := pg.NewWarehouseRepository(db)
 	warehouseService := service.NewWarehouseService(warehouseRepo, log)
 	warehouseHandler := v1.NewWarehouseHandler(warehouseService)
 
-	exportService := service.NewExportService(lotRepo, reportRepo, googleExporter, log)
+	warehouseLocationRepo := pg.NewWarehouseLocationRepository(db)
+	warehouseLocationService := service.NewWarehouseLocationService(warehouseLocationRepo, log, qrGenerator)
+	warehouseLocationHandler := v1.NewWarehouseLocationHandler(warehouseLocationService)
+
+	storageContractRepo := pg.NewStorageContractRepository(db)
+	storageContractService := service.NewStorageContractService(
+		storageContractRepo,
+		photoService,
+		qrGenerator,
+		clientBotSender,
+		log,
+		cfg.StorageContracts.ReminderLead,
+		cfg.StorageContracts.ReminderInterval,
+	)
+	storageContractService.Start(context.Background())
+	storageContractHandler := v1.NewStorageContractHandler(storageContractService)
+
+	scanService := service.NewScanService(lotRepo, transferRepo, storageContractRepo, warehouseLocationRepo, log)
+	scanHandler := v1.NewScanHandler(scanService)
+
+	promoCodeRepo := pg.NewPromoCodeRepository(db)
+	promoCodeService := service.NewPromoCodeService(promoCodeRepo, log)
+	promoCodeHandler := v1.NewPromoCodeHandler(promoCodeService)
+
+	exportService := service.NewExportService(lotRepo, reportRepo, orderRepo, googleExporter, log)
 	exportHandler := v1.NewExportHandler(exportService)
 
 	// Router Setup
@@ -185,6 +336,17 @@ func main() {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// The local storage driver serves photos itself under the path of STORAGE_LOCAL_PUBLIC_URL.
+	// Only "lots" is exposed; masters and raw uploads stay private.
+	if cfg.Storage.Driver == storageDriverLocal {
+		mediaURL, err := url.Parse(cfg.Storage.LocalPublicURL)
+		if err != nil || mediaURL.Path == "" || mediaURL.Path == "/" {
+			log.Error("STORAGE_LOCAL_PUBLIC_URL must contain a path, e.g. http://localhost:8083/media")
+			os.Exit(1)
+		}
+		router.Static(path.Join(mediaURL.Path, "lots"), filepath.Join(cfg.Storage.LocalDir, "lots"))
+	}
+
 	publicAPI := router.Group("/api/v1")
 	{
 		publicAPI.GET("/lots", lotHandler.ListPublic)
@@ -193,13 +355,16 @@ func main() {
 		publicAPI.POST("/lots/analytics/events", lotHandler.TrackPublicAnalyticsEvent)
 		publicAPI.POST("/auth/telegram", authHandler.LoginTelegram)
 		publicAPI.POST("/telegram/client/webhook", orderHandler.HandleClientBotWebhook)
+		publicAPI.POST("/payments/:provider/callback", paymentHandler.Callback)
 		publicAPI.POST("/orders", middleware.OptionalAuth(cfg.Auth.JWTSecret), orderHandler.Create)
+		publicAPI.GET("/scan/:code", middleware.OptionalAuth(cfg.Auth.JWTSecret), scanHandler.Resolve)
 	}
 
 	clientAPI := router.Group("/api/v1")
 	clientAPI.Use(middleware.RequireRole(cfg.Auth.JWTSecret, "BUYER", "STAFF", "ADMIN"))
 	{
 		clientAPI.GET("/orders", orderHandler.ListMyOrders)
+		clientAPI.POST("/orders/:id/cancel", orderHandler.CancelMyOrder)
 	}
 
 	// Staff Routes
@@ -213,19 +378,72 @@ func main() {
 		staffAPI.PUT("/lots/:id", lotHandler.Update)
 		staffAPI.DELETE("/lots/:id", lotHandler.Delete)
 		staffAPI.GET("/lots/:id/qr", lotHandler.GetQR)
+		staffAPI.GET("/lots/:id/barcode", lotHandler.GetBarcode)
+		staffAPI.GET("/lots/by-barcode/:code", lotHandler.FindByBarcode)
+		staffAPI.GET("/lots/prefill", lotHandler.PrefillFromBarcode)
+		staffAPI.GET("/lots/:id/label", labelHandler.GetLotLabel)
+		staffAPI.POST("/lots/labels", labelHandler.PrintLabels)
+		staffAPI.GET("/labels/templates", labelHandler.ListTemplates)
+		staffAPI.POST("/lots/:id/move", warehouseLocationHandler.MoveLot)
+		staffAPI.GET("/lots/:id/moves", warehouseLocationHandler.ListLotMoves)
 		staffAPI.GET("/orders", orderHandler.List)
+		staffAPI.GET("/orders/export", exportHandler.ExportOrders)
 		staffAPI.PATCH("/orders/:id/status", orderHandler.UpdateStatus)
+		staffAPI.GET("/orders/:id/transitions", orderHandler.ListTransitions)
+		staffAPI.PUT("/orders/:id/assignee", orderAssignmentHandler.Assign)
+		staffAPI.POST("/orders/:id/items", orderHandler.AddItem)
+		staffAPI.PATCH("/orders/:id/items/:itemId", orderHandler.UpdateItem)
+		staffAPI.DELETE("/orders/:id/items/:itemId", orderHandler.RemoveItem)
 		staffAPI.PATCH("/orders/:id/items/:itemId/price", orderHandler.UpdateItemPrice)
+		staffAPI.POST("/orders/:id/returns", orderHandler.CreateReturn)
+		staffAPI.GET("/orders/:id/returns", orderHandler.ListReturns)
+		staffAPI.POST("/orders/:id/shipment", shippingHandler.CreateShipment)
+		staffAPI.GET("/orders/:id/shipment", shippingHandler.GetShipment)
+		staffAPI.POST("/orders/:id/shipment/refresh", shippingHandler.RefreshShipment)
+		staffAPI.POST("/orders/:id/payments", paymentHandler.CreatePayment)
+		staffAPI.GET("/orders/:id/payments", paymentHandler.ListPayments)
+		staffAPI.GET("/payments/providers", paymentHandler.ListProviders)
+		staffAPI.POST("/orders/:id/ledger", orderLedgerHandler.CreateEntry)
+		staffAPI.GET("/orders/:id/ledger", orderLedgerHandler.GetLedger)
+		staffAPI.GET("/orders/:id/documents/:type", orderDocumentHandler.GetDocument)
+		staffAPI.POST("/orders/:id/documents/:type/send", orderDocumentHandler.SendDocument)
+		staffAPI.GET("/fiscal/shift", fiscalHandler.GetShift)
+		staffAPI.POST("/fiscal/shift/open", fiscalHandler.OpenShift)
+		staffAPI.POST("/fiscal/shift/close", fiscalHandler.CloseShift)
+		staffAPI.GET("/fiscal/receipts", fiscalHandler.ListReceipts)
+		staffAPI.POST("/fiscal/receipts/:id/retry", fiscalHandler.RetryReceipt)
+		staffAPI.GET("/shipping/cities", shippingHandler.SearchCities)
+		staffAPI.GET("/shipping/branches", shippingHandler.ListBranches)
 		staffAPI.POST("/orders/:id/message", orderHandler.SendMessage)
 		staffAPI.GET("/orders/:id/messages", orderHandler.ListMessages)
+		staffAPI.GET("/customers", customerHandler.List)
+		staffAPI.GET("/customers/:id", customerHandler.Get)
+		staffAPI.PATCH("/customers/:id", customerHandler.Update)
+		staffAPI.GET("/customers/:id/orders", customerHandler.ListOrders)
+		staffAPI.POST("/customers/:id/merge", customerHandler.Merge)
+		staffAPI.POST("/orders/:id/notes", orderNoteHandler.AddNote)
+		staffAPI.GET("/orders/:id/notes", orderNoteHandler.ListNotes)
+		staffAPI.GET("/orders/:id/timeline", orderNoteHandler.GetTimeline)
 		staffAPI.GET("/transfers", transferHandler.List)
 		staffAPI.GET("/transfers/:id", transferHandler.GetByID)
+		staffAPI.GET("/transfers/:id/qr", transferHandler.GetQR)
 		staffAPI.POST("/transfers", transferHandler.Create)
 		staffAPI.POST("/transfers/:id/accept", transferHandler.Accept)
 		staffAPI.POST("/transfers/:id/cancel", transferHandler.Cancel)
 		staffAPI.GET("/warehouses", warehouseHandler.List)
+		staffAPI.GET("/warehouses/:id/locations", warehouseLocationHandler.List)
+		staffAPI.GET("/warehouse-locations/:id/qr", warehouseLocationHandler.GetQR)
 		staffAPI.POST("/lots/upload", uploadHandler.UploadPhoto)
+		staffAPI.POST("/lots/uploads/presign", uploadHandler.PresignUpload)
+		staffAPI.POST("/lots/uploads/:id/finalize", uploadHandler.FinalizeUpload)
+		staffAPI.GET("/lots/uploads/:id", uploadHandler.GetUpload)
 		staffAPI.DELETE("/lots/photo", uploadHandler.DeletePhoto)
+		staffAPI.GET("/storage-contracts", storageContractHandler.List)
+		staffAPI.POST("/storage-contracts", storageContractHandler.Create)
+		staffAPI.GET("/storage-contracts/:id", storageContractHandler.GetByID)
+		staffAPI.PUT("/storage-contracts/:id", storageContractHandler.Update)
+		staffAPI.POST("/storage-contracts/:id/return", storageContractHandler.Return)
+		staffAPI.GET("/storage-contracts/:id/qr", storageContractHandler.GetQR)
 	}
 
 	// Admin Routes
@@ -237,11 +455,26 @@ func main() {
 		adminAPI.POST("/warehouses", warehouseHandler.Create)
 		adminAPI.PUT("/warehouses/:id", warehouseHandler.Update)
 		adminAPI.DELETE("/warehouses/:id", warehouseHandler.Delete)
+		adminAPI.POST("/warehouses/:id/locations", warehouseLocationHandler.Create)
+		adminAPI.PUT("/warehouse-locations/:id", warehouseLocationHandler.Update)
+		adminAPI.DELETE("/warehouse-locations/:id", warehouseLocationHandler.Delete)
+		adminAPI.DELETE("/storage-contracts/:id", storageContractHandler.Delete)
+		adminAPI.GET("/photos/orphans", uploadHandler.ListOrphans)
+		adminAPI.POST("/photos/orphans/sweep", uploadHandler.SweepOrphans)
+		adminAPI.GET("/photos/orphans/unregistered", uploadHandler.ListUnregisteredOrphans)
+		adminAPI.POST("/photos/orphans/unregistered/sweep", uploadHandler.SweepUnregisteredOrphans)
+		adminAPI.POST("/photos/reprocess", uploadHandler.StartReprocess)
+		adminAPI.GET("/photos/reprocess", uploadHandler.GetReprocessStatus)
 		adminAPI.GET("/exports/inventory", exportHandler.ExportInventory)
 		adminAPI.GET("/exports/pnl", exportHandler.ExportPnL)
 		adminAPI.GET("/audit-logs", auditHandler.ListAuditLogs)
 		adminAPI.GET("/notifications", adminNotificationHandler.List)
 		adminAPI.POST("/notifications/:id/read", adminNotificationHandler.MarkRead)
+		adminAPI.GET("/promo-codes", promoCodeHandler.List)
+		adminAPI.POST("/promo-codes", promoCodeHandler.Create)
+		adminAPI.GET("/promo-codes/:id", promoCodeHandler.GetByID)
+		adminAPI.PUT("/promo-codes/:id", promoCodeHandler.Update)
+		adminAPI.GET("/promo-codes/:id/usage", promoCodeHandler.GetUsage)
 
 		// User Management Routes
 		adminAPI.GET("/users", userHandler.ListUsers)
@@ -267,6 +500,96 @@ func main() {
 	}
 }
 
+const (
+	storageDriverMinio = "minio"
+	storageDriverLocal = "local"
+)
+
+// newPaymentProviders enables every provider whose credentials are configured. Telegram Payments also
+// needs the webhook secret, because its updates are not signed otherwise.
+func newPaymentProviders(cfg *config.Config, log *slog.Logger) []domain.PaymentProvider {
+	var providers []domain.PaymentProvider
+	if cfg.Payments.LiqPayPublicKey != "" && cfg.Payments.LiqPayPrivateKey != "" {
+		providers = append(providers, payments.NewLiqPay(cfg.Payments.LiqPayCheckoutURL, cfg.Payments.LiqPayPublicKey, cfg.Payments.LiqPayPrivateKey))
+	}
+	if cfg.Payments.MonobankToken != "" {
+		providers = append(providers, payments.NewMonobank(cfg.Payments.MonobankAPIURL, cfg.Payments.MonobankToken, log))
+	}
+	if cfg.Payments.TelegramProviderToken != "" {
+		if cfg.Telegram.ClientBotWebhookSecret == "" {
+			log.Warn("telegram payments need CLIENT_BOT_WEBHOOK_SECRET, provider disabled")
+		} else {
+			providers = append(providers, payments.NewTelegram(cfg.Payments.TelegramAPIURL, cfg.Auth.ClientTelegramBotToken, cfg.Payments.TelegramProviderToken, cfg.Telegram.ClientBotWebhookSecret, log))
+		}
+	}
+	return providers
+}
+
+// newBuyerNotificationOptions reads the buyer status messages settings. Unknown statuses in the disabled
+// list are ignored.
+func newBuyerNotificationOptions(cfg *config.Config) service.BuyerNotificationOptions {
+	opts := service.BuyerNotificationOptions{
+		PickupAddress: cfg.BuyerNotifications.PickupAddress,
+		ContactPhone:  cfg.Documents.CompanyPhone,
+	}
+	if opts.PickupAddress == "" {
+		opts.PickupAddress = cfg.Documents.CompanyAddress
+	}
+	for _, status := range cfg.BuyerNotifications.DisabledStatuses {
+		opts.Disabled = append(opts.Disabled, domain.OrderStatus(strings.ToUpper(strings.TrimSpace(status))))
+	}
+	return opts
+}
+
+// newBuyerCancelPolicy reads the statuses and time windows buyers may cancel their orders in.
+func newBuyerCancelPolicy(cfg *config.Config) domain.BuyerCancelPolicy {
+	policy := make(domain.BuyerCancelPolicy, len(cfg.BuyerCancel.Windows))
+	for status, window := range cfg.BuyerCancel.Windows {
+		policy[domain.OrderStatus(strings.ToUpper(strings.TrimSpace(status)))] = window
+	}
+	return policy
+}
+
+// newPhotoStorage picks the StorageService implementation. The local driver lets developers
+// and single-box deployments run without MinIO.
+func newPhotoStorage(cfg *config.Config, log *slog.Logger) (domain.StorageService, error) {
+	images := storage.ImageOptions{}
+	if cfg.Images.WatermarkPath != "" {
+		watermark, err := storage.LoadWatermark(
+			cfg.Images.WatermarkPath,
+			cfg.Images.WatermarkPosition,
+			cfg.Images.WatermarkOpacity,
+			cfg.Images.WatermarkScale,
+		)
+		if err != nil {
+			return nil, err
+		}
+		images.Watermark = watermark
+	}
+
+	switch cfg.Storage.Driver {
+	case storageDriverLocal:
+		return storage.NewLocalStorage(cfg.Storage.LocalDir, cfg.Storage.LocalPublicURL, images, log)
+	case storageDriverMinio, "":
+		if cfg.Storage.AccessKey == "" || cfg.Storage.SecretKey == "" {
+			return nil, fmt.Errorf("MINIO_ACCESS_KEY and MINIO_SECRET_KEY are required for the minio driver")
+		}
+		return storage.NewMinioStorage(
+			cfg.Storage.Endpoint,
+			cfg.Storage.AccessKey,
+			cfg.Storage.SecretKey,
+			cfg.Storage.BucketName,
+			cfg.Storage.PublicURL,
+			cfg.Storage.Region,
+			cfg.Storage.UseSSL,
+			images,
+			log,
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
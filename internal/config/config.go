@@ -15,6 +15,17 @@ type Config struct {
 	Auth                `yaml:"auth"`
 	Telegram            `yaml:"telegram"`
 	Storage             `yaml:"storage"`
+	Images              `yaml:"images"`
+	StorageContracts    `yaml:"storage_contracts"`
+	Labels              `yaml:"labels"`
+	QRLinks             `yaml:"qr_links"`
+	NovaPoshta          `yaml:"nova_poshta"`
+	Payments            `yaml:"payments"`
+	Documents           `yaml:"documents"`
+	Fiscal              `yaml:"fiscal"`
+	OrderSLA            `yaml:"order_sla"`
+	BuyerNotifications  `yaml:"buyer_notifications"`
+	BuyerCancel         `yaml:"buyer_cancel"`
 	GoogleSpreadsheetID string `yaml:"google_spreadsheet_id" env:"GOOGLE_SPREADSHEET_ID"`
 }
 
@@ -39,18 +50,136 @@ type Auth struct {
 }
 
 type Telegram struct {
-	ClientBotWebhookURL string `yaml:"client_bot_webhook_url" env:"CLIENT_BOT_WEBHOOK_URL"`
+	ClientBotWebhookURL    string `yaml:"client_bot_webhook_url" env:"CLIENT_BOT_WEBHOOK_URL"`
+	ClientBotWebhookSecret string `yaml:"client_bot_webhook_secret" env:"CLIENT_BOT_WEBHOOK_SECRET"` // Checked on every client bot update, required for Telegram Payments
 }
 
 type Storage struct {
+	Driver         string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"minio"` // minio or local
+	LocalDir       string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR" env-default:"./data/media"`
+	LocalPublicURL string `yaml:"local_public_url" env:"STORAGE_LOCAL_PUBLIC_URL" env-default:"http://localhost:8083/media"`
+
 	Endpoint   string `yaml:"endpoint" env:"MINIO_ENDPOINT" env-default:"localhost:9000"`
-	AccessKey  string `yaml:"access_key" env:"MINIO_ACCESS_KEY" env-required:"true"`
-	SecretKey  string `yaml:"secret_key" env:"MINIO_SECRET_KEY" env-required:"true"`
+	AccessKey  string `yaml:"access_key" env:"MINIO_ACCESS_KEY"` // Required for the minio driver
+	SecretKey  string `yaml:"secret_key" env:"MINIO_SECRET_KEY"`
 	BucketName string `yaml:"bucket_name" env:"MINIO_BUCKET_NAME" env-default:"tires-shop"`
 	PublicURL  string `yaml:"public_url" env:"MINIO_PUBLIC_URL" env-default:"http://localhost:9000"`
+	Region     string `yaml:"region" env:"MINIO_REGION" env-default:"us-east-1"`
 	UseSSL     bool   `yaml:"use_ssl" env:"MINIO_USE_SSL" env-default:"false"`
 }
 
+// Images configures the photo rendition pipeline and the orphaned upload sweeper.
+type Images struct {
+	OrphanGrace   time.Duration `yaml:"orphan_grace" env:"PHOTO_ORPHAN_GRACE" env-default:"48h"`
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"PHOTO_SWEEP_INTERVAL" env-default:"6h"`
+	PresignTTL    time.Duration `yaml:"presign_ttl" env:"PHOTO_PRESIGN_TTL" env-default:"15m"`
+	MaxUploadSize int64         `yaml:"max_upload_size" env:"PHOTO_MAX_UPLOAD_BYTES" env-default:"20971520"`
+	Workers       int           `yaml:"workers" env:"PHOTO_PROCESSING_WORKERS" env-default:"2"`
+
+	WatermarkPath     string  `yaml:"watermark_path" env:"WATERMARK_PATH"` // Logo image, watermarking is off when empty
+	WatermarkPosition string  `yaml:"watermark_position" env:"WATERMARK_POSITION" env-default:"bottom-right"`
+	WatermarkOpacity  float64 `yaml:"watermark_opacity" env:"WATERMARK_OPACITY" env-default:"0.5"`
+	WatermarkScale    float64 `yaml:"watermark_scale" env:"WATERMARK_SCALE" env-default:"0.2"` // Logo width relative to the photo
+}
+
+// StorageContracts configures reminders for the seasonal tire storage ("tire hotel").
+type StorageContracts struct {
+	ReminderLead     time.Duration `yaml:"reminder_lead" env:"STORAGE_REMINDER_LEAD" env-default:"336h"`
+	ReminderInterval time.Duration `yaml:"reminder_interval" env:"STORAGE_REMINDER_INTERVAL" env-default:"6h"`
+}
+
+// Labels configures PDF price tag rendering.
+type Labels struct {
+	FontPath      string `yaml:"font_path" env:"PDF_FONT_PATH"`             // UTF-8 TTF font, required to print Cyrillic as is
+	TemplatesPath string `yaml:"templates_path" env:"LABEL_TEMPLATES_PATH"` // Optional JSON file with extra templates
+}
+
+// QRLinks configures what printed QR codes open when scanned with a phone camera.
+type QRLinks struct {
+	URLTemplate       string `yaml:"url_template" env:"QR_LINK_TEMPLATE"`            // e.g. https://shop.example.com/scan/{code}
+	ClientBotUsername string `yaml:"client_bot_username" env:"CLIENT_BOT_USERNAME"`  // Used for t.me deep links
+	MiniAppName       string `yaml:"mini_app_name" env:"CLIENT_MINI_APP_SHORT_NAME"` // Optional direct Mini App link name
+}
+
+// NovaPoshta configures the shipping integration. The API URL can point to a local fake for tests.
+type NovaPoshta struct {
+	APIURL           string        `yaml:"api_url" env:"NOVA_POSHTA_API_URL" env-default:"https://api.novaposhta.ua/v2.0/json/"`
+	APIKey           string        `yaml:"api_key" env:"NOVA_POSHTA_API_KEY"` // Shipping endpoints answer 503 when empty
+	SenderRef        string        `yaml:"sender_ref" env:"NOVA_POSHTA_SENDER_REF"`
+	SenderContactRef string        `yaml:"sender_contact_ref" env:"NOVA_POSHTA_SENDER_CONTACT_REF"`
+	SenderPhone      string        `yaml:"sender_phone" env:"NOVA_POSHTA_SENDER_PHONE"`
+	SenderCityRef    string        `yaml:"sender_city_ref" env:"NOVA_POSHTA_SENDER_CITY_REF"`
+	SenderAddressRef string        `yaml:"sender_address_ref" env:"NOVA_POSHTA_SENDER_ADDRESS_REF"`
+	TrackInterval    time.Duration `yaml:"track_interval" env:"NOVA_POSHTA_TRACK_INTERVAL" env-default:"1h"`
+}
+
+// Payments configures payment links and acquiring providers. A provider is enabled when its credentials
+// are set. Provider URLs can point to a local stub for tests.
+type Payments struct {
+	CallbackBaseURL   string        `yaml:"callback_base_url" env:"PAYMENTS_CALLBACK_BASE_URL"` // Public URL of this API, e.g. https://api.shop.example.com
+	ResultURL         string        `yaml:"result_url" env:"PAYMENTS_RESULT_URL"`               // Where the buyer lands after paying
+	LinkTTL           time.Duration `yaml:"link_ttl" env:"PAYMENTS_LINK_TTL" env-default:"24h"`
+	PrepaymentPercent float64       `yaml:"prepayment_percent" env:"PAYMENTS_PREPAYMENT_PERCENT" env-default:"30"`
+
+	LiqPayCheckoutURL string `yaml:"liqpay_checkout_url" env:"LIQPAY_CHECKOUT_URL" env-default:"https://www.liqpay.ua/api/3/checkout"`
+	LiqPayPublicKey   string `yaml:"liqpay_public_key" env:"LIQPAY_PUBLIC_KEY"`
+	LiqPayPrivateKey  string `yaml:"liqpay_private_key" env:"LIQPAY_PRIVATE_KEY"`
+
+	MonobankAPIURL string `yaml:"monobank_api_url" env:"MONOBANK_API_URL" env-default:"https://api.monobank.ua"`
+	MonobankToken  string `yaml:"monobank_token" env:"MONOBANK_TOKEN"`
+
+	TelegramAPIURL        string `yaml:"telegram_api_url" env:"TELEGRAM_PAYMENTS_API_URL" env-default:"https://api.telegram.org"`
+	TelegramProviderToken string `yaml:"telegram_provider_token" env:"TELEGRAM_PAYMENTS_PROVIDER_TOKEN"` // From BotFather, client bot
+}
+
+// Documents configures the seller requisites printed on invoices, receipts and warranty cards.
+// The font is shared with labels (PDF_FONT_PATH).
+type Documents struct {
+	CompanyName    string `yaml:"company_name" env:"DOCUMENTS_COMPANY_NAME"`
+	CompanyTaxID   string `yaml:"company_tax_id" env:"DOCUMENTS_COMPANY_TAX_ID"` // ЄДРПОУ or ІПН
+	CompanyAddress string `yaml:"company_address" env:"DOCUMENTS_COMPANY_ADDRESS"`
+	CompanyPhone   string `yaml:"company_phone" env:"DOCUMENTS_COMPANY_PHONE"`
+	CompanyIBAN    string `yaml:"company_iban" env:"DOCUMENTS_COMPANY_IBAN"` // Printed on invoices
+	CompanyBank    string `yaml:"company_bank" env:"DOCUMENTS_COMPANY_BANK"`
+	WarrantyMonths int    `yaml:"warranty_months" env:"DOCUMENTS_WARRANTY_MONTHS" env-default:"12"`
+	WarrantyTerms  string `yaml:"warranty_terms" env:"DOCUMENTS_WARRANTY_TERMS"`
+}
+
+// Fiscal configures fiscal receipts (PRRO) for offline sales. Fiscalization is enabled when the license key
+// and the cashier login are set. The API URL can point to a local stub for tests.
+type Fiscal struct {
+	APIURL          string        `yaml:"api_url" env:"FISCAL_API_URL" env-default:"https://api.checkbox.ua/api/v1"`
+	LicenseKey      string        `yaml:"license_key" env:"FISCAL_LICENSE_KEY"` // Checkbox cash register key
+	CashierLogin    string        `yaml:"cashier_login" env:"FISCAL_CASHIER_LOGIN"`
+	CashierPassword string        `yaml:"cashier_password" env:"FISCAL_CASHIER_PASSWORD"`
+	AutoOpenShift   bool          `yaml:"auto_open_shift" env:"FISCAL_AUTO_OPEN_SHIFT" env-default:"true"`
+	RetryInterval   time.Duration `yaml:"retry_interval" env:"FISCAL_RETRY_INTERVAL" env-default:"1m"`
+	MaxAttempts     int           `yaml:"max_attempts" env:"FISCAL_MAX_ATTEMPTS" env-default:"10"`
+}
+
+// OrderSLA configures order assignment and the SLA timers, both counted from the order creation.
+// Breaches are escalated to admins once per timer.
+type OrderSLA struct {
+	FirstResponse time.Duration `yaml:"first_response" env:"ORDER_SLA_FIRST_RESPONSE" env-default:"30m"`
+	Processing    time.Duration `yaml:"processing" env:"ORDER_SLA_PROCESSING" env-default:"24h"`
+	CheckInterval time.Duration `yaml:"check_interval" env:"ORDER_SLA_CHECK_INTERVAL" env-default:"5m"`
+	AutoAssign    bool          `yaml:"auto_assign" env:"ORDER_AUTO_ASSIGN" env-default:"true"` // Round-robin across STAFF users
+}
+
+// BuyerNotifications configures the client bot messages buyers get when their order changes status.
+type BuyerNotifications struct {
+	// DisabledStatuses lists the statuses buyers are not told about, e.g. "PAID,RETURNED".
+	DisabledStatuses []string `yaml:"disabled_statuses" env:"BUYER_NOTIFICATIONS_DISABLED" env-separator:","`
+	PickupAddress    string   `yaml:"pickup_address" env:"BUYER_PICKUP_ADDRESS"` // Falls back to DOCUMENTS_COMPANY_ADDRESS
+}
+
+// BuyerCancel configures self-service cancellation from the Mini App.
+type BuyerCancel struct {
+	// Windows maps the statuses buyers may cancel from to the time allowed since the order was placed.
+	// "0" means no limit, statuses that are not listed cannot be cancelled by the buyer.
+	Windows map[string]time.Duration `yaml:"windows" env:"BUYER_CANCEL_WINDOWS" env-default:"NEW:24h,AWAITING_PAYMENT:24h,CONFIRMED:2h"`
+}
+
 func MustLoad() *Config {
 	configPath := ".env"
 
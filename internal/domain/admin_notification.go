@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,8 @@ type AdminNotificationType string
 const (
 	AdminNotificationTypeOrderCreated    AdminNotificationType = "ORDER_CREATED"
 	AdminNotificationTypeCustomerMessage AdminNotificationType = "CUSTOMER_MESSAGE"
+	AdminNotificationTypeSLABreached     AdminNotificationType = "ORDER_SLA_BREACHED"
+	AdminNotificationTypeBuyerCancelled  AdminNotificationType = "ORDER_CANCELLED_BY_BUYER"
 )
 
 type AdminNotification struct {
@@ -57,6 +60,10 @@ type AdminNotificationRepository interface {
 type AdminNotificationService interface {
 	NotifyNewOrder(ctx context.Context, order *OrderResponse) error
 	NotifyCustomerMessage(ctx context.Context, order *OrderResponse, messageText string) error
+	// NotifySLABreach escalates an order whose SLA timer ran out. assignee is nil for unassigned orders.
+	NotifySLABreach(ctx context.Context, order *OrderResponse, kind OrderSLAKind, due time.Time, assignee *User) error
+	// NotifyBuyerCancellation tells the admins a buyer cancelled their order, with the reason they gave.
+	NotifyBuyerCancellation(ctx context.Context, order *OrderResponse, reason string) error
 	List(ctx context.Context, filter AdminNotificationFilter) ([]AdminNotification, int64, error)
 	MarkRead(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidBarcode is returned for malformed EAN codes.
+	ErrInvalidBarcode = errors.New("invalid barcode")
+	// ErrAmbiguousSKU is returned when more than one lot shares the ID prefix of a SKU. The lot has to be
+	// picked by its QR code or ID instead.
+	ErrAmbiguousSKU = errors.New("sku matches more than one lot, scan the QR code instead")
+)
+
+// lotSKUPrefix marks our own SKUs printed as Code128, so they never clash with manufacturer EANs.
+const lotSKUPrefix = "TS"
+
+// LotSKU derives a short, stable SKU from the lot ID: "TS" and the first 12 hex digits of the UUID.
+func LotSKU(id uuid.UUID) string {
+	hex := strings.ReplaceAll(id.String(), "-", "")
+	return lotSKUPrefix + strings.ToUpper(hex[:12])
+}
+
+// ParseLotSKU returns the lowercase UUID prefix ("xxxxxxxx-xxxx") encoded in a SKU.
+func ParseLotSKU(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != len(lotSKUPrefix)+12 || !strings.HasPrefix(code, lotSKUPrefix) {
+		return "", false
+	}
+
+	hex := strings.ToLower(code[len(lotSKUPrefix):])
+	for _, char := range hex {
+		if (char < '0' || char > '9') && (char < 'a' || char > 'f') {
+			return "", false
+		}
+	}
+
+	return hex[:8] + "-" + hex[8:], true
+}
+
+// IsEAN reports whether the code looks like an EAN-8, UPC-A or EAN-13 barcode.
+func IsEAN(code string) bool {
+	return ValidateEAN(code) == nil
+}
+
+// ValidateEAN checks the length and the check digit of an EAN-8, UPC-A (12) or EAN-13 barcode.
+func ValidateEAN(code string) error {
+	if len(code) != 8 && len(code) != 12 && len(code) != 13 {
+		return fmt.Errorf("%w: must have 8, 12 or 13 digits", ErrInvalidBarcode)
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := code[i]
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("%w: must contain digits only", ErrInvalidBarcode)
+		}
+		// Weights alternate 3,1,3... starting from the digit next to the check digit.
+		weight := 1
+		if (len(code)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+
+	checkDigit := code[len(code)-1]
+	if checkDigit < '0' || checkDigit > '9' {
+		return fmt.Errorf("%w: must contain digits only", ErrInvalidBarcode)
+	}
+	if int(checkDigit-'0') != (10-sum%10)%10 {
+		return fmt.Errorf("%w: wrong check digit", ErrInvalidBarcode)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCustomerNotFound is returned when a customer does not exist or was merged into another one.
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrCustomerMergeSelf is returned when a customer is merged into itself.
+	ErrCustomerMergeSelf = errors.New("cannot merge a customer into itself")
+)
+
+// Well-known customer tags. Tags are free-form, these are the ones the UI highlights.
+const (
+	CustomerTagVIP         = "VIP"
+	CustomerTagProblematic = "PROBLEMATIC"
+)
+
+// NormalizePhone reduces a phone number to digits in the international form, so "+38 (067) 123-45-67",
+// "0671234567" and "380671234567" match. Returns "" when there are no digits.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	switch {
+	case len(normalized) == 10 && strings.HasPrefix(normalized, "0"):
+		normalized = "38" + normalized
+	case len(normalized) == 11 && strings.HasPrefix(normalized, "80"):
+		normalized = "3" + normalized
+	}
+	return normalized
+}
+
+// NormalizeCustomerTag trims and upper-cases a tag.
+func NormalizeCustomerTag(tag string) string {
+	return strings.ToUpper(strings.TrimSpace(tag))
+}
+
+// CustomerStats are the lifetime figures of a customer. Only DONE orders count as purchases, and refunds
+// of their returns are taken off the total.
+type CustomerStats struct {
+	OrdersCount       int64   `json:"orders_count"` // All orders, including open and cancelled
+	PurchasesCount    int64   `json:"purchases_count"`
+	TotalSpent        float64 `json:"total_spent"`
+	AverageOrderValue float64 `json:"average_order_value"`
+	LastPurchaseAt    string  `json:"last_purchase_at,omitempty"`
+}
+
+// CustomerResponse is a customer record. Orders are linked to it by normalized phone or Telegram ID.
+type CustomerResponse struct {
+	ID         uuid.UUID     `json:"id"`
+	Name       string        `json:"name"`
+	Phone      string        `json:"phone,omitempty"` // Normalized
+	Username   string        `json:"username,omitempty"`
+	TelegramID *int64        `json:"telegram_id,omitempty"`
+	Notes      string        `json:"notes,omitempty"`
+	Tags       []string      `json:"tags"`
+	Stats      CustomerStats `json:"stats"`
+	CreatedAt  string        `json:"created_at"`
+}
+
+// UpdateCustomerDTO changes the staff-managed fields. Omitted fields stay as they are.
+type UpdateCustomerDTO struct {
+	Name  *string   `json:"name,omitempty"`
+	Notes *string   `json:"notes,omitempty"`
+	Tags  *[]string `json:"tags,omitempty"`
+}
+
+// MergeCustomersDTO merges the source customer into the one in the path.
+type MergeCustomersDTO struct {
+	SourceID uuid.UUID `json:"source_id" binding:"required"`
+	Comment  string    `json:"comment"`
+}
+
+type CustomerFilter struct {
+	Page     int
+	PageSize int
+	Search   string // Name, username or phone
+	Tag      string
+}
+
+type CustomerRepository interface {
+	// LinkOrder finds the customer of an order by Telegram ID, then by normalized phone, creates one when
+	// there is none and links the order. Returns nil when the order has neither.
+	LinkOrder(ctx context.Context, orderID uuid.UUID) (*uuid.UUID, error)
+	// ListUnlinkedOrderIDs returns orders without a customer created after the cursor order, oldest first.
+	ListUnlinkedOrderIDs(ctx context.Context, after *uuid.UUID, limit int) ([]uuid.UUID, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*CustomerResponse, error)
+	List(ctx context.Context, filter CustomerFilter) ([]CustomerResponse, int64, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdateCustomerDTO) error
+	// Merge moves the orders of the source to the target, fills the target blanks, joins tags and notes,
+	// and deletes the source. It is recorded in the audit log.
+	Merge(ctx context.Context, targetID, sourceID, userID uuid.UUID, comment string) error
+}
+
+// CustomerLinker links new orders to customers. It is called after the order is committed.
+type CustomerLinker interface {
+	LinkOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+type CustomerService interface {
+	CustomerLinker
+	ListCustomers(ctx context.Context, filter CustomerFilter) ([]CustomerResponse, int64, error)
+	GetCustomer(ctx context.Context, id uuid.UUID) (*CustomerResponse, error)
+	UpdateCustomer(ctx context.Context, id uuid.UUID, dto UpdateCustomerDTO) (*CustomerResponse, error)
+	ListCustomerOrders(ctx context.Context, id uuid.UUID, filter OrderFilter) ([]OrderResponse, int64, error)
+	MergeCustomers(ctx context.Context, targetID uuid.UUID, dto MergeCustomersDTO, userID uuid.UUID) (*CustomerResponse, error)
+	// Start links the orders placed before customer records existed, in the background.
+	Start(ctx context.Context)
+}
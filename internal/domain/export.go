@@ -1,9 +1,33 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
-// ExportService coordinates data fetching and Google Sheets generation.
+// ErrUnsupportedExportFormat is returned when a file export is requested in an unknown format.
+var ErrUnsupportedExportFormat = errors.New("unsupported export format, use csv or xlsx")
+
+// ExportFormat is the file format of a downloadable export.
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ExportFile is a rendered export ready for download.
+type ExportFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// ExportService coordinates data fetching and Google Sheets or file generation.
 type ExportService interface {
 	ExportInventory(ctx context.Context, filter LotFilter) (string, error) // Returns URL of the Google Sheet
 	ExportPnL(ctx context.Context, filter ReportFilter) (string, error)    // Returns URL of the Google Sheet
+	// ExportOrders renders the orders matching the filter with one row per item line, for accounting.
+	// Pagination of the filter is ignored, the whole set is exported.
+	ExportOrders(ctx context.Context, filter OrderFilter, format ExportFormat) (*ExportFile, error)
 }
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFiscalNotConfigured is returned when no fiscalization provider credentials are set.
+	ErrFiscalNotConfigured = errors.New("fiscalization is not configured")
+	// ErrFiscalProvider wraps failures reported by the fiscalization (PRRO) API.
+	ErrFiscalProvider = errors.New("fiscalization provider error")
+	// ErrFiscalReceiptNotRetryable is returned when a manual retry is requested for a receipt that is not failed.
+	ErrFiscalReceiptNotRetryable = errors.New("only failed fiscal receipts can be retried")
+)
+
+// FiscalReceiptType tells a sale from a return.
+type FiscalReceiptType string
+
+const (
+	FiscalReceiptSale   FiscalReceiptType = "SALE"
+	FiscalReceiptReturn FiscalReceiptType = "RETURN"
+)
+
+// FiscalReceiptStatus is the queue state of a receipt.
+type FiscalReceiptStatus string
+
+const (
+	FiscalReceiptPending    FiscalReceiptStatus = "PENDING"    // Waiting to be sent, or to be retried after an error
+	FiscalReceiptProcessing FiscalReceiptStatus = "PROCESSING" // Accepted by the provider, fiscal number not issued yet
+	FiscalReceiptDone       FiscalReceiptStatus = "DONE"
+	FiscalReceiptFailed     FiscalReceiptStatus = "FAILED" // Attempts exhausted or rejected by the provider, needs a manual retry
+)
+
+// FiscalPaymentType is the payment form printed on the receipt.
+type FiscalPaymentType string
+
+const (
+	FiscalPaymentCash     FiscalPaymentType = "CASH"
+	FiscalPaymentCashless FiscalPaymentType = "CASHLESS" // Card terminal, bank transfer and online payments
+)
+
+// FiscalPaymentTypeFor maps a ledger payment method to the receipt payment form.
+func FiscalPaymentTypeFor(method PaymentMethod) FiscalPaymentType {
+	if method == PaymentMethodCash {
+		return FiscalPaymentCash
+	}
+	return FiscalPaymentCashless
+}
+
+// FiscalGood is a receipt line. Amounts are in UAH, Discount is for the whole quantity.
+type FiscalGood struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+	Discount float64 `json:"discount,omitempty"`
+}
+
+// FiscalPayment is a part of the receipt total paid in one form.
+type FiscalPayment struct {
+	Type   FiscalPaymentType `json:"type"`
+	Amount float64           `json:"amount"`
+}
+
+// FiscalReceiptRequest is sent to the provider. ID is the receipt ID in the queue and makes repeated
+// attempts idempotent on the provider side.
+type FiscalReceiptRequest struct {
+	ID       uuid.UUID         `json:"id"`
+	Type     FiscalReceiptType `json:"type"`
+	Goods    []FiscalGood      `json:"goods"`
+	Payments []FiscalPayment   `json:"payments"`
+}
+
+// FiscalReceiptResult is the provider state of a receipt. Status is PROCESSING, DONE or FAILED.
+type FiscalReceiptResult struct {
+	ProviderRef string
+	Status      FiscalReceiptStatus
+	FiscalCode  string
+	Error       string // Provider reason when FAILED
+}
+
+// FiscalShift is the cashier shift of the cash register. Receipts can be issued only in an open shift.
+type FiscalShift struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"` // Provider wording, e.g. OPENED or CLOSED
+	Opened   bool   `json:"opened"`
+	OpenedAt string `json:"opened_at,omitempty"`
+	ClosedAt string `json:"closed_at,omitempty"`
+}
+
+// FiscalProvider is implemented by PRRO adapters.
+type FiscalProvider interface {
+	Name() string
+	// CurrentShift returns nil when no shift is open.
+	CurrentShift(ctx context.Context) (*FiscalShift, error)
+	OpenShift(ctx context.Context) (*FiscalShift, error)
+	// CloseShift closes the current shift, the provider makes the Z-report.
+	CloseShift(ctx context.Context) (*FiscalShift, error)
+	IssueReceipt(ctx context.Context, req FiscalReceiptRequest) (*FiscalReceiptResult, error)
+	// GetReceipt returns nil when the provider does not know the receipt.
+	GetReceipt(ctx context.Context, id uuid.UUID) (*FiscalReceiptResult, error)
+}
+
+// FiscalReceiptResponse is a queued or issued fiscal receipt.
+type FiscalReceiptResponse struct {
+	ID            uuid.UUID           `json:"id"`
+	OrderID       uuid.UUID           `json:"order_id"`
+	OrderReturnID *uuid.UUID          `json:"order_return_id,omitempty"`
+	Type          FiscalReceiptType   `json:"type"`
+	Status        FiscalReceiptStatus `json:"status"`
+	Provider      string              `json:"provider"`
+	Amount        float64             `json:"amount"`
+	ProviderRef   string              `json:"provider_ref,omitempty"`
+	FiscalCode    string              `json:"fiscal_code,omitempty"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	NextAttemptAt string              `json:"next_attempt_at,omitempty"`
+	FiscalizedAt  string              `json:"fiscalized_at,omitempty"`
+	CreatedAt     string              `json:"created_at"`
+
+	Request FiscalReceiptRequest `json:"-"`
+}
+
+// CreateFiscalReceiptRecord queues a receipt.
+type CreateFiscalReceiptRecord struct {
+	OrderID       uuid.UUID
+	OrderReturnID *uuid.UUID
+	Provider      string
+	Request       FiscalReceiptRequest
+}
+
+// FiscalAttempt is the outcome of one provider call for a queued receipt.
+type FiscalAttempt struct {
+	Status        FiscalReceiptStatus
+	ProviderRef   string
+	FiscalCode    string
+	Error         string
+	NextAttemptAt time.Time // When to check or try again, unused for DONE and FAILED
+}
+
+// FiscalReceiptFilter is used by the staff queue view.
+type FiscalReceiptFilter struct {
+	Status   FiscalReceiptStatus
+	OrderID  *uuid.UUID
+	Page     int
+	PageSize int
+}
+
+// FiscalReceiptRepository is the fiscalization queue.
+type FiscalReceiptRepository interface {
+	// Enqueue stores the receipt unless the order, or the return, already has one of that type.
+	// The existing receipt is returned with created=false then.
+	Enqueue(ctx context.Context, record CreateFiscalReceiptRecord) (receipt *FiscalReceiptResponse, created bool, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (*FiscalReceiptResponse, error)
+	GetSale(ctx context.Context, orderID uuid.UUID) (*FiscalReceiptResponse, error)
+	List(ctx context.Context, filter FiscalReceiptFilter) ([]FiscalReceiptResponse, int64, error)
+	// ListDue returns PENDING and PROCESSING receipts whose next attempt is due, oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]FiscalReceiptResponse, error)
+	// SaveAttempt counts the attempt and stores its outcome. A DONE receipt copies its fiscal code to the
+	// order, or to the return.
+	SaveAttempt(ctx context.Context, id uuid.UUID, attempt FiscalAttempt) error
+	// Postpone moves the next attempt without counting one, e.g. while the sale of a return is not fiscalized.
+	Postpone(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error
+	// Requeue resets a FAILED receipt to PENDING with a fresh attempt budget.
+	Requeue(ctx context.Context, id uuid.UUID) (*FiscalReceiptResponse, error)
+}
+
+// OrderFiscalizer issues fiscal receipts for offline sales. It is called after the order change is committed.
+type OrderFiscalizer interface {
+	// FiscalizeSale queues the sale receipt of an offline order once it is paid or done.
+	FiscalizeSale(ctx context.Context, order *OrderResponse) error
+	// FiscalizeReturn queues the return receipt of a refunded return of a fiscalized offline order.
+	// refundMethod may be empty when the refund is booked later.
+	FiscalizeReturn(ctx context.Context, order *OrderResponse, orderReturn *OrderReturnResponse, refundMethod PaymentMethod) error
+}
+
+// FiscalService handles the cashier shift and the fiscal receipts queue.
+type FiscalService interface {
+	OrderFiscalizer
+	GetShift(ctx context.Context) (*FiscalShift, error)
+	OpenShift(ctx context.Context) (*FiscalShift, error)
+	CloseShift(ctx context.Context) (*FiscalShift, error)
+	ListReceipts(ctx context.Context, filter FiscalReceiptFilter) ([]FiscalReceiptResponse, int64, error)
+	RetryReceipt(ctx context.Context, id uuid.UUID) (*FiscalReceiptResponse, error)
+	Start(ctx context.Context)
+}
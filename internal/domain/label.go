@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// LotLabel is everything printed on a single price tag.
+type LotLabel struct {
+	LotID     uuid.UUID
+	Brand     string
+	Model     string
+	Size      string // e.g. "205/55 R16", see LotParams.SizeLabel
+	Season    string
+	Condition string
+	Price     float64
+	QRCode    []byte // PNG
+}
+
+// PrintLabelsDTO selects lots for a label sheet. When LotIDs is empty the lots are selected by the query filters.
+type PrintLabelsDTO struct {
+	LotIDs   []uuid.UUID `json:"lot_ids"`
+	Template string      `json:"template"`                                // Defaults to the A4 grid
+	Copies   int         `json:"copies" binding:"omitempty,min=1,max=50"` // Labels per lot, defaults to 1
+}
+
+// LabelTemplateInfo describes a template that can be used for printing.
+type LabelTemplateInfo struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	LabelWidth  float64 `json:"label_width_mm"`
+	LabelHeight float64 `json:"label_height_mm"`
+	PerPage     int     `json:"labels_per_page"`
+}
+
+// LabelService renders printable price tags for lots.
+type LabelService interface {
+	RenderLotLabel(ctx context.Context, lotID uuid.UUID, template string) ([]byte, error)
+	RenderLotLabels(ctx context.Context, dto PrintLabelsDTO, filter LotFilter) ([]byte, error)
+	ListTemplates() []LabelTemplateInfo
+}
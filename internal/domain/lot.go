@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/google/uuid"
 )
@@ -43,19 +45,37 @@ type LotParams struct {
 	PackageQuantity   int     `json:"package_quantity,omitempty"`
 }
 
+// SizeLabel returns the human readable size of a tire ("205/55 R16") or rim ("R16").
+// Lots without size params, e.g. accessories, get an empty string.
+func (p LotParams) SizeLabel(lotType string) string {
+	if p.Width > 0 && p.Profile > 0 && p.Diameter > 0 {
+		return fmt.Sprintf("%s/%s R%s", formatSizeNumber(p.Width), formatSizeNumber(p.Profile), formatSizeNumber(p.Diameter))
+	}
+	if lotType == "RIM" && p.Diameter > 0 {
+		return fmt.Sprintf("R%s", formatSizeNumber(p.Diameter))
+	}
+	return ""
+}
+
+func formatSizeNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
 // CreateLotDTO contains the necessary data to create a new lot from the API.
 type CreateLotDTO struct {
-	WarehouseID     uuid.UUID `json:"warehouse_id" binding:"required"`
-	Type            string    `json:"type" binding:"required,oneof=TIRE RIM ACCESSORY"`
-	Condition       string    `json:"condition" binding:"required,oneof=NEW USED"`
-	Brand           string    `json:"brand" binding:"required"`
-	Model           string    `json:"model"`
-	Params          LotParams `json:"params"`
-	Defects         string    `json:"defects"`
-	Photos          []string  `json:"photos"`
-	InitialQuantity int       `json:"initial_quantity" binding:"required,gt=0"`
-	PurchasePrice   float64   `json:"purchase_price" binding:"required,gt=0"`
-	SellPrice       float64   `json:"sell_price" binding:"required,gt=0"`
+	WarehouseID     uuid.UUID  `json:"warehouse_id" binding:"required"`
+	LocationID      *uuid.UUID `json:"location_id"` // Optional bin/shelf inside the warehouse
+	Type            string     `json:"type" binding:"required,oneof=TIRE RIM ACCESSORY"`
+	Condition       string     `json:"condition" binding:"required,oneof=NEW USED"`
+	Brand           string     `json:"brand" binding:"required"`
+	Model           string     `json:"model"`
+	Barcode         string     `json:"barcode" binding:"omitempty,numeric"` // Manufacturer EAN-8/UPC-A/EAN-13
+	Params          LotParams  `json:"params"`
+	Defects         string     `json:"defects"`
+	Photos          []string   `json:"photos"`
+	InitialQuantity int        `json:"initial_quantity" binding:"required,gt=0"`
+	PurchasePrice   float64    `json:"purchase_price" binding:"required,gt=0"`
+	SellPrice       float64    `json:"sell_price" binding:"required,gt=0"`
 }
 
 // UpdateLotDTO contains fields that can be updated.
@@ -65,6 +85,7 @@ type UpdateLotDTO struct {
 	Condition     *string    `json:"condition" binding:"omitempty,oneof=NEW USED"`
 	Brand         *string    `json:"brand"`
 	Model         *string    `json:"model"`
+	Barcode       *string    `json:"barcode" binding:"omitempty,numeric"`
 	Params        *LotParams `json:"params"`
 	Defects       *string    `json:"defects"`
 	Photos        []string   `json:"photos"`
@@ -79,6 +100,8 @@ type LotFilter struct {
 	SortBy          string
 	SortOrder       string
 	Status          string
+	IDs             []uuid.UUID // Internal only, restricts the result to the given lots
+	LocationID      string      // Internal only, includes nested locations
 	Brand           string
 	Type            string
 	Search          string
@@ -116,16 +139,17 @@ type LotFilter struct {
 
 // LotPublicResponse is what the BUYER sees.
 type LotPublicResponse struct {
-	ID              uuid.UUID `json:"id"`
-	Type            string    `json:"type"`
-	Condition       string    `json:"condition"`
-	Brand           string    `json:"brand"`
-	Model           string    `json:"model"`
-	Params          LotParams `json:"params"`
-	Defects         string    `json:"defects,omitempty"`
-	Photos          []string  `json:"photos"`
-	CurrentQuantity int       `json:"current_quantity"`
-	SellPrice       float64   `json:"sell_price"`
+	ID              uuid.UUID    `json:"id"`
+	Type            string       `json:"type"`
+	Condition       string       `json:"condition"`
+	Brand           string       `json:"brand"`
+	Model           string       `json:"model"`
+	Params          LotParams    `json:"params"`
+	Defects         string       `json:"defects,omitempty"`
+	Photos          []string     `json:"photos"`
+	Images          []PhotoAsset `json:"images"` // Same photos with thumb, card and full renditions
+	CurrentQuantity int          `json:"current_quantity"`
+	SellPrice       float64      `json:"sell_price"`
 }
 
 // PaginatedLotPublicResponse is the paginated public contract for /lots.
@@ -149,19 +173,27 @@ type SuggestionSelectionRequest struct {
 // LotInternalResponse is what ADMIN and STAFF see.
 type LotInternalResponse struct {
 	LotPublicResponse
-	WarehouseID   uuid.UUID `json:"warehouse_id"`
-	InitialQty    int       `json:"initial_quantity"`
-	PurchasePrice float64   `json:"purchase_price"`
-	Status        string    `json:"status"`
+	WarehouseID   uuid.UUID          `json:"warehouse_id"`
+	Location      *WarehouseLocation `json:"location,omitempty"`
+	SKU           string             `json:"sku"`
+	Barcode       string             `json:"barcode,omitempty"`
+	InitialQty    int                `json:"initial_quantity"`
+	PurchasePrice float64            `json:"purchase_price"`
+	Status        string             `json:"status"`
 }
 
 // LotRepository defines database operations for the Lot entity.
 type LotRepository interface {
 	Create(ctx context.Context, dto *CreateLotDTO) (uuid.UUID, error)
-	Update(ctx context.Context, id uuid.UUID, dto *UpdateLotDTO) error
+	// Update changes the lot. Moving it to another warehouse drops its bin placement and records the move,
+	// attributed to userID.
+	Update(ctx context.Context, id uuid.UUID, dto *UpdateLotDTO, userID uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListPublic(ctx context.Context, filter LotFilter) ([]LotPublicResponse, int64, error)
 	ListInternal(ctx context.Context, filter LotFilter) ([]LotInternalResponse, int64, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*LotInternalResponse, error)
+	GetBySKU(ctx context.Context, sku string) (*LotInternalResponse, error)
+	ListByBarcode(ctx context.Context, barcode string) ([]LotInternalResponse, error)
 	ListSuggestions(ctx context.Context, filter LotFilter, internal bool, limit int) ([]string, error)
 	TrackSuggestionSelection(ctx context.Context, suggestion string, internal bool) error
 	TrackAnalyticsEvent(ctx context.Context, req TrackLotAnalyticsEventRequest, userAgent string) error
@@ -170,7 +202,7 @@ type LotRepository interface {
 // LotService defines business logic operations for the Lot entity.
 type LotService interface {
 	CreateLot(ctx context.Context, dto CreateLotDTO) (uuid.UUID, error)
-	UpdateLot(ctx context.Context, id uuid.UUID, dto UpdateLotDTO) error
+	UpdateLot(ctx context.Context, id uuid.UUID, dto UpdateLotDTO, userID uuid.UUID) error
 	DeleteLot(ctx context.Context, id uuid.UUID) error
 	ListPublicLots(ctx context.Context, filter LotFilter) ([]LotPublicResponse, int64, error)
 	ListInternalLots(ctx context.Context, filter LotFilter) ([]LotInternalResponse, int64, error)
@@ -180,4 +212,7 @@ type LotService interface {
 	TrackInternalSuggestionSelection(ctx context.Context, suggestion string) error
 	TrackLotAnalyticsEvent(ctx context.Context, req TrackLotAnalyticsEventRequest, userAgent string) error
 	GenerateLotQR(ctx context.Context, id uuid.UUID) ([]byte, error)
+	GenerateLotBarcode(ctx context.Context, id uuid.UUID) ([]byte, error)
+	FindByBarcode(ctx context.Context, code string) ([]LotInternalResponse, error)
+	PrefillFromBarcode(ctx context.Context, barcode string) (*CreateLotDTO, error)
 }
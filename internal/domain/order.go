@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -28,12 +29,15 @@ type CreateOrderDTO struct {
 	CustomerTelegramID *int64         `json:"customer_telegram_id"` // Optional
 	Channel            OrderChannel   `json:"channel,omitempty"`
 	Items              []OrderItemDTO `json:"items" binding:"required,min=1"`
+	PromoCode          string         `json:"promo_code,omitempty"` // Online orders only
 }
 
 // UpdateOrderStatusDTO represents the request to change an order's status.
 type UpdateOrderStatusDTO struct {
-	Status  string `json:"status" binding:"required,oneof=NEW PREPAYMENT DONE CANCELLED"`
-	Comment string `json:"comment"`
+	Status  OrderStatus `json:"status" binding:"required,oneof=NEW CONFIRMED AWAITING_PAYMENT PREPAYMENT PAID SHIPPED READY_FOR_PICKUP DONE CANCELLED RETURNED"`
+	Comment string      `json:"comment"`
+	// OverrideBalance lets an admin complete an order whose balance is not settled.
+	OverrideBalance bool `json:"override_balance"`
 }
 
 type UpdateOrderItemPriceDTO struct {
@@ -41,6 +45,38 @@ type UpdateOrderItemPriceDTO struct {
 	Comment string  `json:"comment"`
 }
 
+// AddOrderItemDTO adds a lot to an open order. Adding a lot already in the order increases its quantity.
+type AddOrderItemDTO struct {
+	LotID      uuid.UUID `json:"lot_id" binding:"required"`
+	Quantity   int       `json:"quantity" binding:"required,gt=0"`
+	FinalPrice *float64  `json:"final_price,omitempty" binding:"omitempty,gt=0"` // Offline orders only
+	Comment    string    `json:"comment"`
+}
+
+// UpdateOrderItemDTO changes the quantity of an order item, or swaps it to another lot (e.g. a different size).
+type UpdateOrderItemDTO struct {
+	Quantity int        `json:"quantity" binding:"required,gt=0"`
+	LotID    *uuid.UUID `json:"lot_id,omitempty"`
+	Comment  string     `json:"comment"`
+}
+
+type OrderItemEditOp string
+
+const (
+	OrderItemEditAdd    OrderItemEditOp = "ADD"
+	OrderItemEditUpdate OrderItemEditOp = "UPDATE"
+	OrderItemEditRemove OrderItemEditOp = "REMOVE"
+)
+
+// OrderItemEdit is a single change to the item set of an open order.
+type OrderItemEdit struct {
+	Op         OrderItemEditOp
+	ItemID     uuid.UUID // UPDATE and REMOVE
+	LotID      uuid.UUID // ADD, and UPDATE when swapping the lot
+	Quantity   int       // ADD and UPDATE
+	FinalPrice *float64  // ADD on offline orders
+}
+
 type SendOrderMessageDTO struct {
 	Message string `json:"message" binding:"required"`
 }
@@ -86,6 +122,29 @@ type OrderFilter struct {
 	Status   string
 	Channel  OrderChannel
 	Customer string // Search by name or phone
+
+	CustomerID *uuid.UUID
+	AssigneeID *uuid.UUID
+	Unassigned bool // Only orders without an assignee
+	Overdue    bool // Only orders with an SLA timer past due
+
+	StartDate    *time.Time // Placed at or after
+	EndDate      *time.Time // Placed at or before
+	MinTotal     *float64
+	MaxTotal     *float64
+	CreatedBy    *uuid.UUID        // Placed by the user: the buyer, or the staff member for offline sales
+	PaymentState OrderPaymentState // From the ledger, see OrderBalance.PaymentState
+
+	// Item filters match orders with at least one such item. Size is matched on the lot parameters.
+	LotID       *uuid.UUID
+	Brand       string
+	Width       float64
+	Profile     float64
+	Diameter    float64
+	WarehouseID *uuid.UUID
+
+	SortBy    string // created_at (default), total_amount, status or customer_name
+	SortOrder string // asc or desc (default)
 }
 
 // OrderResponse represents the order data returned to the client.
@@ -95,9 +154,20 @@ type OrderResponse struct {
 	CustomerPhone      string              `json:"customer_phone"`
 	CustomerUsername   string              `json:"customer_username,omitempty"`
 	CustomerTelegramID *int64              `json:"customer_telegram_id,omitempty"`
+	CustomerID         *uuid.UUID          `json:"customer_id,omitempty"`
 	Channel            OrderChannel        `json:"channel"`
 	Status             string              `json:"status"`
-	TotalAmount        float64             `json:"total_amount"`
+	TotalAmount        float64             `json:"total_amount"` // Net of the discount
+	DiscountAmount     float64             `json:"discount_amount,omitempty"`
+	PaidAmount         float64             `json:"paid_amount"` // Payments minus refunds, from the ledger
+	Balance            float64             `json:"balance"`     // Still owed by the buyer, negative when the shop owes a refund
+	PaymentState       OrderPaymentState   `json:"payment_state,omitempty"`
+	PromoCode          string              `json:"promo_code,omitempty"`
+	TrackingNumber     string              `json:"tracking_number,omitempty"`
+	FiscalCode         string              `json:"fiscal_code,omitempty"` // Fiscal number of the sale receipt
+	AssigneeID         *uuid.UUID          `json:"assignee_id,omitempty"`
+	AssignedAt         string              `json:"assigned_at,omitempty"`
+	SLA                *OrderSLAStatus     `json:"sla,omitempty"` // Missing for orders placed before SLA tracking
 	CreatedAt          string              `json:"created_at"`
 	Items              []OrderItemResponse `json:"items"`
 }
@@ -111,15 +181,27 @@ type OrderItemResponse struct {
 	Photo    string    `json:"photo,omitempty"`
 	Quantity int       `json:"quantity"`
 	Price    float64   `json:"price"`
-	Total    float64   `json:"total"`
+	Discount float64   `json:"discount,omitempty"` // Share of the order discount for the whole quantity
+	Total    float64   `json:"total"`              // Price times quantity minus the discount
 }
 
 // OrderRepository handles database operations for orders, including transactions.
 type OrderRepository interface {
 	CreateOrderTx(ctx context.Context, dto CreateOrderDTO, userID *uuid.UUID) (uuid.UUID, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status string, userID uuid.UUID, comment string) error
+	// UpdateStatus validates the change against the state machine under a row lock, restocks when the
+	// transition says so and returns the applied transition. Completing an order requires a settled
+	// balance unless an admin overrides it.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus, userID uuid.UUID, role UserRole, comment string, overrideBalance bool) (*OrderTransition, error)
+	// CancelByBuyer cancels an order on behalf of its owner through the same path as UpdateStatus, checking
+	// ownership and the policy under the row lock. Orders of other users are reported as ErrOrderNotFound.
+	CancelByBuyer(ctx context.Context, id, buyerID uuid.UUID, reason string, policy BuyerCancelPolicy) (*OrderTransition, error)
 	UpdateItemPrice(ctx context.Context, orderID, itemID, userID uuid.UUID, price float64, comment string) error
+	// EditItems applies the edit to an open order, rebalancing lot stock and the order total atomically.
+	EditItems(ctx context.Context, orderID, userID uuid.UUID, edit OrderItemEdit, comment string) error
 	GetByID(ctx context.Context, id uuid.UUID) (*OrderResponse, error)
+	// CreateReturnTx registers a return on a DONE order, restocking the units marked RESTOCK.
+	CreateReturnTx(ctx context.Context, orderID, userID uuid.UUID, dto CreateOrderReturnDTO) (uuid.UUID, error)
+	ListReturns(ctx context.Context, orderID uuid.UUID) ([]OrderReturnResponse, error)
 	CreateMessage(ctx context.Context, dto CreateOrderMessageDTO) (*OrderMessage, error)
 	ListMessages(ctx context.Context, orderID uuid.UUID) ([]OrderMessage, error)
 	GetMessageByTelegramMeta(ctx context.Context, customerTelegramID int64, telegramMessageID int64) (*OrderMessage, error)
@@ -130,8 +212,16 @@ type OrderRepository interface {
 // OrderService handles business logic for orders.
 type OrderService interface {
 	CreateOrder(ctx context.Context, dto CreateOrderDTO, userID *uuid.UUID) (uuid.UUID, error)
-	UpdateOrderStatus(ctx context.Context, id uuid.UUID, status string, userID uuid.UUID, comment string) error
+	UpdateOrderStatus(ctx context.Context, id uuid.UUID, status OrderStatus, userID uuid.UUID, role UserRole, comment string, overrideBalance bool) error
+	ListOrderTransitions(ctx context.Context, id uuid.UUID, role UserRole) (*OrderTransitionsResponse, error)
+	// CancelMyOrder cancels an order of the buyer and tells the admins the reason.
+	CancelMyOrder(ctx context.Context, id, userID uuid.UUID, dto CancelOrderDTO) error
 	UpdateOrderItemPrice(ctx context.Context, orderID, itemID, userID uuid.UUID, price float64, comment string) error
+	AddOrderItem(ctx context.Context, orderID, userID uuid.UUID, dto AddOrderItemDTO) error
+	UpdateOrderItem(ctx context.Context, orderID, itemID, userID uuid.UUID, dto UpdateOrderItemDTO) error
+	RemoveOrderItem(ctx context.Context, orderID, itemID, userID uuid.UUID, comment string) error
+	CreateOrderReturn(ctx context.Context, orderID, userID uuid.UUID, dto CreateOrderReturnDTO) (*OrderReturnResponse, error)
+	ListOrderReturns(ctx context.Context, orderID uuid.UUID) ([]OrderReturnResponse, error)
 	SendOrderMessage(ctx context.Context, id uuid.UUID, message string) error
 	ListOrderMessages(ctx context.Context, id uuid.UUID) ([]OrderMessage, error)
 	ProcessInboundMessage(ctx context.Context, dto InboundOrderMessageDTO) error
@@ -139,3 +229,14 @@ type OrderService interface {
 	ListOrders(ctx context.Context, filter OrderFilter) ([]OrderResponse, int64, error)
 	ListMyOrders(ctx context.Context, userID uuid.UUID, filter OrderFilter) ([]OrderResponse, int64, error)
 }
+
+// OrderDocumentGenerator renders order documents for transitions flagged with GenerateDocuments.
+type OrderDocumentGenerator interface {
+	GenerateOrderDocuments(ctx context.Context, order *OrderResponse, status OrderStatus) error
+}
+
+// OrderStatusNotifier tells the buyer about a status change of their order. comment is the staff comment
+// of the change, shown to the buyer as the reason of a cancellation.
+type OrderStatusNotifier interface {
+	NotifyStatus(ctx context.Context, order *OrderResponse, status OrderStatus, comment string) error
+}
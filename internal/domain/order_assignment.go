@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAssignee is returned when an order is assigned to a user who is not staff or an admin.
+var ErrInvalidAssignee = errors.New("orders can be assigned only to staff or admins")
+
+// OrderSLAKind names one of the order timers.
+type OrderSLAKind string
+
+const (
+	// OrderSLAFirstResponse runs until staff first reacts: a status change or a message to the buyer.
+	OrderSLAFirstResponse OrderSLAKind = "FIRST_RESPONSE"
+	// OrderSLAProcessing runs until the order is handed over (shipped or ready for pickup) or closed.
+	OrderSLAProcessing OrderSLAKind = "PROCESSING"
+)
+
+// EndsProcessing reports whether reaching the status stops the processing timer.
+func (s OrderStatus) EndsProcessing() bool {
+	switch s {
+	case OrderStatusShipped, OrderStatusReadyForPickup, OrderStatusDone, OrderStatusCancelled, OrderStatusReturned:
+		return true
+	}
+	return false
+}
+
+// OrderSLA holds the time targets, both counted from the order creation.
+type OrderSLA struct {
+	FirstResponse time.Duration
+	Processing    time.Duration
+}
+
+// OrderSLAStatus shows the timers of an order.
+type OrderSLAStatus struct {
+	FirstResponseDue string `json:"first_response_due,omitempty"`
+	FirstRespondedAt string `json:"first_responded_at,omitempty"`
+	ProcessingDue    string `json:"processing_due,omitempty"`
+	ProcessedAt      string `json:"processed_at,omitempty"`
+	Overdue          bool   `json:"overdue"` // A timer is past due and still running
+}
+
+// AssignOrderDTO sets the order assignee. A null assignee unassigns the order.
+type AssignOrderDTO struct {
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+	Comment    string     `json:"comment"`
+}
+
+// StartOrderSLA starts the timers of a new order.
+type StartOrderSLA struct {
+	SLA OrderSLA
+	// AssigneeID assigns the order to this user, otherwise AutoAssign picks staff round-robin.
+	AssigneeID *uuid.UUID
+	AutoAssign bool
+	// Responded stops the first response timer at once, e.g. for orders placed at the counter.
+	Responded bool
+}
+
+// OrderSLABreach is a timer past due that admins have not been told about yet.
+type OrderSLABreach struct {
+	Order OrderResponse
+	Kind  OrderSLAKind
+	Due   time.Time
+}
+
+// OrderAssignmentRepository keeps the order assignees and SLA timers.
+type OrderAssignmentRepository interface {
+	// StartSLA sets the deadlines of an order and assigns it. Returns the assignee, nil when nobody was picked.
+	StartSLA(ctx context.Context, orderID uuid.UUID, start StartOrderSLA) (*uuid.UUID, error)
+	// Assign changes the assignee and records it in the audit log.
+	Assign(ctx context.Context, orderID uuid.UUID, assigneeID *uuid.UUID, userID uuid.UUID, comment string) error
+	// MarkFirstResponse stops the first response timer unless it is stopped already.
+	MarkFirstResponse(ctx context.Context, orderID uuid.UUID) error
+	// ListSLABreaches returns timers past due at now that were not escalated, oldest deadline first.
+	ListSLABreaches(ctx context.Context, now time.Time, limit int) ([]OrderSLABreach, error)
+	MarkSLAEscalated(ctx context.Context, orderID uuid.UUID, kind OrderSLAKind) error
+}
+
+// OrderSLATracker starts the timers of new orders. It is called after the order is committed.
+type OrderSLATracker interface {
+	// StartTracking sets the SLA deadlines and assigns the order. createdBy is the staff member who placed
+	// an offline order, the order is assigned to them.
+	StartTracking(ctx context.Context, order *OrderResponse, createdBy *uuid.UUID) error
+	// RecordFirstResponse stops the first response timer when staff messages the buyer.
+	RecordFirstResponse(ctx context.Context, orderID uuid.UUID) error
+}
+
+// OrderAssignmentService assigns orders and escalates SLA breaches to admins.
+type OrderAssignmentService interface {
+	OrderSLATracker
+	AssignOrder(ctx context.Context, orderID uuid.UUID, dto AssignOrderDTO, userID uuid.UUID) (*OrderResponse, error)
+	Start(ctx context.Context)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrOrderNotFound is returned when an order does not exist or does not belong to the caller.
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderCancelNotAllowed is returned when the buyer's cancellation policy no longer covers the order.
+	ErrOrderCancelNotAllowed = errors.New("this order can no longer be cancelled, please contact the shop")
+)
+
+// BuyerCancelPolicy lists the statuses a buyer may cancel their own order from, with how long after placing
+// the order it is allowed. A zero window has no limit. Only the statuses the state machine lets buyers
+// cancel from take effect.
+type BuyerCancelPolicy map[OrderStatus]time.Duration
+
+// Allows reports whether an order in the status, placed at createdAt, may still be cancelled by its buyer.
+func (p BuyerCancelPolicy) Allows(status OrderStatus, createdAt, now time.Time) bool {
+	window, ok := p[status]
+	if !ok {
+		return false
+	}
+	return window <= 0 || now.Before(createdAt.Add(window))
+}
+
+// CancelOrderDTO is a cancellation request of the buyer. The reason is forwarded to the admins.
+type CancelOrderDTO struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrOrderDocumentNotAvailable is returned when the document does not apply to the order yet,
+// e.g. a receipt for an unpaid order.
+var ErrOrderDocumentNotAvailable = errors.New("document is not available for this order")
+
+// OrderDocumentType is a printable order document.
+type OrderDocumentType string
+
+const (
+	OrderDocumentInvoice  OrderDocumentType = "INVOICE"  // Рахунок на оплату
+	OrderDocumentReceipt  OrderDocumentType = "RECEIPT"  // Товарний чек
+	OrderDocumentWarranty OrderDocumentType = "WARRANTY" // Гарантійний талон
+)
+
+// ParseOrderDocumentType accepts the type in any case, as it comes from the URL.
+func ParseOrderDocumentType(value string) (OrderDocumentType, bool) {
+	docType := OrderDocumentType(strings.ToUpper(value))
+	switch docType {
+	case OrderDocumentInvoice, OrderDocumentReceipt, OrderDocumentWarranty:
+		return docType, true
+	}
+	return "", false
+}
+
+// OrderDocument is a rendered PDF document.
+type OrderDocument struct {
+	Type     OrderDocumentType
+	Number   string
+	FileName string
+	Content  []byte
+}
+
+// OrderDocumentService renders order documents for staff and sends them to buyers through the client bot.
+// It is also the OrderDocumentGenerator of the status state machine.
+type OrderDocumentService interface {
+	OrderDocumentGenerator
+	RenderOrderDocument(ctx context.Context, orderID uuid.UUID, docType OrderDocumentType) (*OrderDocument, error)
+	SendOrderDocument(ctx context.Context, orderID uuid.UUID, docType OrderDocumentType) error
+}
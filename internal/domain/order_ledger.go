@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrOrderBalanceOutstanding is returned when an order with an unsettled balance is completed without an admin override.
+	ErrOrderBalanceOutstanding = errors.New("order balance is not settled")
+	// ErrLedgerEntryNotAllowed is returned when a ledger entry would overpay the order or refund more than was paid.
+	ErrLedgerEntryNotAllowed = errors.New("ledger entry is not allowed")
+)
+
+// LedgerDateLayout is the layout of ledger timestamps in requests and responses.
+const LedgerDateLayout = "2006-01-02 15:04:05"
+
+// LedgerEntryType tells whether money came in or went back to the buyer.
+type LedgerEntryType string
+
+const (
+	LedgerEntryPayment LedgerEntryType = "PAYMENT"
+	LedgerEntryRefund  LedgerEntryType = "REFUND"
+)
+
+// PaymentMethod is how the money moved. ONLINE entries are written by payment provider callbacks only.
+type PaymentMethod string
+
+const (
+	PaymentMethodCash     PaymentMethod = "CASH"
+	PaymentMethodCard     PaymentMethod = "CARD" // Card terminal in the shop
+	PaymentMethodTransfer PaymentMethod = "TRANSFER"
+	PaymentMethodOnline   PaymentMethod = "ONLINE"
+)
+
+// CreateLedgerEntryDTO records money taken or paid back by staff.
+type CreateLedgerEntryDTO struct {
+	Type       LedgerEntryType `json:"type" binding:"required,oneof=PAYMENT REFUND"`
+	Method     PaymentMethod   `json:"method" binding:"required,oneof=CASH CARD TRANSFER"`
+	Amount     float64         `json:"amount" binding:"required,gt=0"`
+	ReceivedAt string          `json:"received_at,omitempty" binding:"omitempty,datetime=2006-01-02 15:04:05"` // Defaults to now
+	Comment    string          `json:"comment"`
+}
+
+// LedgerEntryResponse is a single money movement of an order.
+type LedgerEntryResponse struct {
+	ID           uuid.UUID       `json:"id"`
+	OrderID      uuid.UUID       `json:"order_id"`
+	Type         LedgerEntryType `json:"type"`
+	Method       PaymentMethod   `json:"method"`
+	Amount       float64         `json:"amount"`
+	PaymentID    *uuid.UUID      `json:"payment_id,omitempty"`     // Online payment the entry came from
+	ReceivedByID *uuid.UUID      `json:"received_by_id,omitempty"` // Empty for provider callbacks
+	ReceivedAt   string          `json:"received_at"`
+	Comment      string          `json:"comment,omitempty"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// OrderBalance is what the buyer owes. TotalAmount is the order total less return refunds, and zero for
+// cancelled orders. A negative Balance is money the shop owes back to the buyer.
+type OrderBalance struct {
+	TotalAmount float64 `json:"total_amount"`
+	PaidAmount  float64 `json:"paid_amount"` // Payments minus refunds
+	Balance     float64 `json:"balance"`
+}
+
+// IsSettled reports whether nothing is owed either way, to the kopeck.
+func (b OrderBalance) IsSettled() bool {
+	return b.Balance > -0.005 && b.Balance < 0.005
+}
+
+// OrderPaymentState sums up the balance of an order for filtering and exports.
+type OrderPaymentState string
+
+const (
+	OrderPaymentUnpaid    OrderPaymentState = "UNPAID"
+	OrderPaymentPartial   OrderPaymentState = "PARTIALLY_PAID"
+	OrderPaymentPaid      OrderPaymentState = "PAID"
+	OrderPaymentRefundDue OrderPaymentState = "REFUND_DUE" // The shop owes money back
+)
+
+// PaymentState returns the payment state of the balance. Orders that owe nothing and were never paid,
+// such as cancelled unpaid ones, have no state.
+func (b OrderBalance) PaymentState() OrderPaymentState {
+	paid := b.PaidAmount >= 0.005
+	switch {
+	case b.Balance <= -0.005:
+		return OrderPaymentRefundDue
+	case b.Balance >= 0.005 && paid:
+		return OrderPaymentPartial
+	case b.Balance >= 0.005:
+		return OrderPaymentUnpaid
+	case paid:
+		return OrderPaymentPaid
+	}
+	return ""
+}
+
+// OrderLedgerResponse is the ledger of an order with its balance.
+type OrderLedgerResponse struct {
+	OrderBalance
+	Entries []LedgerEntryResponse `json:"entries"`
+}
+
+// OrderLedgerRepository stores order money movements.
+type OrderLedgerRepository interface {
+	// AddEntry locks the order and rejects payments above the balance and refunds above what was paid.
+	// Refunds of a fiscalized offline sale are limited to the refunds of its returns.
+	AddEntry(ctx context.Context, orderID, userID uuid.UUID, dto CreateLedgerEntryDTO) (*LedgerEntryResponse, error)
+	ListEntries(ctx context.Context, orderID uuid.UUID) ([]LedgerEntryResponse, error)
+	GetBalance(ctx context.Context, orderID uuid.UUID) (*OrderBalance, error)
+}
+
+// OrderLedgerService records offline payments and refunds.
+type OrderLedgerService interface {
+	RecordEntry(ctx context.Context, orderID, userID uuid.UUID, dto CreateLedgerEntryDTO) (*LedgerEntryResponse, error)
+	GetLedger(ctx context.Context, orderID uuid.UUID) (*OrderLedgerResponse, error)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidMention is returned when a note mentions a user who is not staff or an admin.
+var ErrInvalidMention = errors.New("only staff and admins can be mentioned in order notes")
+
+// CreateOrderNoteDTO adds an internal note to an order. Mentioned staff get a message from the staff bot.
+type CreateOrderNoteDTO struct {
+	Text       string      `json:"text" binding:"required"`
+	MentionIDs []uuid.UUID `json:"mention_ids"`
+}
+
+// OrderNote is a staff-only note on an order. Notes are never sent to the buyer.
+type OrderNote struct {
+	ID          uuid.UUID   `json:"id"`
+	OrderID     uuid.UUID   `json:"order_id"`
+	AuthorID    uuid.UUID   `json:"author_id"`
+	AuthorLabel string      `json:"author_label"`
+	Text        string      `json:"text"`
+	MentionIDs  []uuid.UUID `json:"mention_ids,omitempty"`
+	CreatedAt   string      `json:"created_at"`
+}
+
+// CreateOrderNoteRecord stores a note.
+type CreateOrderNoteRecord struct {
+	OrderID    uuid.UUID
+	AuthorID   uuid.UUID
+	Text       string
+	MentionIDs []uuid.UUID
+}
+
+// OrderStatusChange is a STATUS_CHANGED audit entry of an order.
+type OrderStatusChange struct {
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	UserID    uuid.UUID   `json:"user_id"`
+	UserLabel string      `json:"user_label,omitempty"` // Empty for changes made by integrations
+	Comment   string      `json:"comment,omitempty"`
+	CreatedAt string      `json:"created_at"`
+}
+
+type OrderTimelineEntryType string
+
+const (
+	OrderTimelineNote         OrderTimelineEntryType = "NOTE"
+	OrderTimelineMessage      OrderTimelineEntryType = "MESSAGE"
+	OrderTimelineStatusChange OrderTimelineEntryType = "STATUS_CHANGE"
+)
+
+// OrderTimelineEntry is one event of the order history. Exactly one of Note, Message and StatusChange is set.
+type OrderTimelineEntry struct {
+	Type         OrderTimelineEntryType `json:"type"`
+	CreatedAt    string                 `json:"created_at"`
+	Note         *OrderNote             `json:"note,omitempty"`
+	Message      *OrderMessage          `json:"message,omitempty"`
+	StatusChange *OrderStatusChange     `json:"status_change,omitempty"`
+}
+
+type OrderNoteRepository interface {
+	Create(ctx context.Context, record CreateOrderNoteRecord) (*OrderNote, error)
+	// List returns the notes of an order, oldest first.
+	List(ctx context.Context, orderID uuid.UUID) ([]OrderNote, error)
+	// ListStatusChanges returns the status changes of an order from the audit log, oldest first.
+	ListStatusChanges(ctx context.Context, orderID uuid.UUID) ([]OrderStatusChange, error)
+}
+
+// OrderNoteService handles internal notes and the order timeline.
+type OrderNoteService interface {
+	AddNote(ctx context.Context, orderID, authorID uuid.UUID, dto CreateOrderNoteDTO) (*OrderNote, error)
+	ListNotes(ctx context.Context, orderID uuid.UUID) ([]OrderNote, error)
+	// GetTimeline interleaves notes, buyer messages and status changes, oldest first.
+	GetTimeline(ctx context.Context, orderID uuid.UUID) ([]OrderTimelineEntry, error)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrReturnNotAllowed is returned when a return is registered on an order that is not DONE.
+var ErrReturnNotAllowed = errors.New("returns are accepted only for completed orders")
+
+// ReturnDisposition tells what happens to the returned tires.
+type ReturnDisposition string
+
+const (
+	ReturnDispositionRestock  ReturnDisposition = "RESTOCK"   // Put back into the original lot
+	ReturnDispositionWriteOff ReturnDisposition = "WRITE_OFF" // Damaged or unsellable, stock is not restored
+)
+
+// ReturnItemDTO selects how many units of an order item come back.
+type ReturnItemDTO struct {
+	OrderItemID uuid.UUID         `json:"order_item_id" binding:"required"`
+	Quantity    int               `json:"quantity" binding:"required,gt=0"`
+	Disposition ReturnDisposition `json:"disposition" binding:"required,oneof=RESTOCK WRITE_OFF"`
+}
+
+// CreateOrderReturnDTO registers a return (RMA) on a completed order.
+type CreateOrderReturnDTO struct {
+	Items        []ReturnItemDTO `json:"items" binding:"required,min=1,dive"`
+	RefundAmount *float64        `json:"refund_amount,omitempty" binding:"omitempty,gte=0"` // Defaults to, and cannot exceed, the sale price of the returned units
+	// RefundMethod books the refund as paid back in the order ledger. Leave empty to record it later.
+	RefundMethod PaymentMethod `json:"refund_method,omitempty" binding:"omitempty,oneof=CASH CARD TRANSFER"`
+	Reason       string        `json:"reason" binding:"required"`
+}
+
+// OrderReturnItemResponse is a returned line with its share of the refund.
+type OrderReturnItemResponse struct {
+	OrderItemID  uuid.UUID         `json:"order_item_id"`
+	LotID        uuid.UUID         `json:"lot_id"`
+	Brand        string            `json:"brand,omitempty"`
+	Model        string            `json:"model,omitempty"`
+	Quantity     int               `json:"quantity"`
+	Disposition  ReturnDisposition `json:"disposition"`
+	RefundAmount float64           `json:"refund_amount"`
+}
+
+// OrderReturnResponse represents a registered return.
+type OrderReturnResponse struct {
+	ID           uuid.UUID                 `json:"id"`
+	OrderID      uuid.UUID                 `json:"order_id"`
+	CreatedByID  uuid.UUID                 `json:"created_by_id"`
+	Reason       string                    `json:"reason"`
+	RefundAmount float64                   `json:"refund_amount"`
+	FiscalCode   string                    `json:"fiscal_code,omitempty"` // Fiscal number of the return receipt
+	CreatedAt    string                    `json:"created_at"`
+	Items        []OrderReturnItemResponse `json:"items"`
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatus is a step of the order lifecycle.
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusConfirmed       OrderStatus = "CONFIRMED"
+	OrderStatusAwaitingPayment OrderStatus = "AWAITING_PAYMENT"
+	OrderStatusPrepayment      OrderStatus = "PREPAYMENT"
+	OrderStatusPaid            OrderStatus = "PAID"
+	OrderStatusShipped         OrderStatus = "SHIPPED"
+	OrderStatusReadyForPickup  OrderStatus = "READY_FOR_PICKUP"
+	OrderStatusDone            OrderStatus = "DONE"
+	OrderStatusCancelled       OrderStatus = "CANCELLED"
+	OrderStatusReturned        OrderStatus = "RETURNED"
+)
+
+var (
+	// ErrOrderTransitionNotAllowed is returned when the state machine has no edge between two statuses.
+	ErrOrderTransitionNotAllowed = errors.New("order status transition is not allowed")
+	// ErrOrderTransitionForbidden is returned when the edge exists but the caller's role may not use it.
+	ErrOrderTransitionForbidden = errors.New("your role cannot perform this order status transition")
+	// ErrOrderItemsLocked is returned when items are edited on an order that is shipped, done or closed.
+	ErrOrderItemsLocked = errors.New("order items can no longer be changed in this status")
+)
+
+// OrderTransition is an edge of the order state machine together with the side effects it triggers.
+type OrderTransition struct {
+	From              OrderStatus `json:"from"`
+	To                OrderStatus `json:"to"`
+	Roles             []UserRole  `json:"roles"`
+	Restock           bool        `json:"restock"`            // Return the order items to their lots
+	NotifyBuyer       bool        `json:"notify_buyer"`       // Message the buyer through the client bot
+	GenerateDocuments bool        `json:"generate_documents"` // Render the order documents (invoice, receipt)
+}
+
+// AllowsRole reports whether the role may perform the transition.
+func (t OrderTransition) AllowsRole(role UserRole) bool {
+	return slices.Contains(t.Roles, role)
+}
+
+var (
+	orderStaffRoles = []UserRole{RoleStaff, RoleAdmin}
+	orderAdminRoles = []UserRole{RoleAdmin}
+	// Payment edges are also taken automatically when a provider confirms a payment.
+	orderPaymentRoles = []UserRole{RoleStaff, RoleAdmin, RoleSystem}
+	// Buyers may cancel unpaid orders themselves, within the limits of BuyerCancelPolicy.
+	orderCancelRoles = []UserRole{RoleStaff, RoleAdmin, RoleBuyer}
+)
+
+// orderTransitions is the order state machine. Statuses without outgoing edges are final.
+// Cancelling after money was taken and taking back a shipment are reserved for admins.
+// Completed orders stay DONE, goods brought back later go through order returns instead.
+// PREPAYMENT and PAID are reached automatically by payment callbacks, staff can set them for offline payments.
+var orderTransitions = []OrderTransition{
+	{From: OrderStatusNew, To: OrderStatusConfirmed, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusNew, To: OrderStatusAwaitingPayment, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusNew, To: OrderStatusPrepayment, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusNew, To: OrderStatusPaid, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusNew, To: OrderStatusReadyForPickup, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusNew, To: OrderStatusDone, Roles: orderStaffRoles, GenerateDocuments: true}, // Walk-in sales
+	{From: OrderStatusNew, To: OrderStatusCancelled, Roles: orderCancelRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusConfirmed, To: OrderStatusAwaitingPayment, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusConfirmed, To: OrderStatusPrepayment, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusConfirmed, To: OrderStatusPaid, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusConfirmed, To: OrderStatusShipped, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusConfirmed, To: OrderStatusReadyForPickup, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusConfirmed, To: OrderStatusDone, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusConfirmed, To: OrderStatusCancelled, Roles: orderCancelRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusAwaitingPayment, To: OrderStatusConfirmed, Roles: orderStaffRoles},
+	{From: OrderStatusAwaitingPayment, To: OrderStatusPrepayment, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusAwaitingPayment, To: OrderStatusPaid, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusAwaitingPayment, To: OrderStatusCancelled, Roles: orderCancelRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusPrepayment, To: OrderStatusConfirmed, Roles: orderStaffRoles},
+	{From: OrderStatusPrepayment, To: OrderStatusPaid, Roles: orderPaymentRoles, NotifyBuyer: true},
+	{From: OrderStatusPrepayment, To: OrderStatusShipped, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusPrepayment, To: OrderStatusReadyForPickup, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusPrepayment, To: OrderStatusDone, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusPrepayment, To: OrderStatusCancelled, Roles: orderAdminRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusPaid, To: OrderStatusShipped, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusPaid, To: OrderStatusReadyForPickup, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusPaid, To: OrderStatusDone, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusPaid, To: OrderStatusCancelled, Roles: orderAdminRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusReadyForPickup, To: OrderStatusDone, Roles: orderStaffRoles, NotifyBuyer: true, GenerateDocuments: true},
+	{From: OrderStatusReadyForPickup, To: OrderStatusCancelled, Roles: orderStaffRoles, Restock: true, NotifyBuyer: true},
+
+	{From: OrderStatusShipped, To: OrderStatusDone, Roles: orderStaffRoles, NotifyBuyer: true},
+	{From: OrderStatusShipped, To: OrderStatusReturned, Roles: orderAdminRoles, Restock: true, NotifyBuyer: true},
+}
+
+// IsFinal reports whether no transition leaves the status.
+func (s OrderStatus) IsFinal() bool {
+	for _, t := range orderTransitions {
+		if t.From == s {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsItemEdits reports whether items may still be added, changed or removed.
+// Once the order is paid in full, goods have left the shop or the order is closed the item set is frozen.
+func (s OrderStatus) AllowsItemEdits() bool {
+	switch s {
+	case OrderStatusNew, OrderStatusConfirmed, OrderStatusAwaitingPayment, OrderStatusPrepayment, OrderStatusReadyForPickup:
+		return true
+	}
+	return false
+}
+
+// FindOrderTransition looks up the edge from one status to another.
+func FindOrderTransition(from, to OrderStatus) (OrderTransition, bool) {
+	for _, t := range orderTransitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+	return OrderTransition{}, false
+}
+
+// CheckOrderTransition validates a status change for the role and returns the edge to apply.
+func CheckOrderTransition(from, to OrderStatus, role UserRole) (OrderTransition, error) {
+	t, ok := FindOrderTransition(from, to)
+	if !ok {
+		return OrderTransition{}, ErrOrderTransitionNotAllowed
+	}
+	if !t.AllowsRole(role) {
+		return OrderTransition{}, ErrOrderTransitionForbidden
+	}
+	return t, nil
+}
+
+// AllowedOrderTransitions returns the edges leaving the status that the role may use.
+func AllowedOrderTransitions(from OrderStatus, role UserRole) []OrderTransition {
+	allowed := make([]OrderTransition, 0)
+	for _, t := range orderTransitions {
+		if t.From == from && t.AllowsRole(role) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+// OrderTransitionsResponse tells the staff UI which status buttons to render for an order.
+type OrderTransitionsResponse struct {
+	OrderID     uuid.UUID         `json:"order_id"`
+	Status      OrderStatus       `json:"status"`
+	Transitions []OrderTransition `json:"transitions"`
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPaymentProviderUnknown is returned for providers that are not configured.
+	ErrPaymentProviderUnknown = errors.New("payment provider is not configured")
+	// ErrPaymentProvider wraps failures reported by the acquiring API.
+	ErrPaymentProvider = errors.New("payment provider error")
+	// ErrPaymentSignature is returned when a callback signature does not verify.
+	ErrPaymentSignature = errors.New("invalid payment callback signature")
+	// ErrPaymentNotAllowed is returned when the order cannot take a payment (closed or fully paid).
+	ErrPaymentNotAllowed = errors.New("order does not accept payments")
+	// ErrPaymentCancelNotSupported is returned by providers whose links cannot be invalidated before they expire.
+	ErrPaymentCancelNotSupported = errors.New("payment provider cannot cancel a payment link")
+)
+
+// PaymentReasonOrderCancelled is the failure reason of links expired because the buyer cancelled the order.
+const PaymentReasonOrderCancelled = "order cancelled by buyer"
+
+// TruncateRunes cuts value to at most limit characters, for provider fields and reasons with a length limit.
+func TruncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+// PaymentCurrency is the only currency the shop sells in.
+const PaymentCurrency = "UAH"
+
+// PaymentProviderTelegram names Telegram Payments, whose updates arrive through the client bot webhook.
+const PaymentProviderTelegram = "TELEGRAM"
+
+// PaymentStatus is the state of a single payment attempt.
+type PaymentStatus string
+
+const (
+	PaymentStatusPending  PaymentStatus = "PENDING" // Link created, buyer has not paid yet
+	PaymentStatusSuccess  PaymentStatus = "SUCCESS"
+	PaymentStatusFailed   PaymentStatus = "FAILED"
+	PaymentStatusExpired  PaymentStatus = "EXPIRED"
+	PaymentStatusReversed PaymentStatus = "REVERSED" // Refunded or charged back by the acquirer
+)
+
+// IsFinal reports whether the provider can still change the payment. A successful payment can only be reversed.
+func (s PaymentStatus) IsFinal() bool {
+	return s == PaymentStatusFailed || s == PaymentStatusExpired || s == PaymentStatusReversed
+}
+
+// PaymentPurpose tells whether the payment covers part of the order or everything that is due.
+type PaymentPurpose string
+
+const (
+	PaymentPurposePrepayment PaymentPurpose = "PREPAYMENT"
+	PaymentPurposeFull       PaymentPurpose = "FULL"
+)
+
+// InvoiceRequest is what a provider needs to create a payment link. PaymentID is sent as the merchant
+// reference and comes back in the callback.
+type InvoiceRequest struct {
+	PaymentID   uuid.UUID
+	OrderID     uuid.UUID
+	Amount      float64
+	Currency    string
+	Description string
+	CallbackURL string
+	ResultURL   string // Where the buyer lands after paying, optional
+	ExpiresAt   time.Time
+}
+
+// Invoice is the payment link created by the provider.
+type Invoice struct {
+	ProviderRef string
+	URL         string
+}
+
+// PaymentCallback is a raw provider notification as received by the webhook.
+type PaymentCallback struct {
+	Body   []byte
+	Header http.Header
+}
+
+// PaymentEvent is a verified provider notification.
+type PaymentEvent struct {
+	PaymentID     uuid.UUID
+	ProviderRef   string
+	Status        PaymentStatus
+	Amount        float64 // Amount confirmed by the provider, 0 when not reported
+	FailureReason string
+	Raw           []byte
+}
+
+// PaymentProvider is implemented by acquiring adapters.
+type PaymentProvider interface {
+	Name() string
+	CreateInvoice(ctx context.Context, req InvoiceRequest) (*Invoice, error)
+	// ParseCallback verifies the callback signature and decodes the notification.
+	ParseCallback(ctx context.Context, callback PaymentCallback) (*PaymentEvent, error)
+	// CancelInvoice invalidates an unpaid link so the buyer can no longer pay it.
+	CancelInvoice(ctx context.Context, providerRef string) error
+}
+
+// PaymentCheckoutAnswerer is implemented by providers that ask the shop to confirm a checkout before
+// charging the buyer (Telegram Payments pre-checkout queries).
+type PaymentCheckoutAnswerer interface {
+	AnswerCheckout(ctx context.Context, queryID string, errorMessage string) error
+}
+
+// PaymentCheckout is a pre-checkout query: the buyer pressed "Pay" and the provider waits for our answer.
+type PaymentCheckout struct {
+	QueryID  string
+	Payload  string // Our payment ID
+	Currency string
+	Amount   float64
+}
+
+// CreatePaymentDTO requests a payment link for an order.
+type CreatePaymentDTO struct {
+	Provider    string         `json:"provider" binding:"required"`
+	Purpose     PaymentPurpose `json:"purpose" binding:"required,oneof=PREPAYMENT FULL"`
+	Amount      *float64       `json:"amount,omitempty" binding:"omitempty,gt=0"` // Prepayment amount, defaults to the configured share
+	SendToBuyer bool           `json:"send_to_buyer"`                             // Message the link through the client bot
+}
+
+// PaymentResponse represents a payment attempt of an order.
+type PaymentResponse struct {
+	ID            uuid.UUID      `json:"id"`
+	OrderID       uuid.UUID      `json:"order_id"`
+	Provider      string         `json:"provider"`
+	ProviderRef   string         `json:"provider_ref,omitempty"`
+	Purpose       PaymentPurpose `json:"purpose"`
+	Amount        float64        `json:"amount"`
+	Currency      string         `json:"currency"`
+	Status        PaymentStatus  `json:"status"`
+	PaymentURL    string         `json:"payment_url,omitempty"`
+	FailureReason string         `json:"failure_reason,omitempty"`
+	ExpiresAt     string         `json:"expires_at,omitempty"`
+	PaidAt        string         `json:"paid_at,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+}
+
+// CreatePaymentRecord is what the service stores before asking the provider for a link.
+type CreatePaymentRecord struct {
+	OrderID     uuid.UUID
+	Provider    string
+	Purpose     PaymentPurpose
+	Amount      float64
+	ExpiresAt   time.Time
+	CreatedByID uuid.UUID
+}
+
+// PaymentRepository stores payment attempts and their provider state.
+type PaymentRepository interface {
+	Create(ctx context.Context, record CreatePaymentRecord) (*PaymentResponse, error)
+	AttachInvoice(ctx context.Context, id uuid.UUID, invoice Invoice) (*PaymentResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*PaymentResponse, error)
+	ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]PaymentResponse, error)
+	// ApplyEvent stores a provider notification under a row lock and books confirmed and reversed
+	// payments in the order ledger. It reports false when the event repeats the stored state or arrives
+	// after the payment was closed, so callbacks are idempotent. A success is applied even to a FAILED or
+	// EXPIRED payment, since the buyer was charged.
+	ApplyEvent(ctx context.Context, event PaymentEvent) (*PaymentResponse, bool, error)
+}
+
+// PaymentService creates payment links and processes provider callbacks.
+type PaymentService interface {
+	Providers() []string
+	CreatePayment(ctx context.Context, orderID uuid.UUID, dto CreatePaymentDTO, userID uuid.UUID) (*PaymentResponse, error)
+	ListPayments(ctx context.Context, orderID uuid.UUID) ([]PaymentResponse, error)
+	HandleCallback(ctx context.Context, provider string, callback PaymentCallback) error
+	ConfirmCheckout(ctx context.Context, provider string, checkout PaymentCheckout) error
+	// CancelOrderLinks invalidates at the providers the links expired by a buyer cancellation. Best effort,
+	// failures are only logged.
+	CancelOrderLinks(ctx context.Context, orderID uuid.UUID)
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhotoSize names a rendition generated for every uploaded photo.
+type PhotoSize string
+
+const (
+	PhotoSizeThumb PhotoSize = "thumb" // Admin lists and order items
+	PhotoSizeCard  PhotoSize = "card"  // Catalog grid
+	PhotoSizeFull  PhotoSize = "full"  // Lot page and zoom
+)
+
+// PhotoSizeWidths is the maximum width of each rendition. Smaller originals are never upscaled.
+var PhotoSizeWidths = map[PhotoSize]int{
+	PhotoSizeThumb: 320,
+	PhotoSizeCard:  640,
+	PhotoSizeFull:  1080,
+}
+
+// PhotoSizes lists renditions from the smallest to the largest.
+var PhotoSizes = []PhotoSize{PhotoSizeThumb, PhotoSizeCard, PhotoSizeFull}
+
+// PhotoRendition is one size of a photo.
+type PhotoRendition struct {
+	Size   PhotoSize `json:"size"`
+	URL    string    `json:"url"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+}
+
+// PhotoAsset is the rendition set of an uploaded photo. URL is the full-size JPEG,
+// the same value stored in the flat photo arrays of lots and storage contracts.
+type PhotoAsset struct {
+	URL        string           `json:"url"`
+	Width      int              `json:"width"`
+	Height     int              `json:"height"`
+	Renditions []PhotoRendition `json:"renditions"`
+}
+
+// PhotoUpload is a registry entry of an uploaded photo, used by the orphan report.
+type PhotoUpload struct {
+	URL          string     `json:"url"`
+	UploadedByID *uuid.UUID `json:"uploaded_by_id,omitempty"`
+	LotID        *uuid.UUID `json:"lot_id,omitempty"` // Last lot the photo was attached to
+	UploadedAt   string     `json:"uploaded_at"`
+}
+
+// PhotoSweepReport describes unattached photos older than the grace period and what happened to them.
+type PhotoSweepReport struct {
+	DryRun      bool          `json:"dry_run"`
+	GracePeriod string        `json:"grace_period"`
+	Photos      []PhotoUpload `json:"photos"`
+	Deleted     int           `json:"deleted"`
+	Failed      []string      `json:"failed,omitempty"`
+}
+
+// PhotoReprocessStatus is the progress of the batch job that re-renders stored photos,
+// e.g. after the watermark was changed.
+type PhotoReprocessStatus struct {
+	Running    bool     `json:"running"`
+	Total      int64    `json:"total"`
+	Processed  int      `json:"processed"`
+	Failed     []string `json:"failed,omitempty"`
+	StartedAt  string   `json:"started_at,omitempty"`
+	FinishedAt string   `json:"finished_at,omitempty"`
+}
+
+// PhotoUploadStatus is the lifecycle of a direct-to-storage upload.
+type PhotoUploadStatus string
+
+const (
+	PhotoUploadStatusPending    PhotoUploadStatus = "PENDING"    // Presigned URL issued, waiting for the client
+	PhotoUploadStatusProcessing PhotoUploadStatus = "PROCESSING" // Finalized, renditions are being generated
+	PhotoUploadStatusReady      PhotoUploadStatus = "READY"
+	PhotoUploadStatusFailed     PhotoUploadStatus = "FAILED"
+	PhotoUploadStatusExpired    PhotoUploadStatus = "EXPIRED" // Never finalized
+)
+
+// PresignPhotoUploadDTO declares the file the client is about to upload.
+type PresignPhotoUploadDTO struct {
+	ContentType string `json:"content_type" binding:"required,oneof=image/jpeg image/png"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+}
+
+// PresignedPhotoUpload tells the client where to POST the file: a multipart form with every FormData field
+// and the file last, in a field named "file". Storage enforces the declared type and size, the real type and
+// dimensions are checked on finalize.
+type PresignedPhotoUpload struct {
+	UploadID  uuid.UUID         `json:"upload_id"`
+	ObjectKey string            `json:"object_key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	FormData  map[string]string `json:"form_data"`
+	ExpiresAt string            `json:"expires_at"`
+	MaxSize   int64             `json:"max_size"`
+}
+
+// PhotoUploadSession tracks a direct upload from the presigned URL to the finished rendition set.
+type PhotoUploadSession struct {
+	ID           uuid.UUID         `json:"id"`
+	Status       PhotoUploadStatus `json:"status"`
+	ObjectKey    string            `json:"object_key"`
+	ContentType  string            `json:"content_type"`
+	UploadedByID uuid.UUID         `json:"uploaded_by_id"`
+	URL          string            `json:"url,omitempty"`   // Full-size JPEG once READY
+	Photo        *PhotoAsset       `json:"photo,omitempty"` // Rendition set once READY
+	Error        string            `json:"error,omitempty"`
+	ExpiresAt    string            `json:"expires_at"`
+	CreatedAt    string            `json:"created_at"`
+	Deadline     time.Time         `json:"-"` // ExpiresAt as a time, for comparisons
+}
+
+// StoredObjectInfo describes a raw object uploaded by a client, read without downloading the whole file.
+type StoredObjectInfo struct {
+	Size        int64
+	ContentType string // Sniffed from the content, not the header sent by the client
+	Width       int
+	Height      int
+}
+
+// PhotoUploadSessionRepository stores direct upload sessions.
+type PhotoUploadSessionRepository interface {
+	Create(ctx context.Context, session *PhotoUploadSession, expiresAt time.Time) error
+	GetByID(ctx context.Context, id uuid.UUID) (*PhotoUploadSession, error)
+	// UpdateStatus moves a session to status only if it is currently in one of from.
+	UpdateStatus(ctx context.Context, id uuid.UUID, from []PhotoUploadStatus, status PhotoUploadStatus, url string, errMsg string) error
+	ListByStatus(ctx context.Context, status PhotoUploadStatus) ([]PhotoUploadSession, error)
+	ListExpired(ctx context.Context, now time.Time) ([]PhotoUploadSession, error)
+}
+
+// PhotoAssetRepository is the upload registry: rendition sets keyed by the photo URL,
+// who uploaded them, and which lot they belong to.
+type PhotoAssetRepository interface {
+	Create(ctx context.Context, asset *PhotoAsset, uploadedByID uuid.UUID) error
+	GetByURL(ctx context.Context, url string) (*PhotoAsset, error)
+	// ListURLs pages through all registered photos ordered by URL, starting after afterURL.
+	ListURLs(ctx context.Context, afterURL string, limit int) ([]string, error)
+	Count(ctx context.Context) (int64, error)
+	UpdateRenditions(ctx context.Context, asset *PhotoAsset) error
+	// ReplaceLegacyPhoto points lots, storage contracts and order items from oldURL to the converted asset
+	// and registers it.
+	ReplaceLegacyPhoto(ctx context.Context, oldURL string, asset *PhotoAsset) error
+	AttachToLot(ctx context.Context, lotID uuid.UUID, urls []string) error
+	// IsReferenced reports whether a live lot, a storage contract or an order item still shows the photo.
+	IsReferenced(ctx context.Context, url string) (bool, error)
+	// ListOrphans returns registered photos uploaded before the given time that nothing references.
+	ListOrphans(ctx context.Context, uploadedBefore time.Time, limit int) ([]PhotoUpload, error)
+	// FilterUnregisteredOrphans keeps the URLs that are neither registered nor referenced.
+	FilterUnregisteredOrphans(ctx context.Context, urls []string) ([]string, error)
+	DeleteByURL(ctx context.Context, url string) error
+}
+
+// PhotoService uploads photos with all their renditions and keeps the registry in sync with storage.
+type PhotoService interface {
+	UploadPhoto(ctx context.Context, file io.Reader, originalFilename string, uploadedByID uuid.UUID) (*PhotoAsset, error)
+	DeletePhoto(ctx context.Context, url string) error
+	// PresignUpload issues a short-lived URL for uploading straight to storage.
+	PresignUpload(ctx context.Context, dto PresignPhotoUploadDTO, userID uuid.UUID) (*PresignedPhotoUpload, error)
+	// FinalizeUpload validates the uploaded object and queues rendition processing.
+	FinalizeUpload(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*PhotoUploadSession, error)
+	GetUpload(ctx context.Context, id uuid.UUID) (*PhotoUploadSession, error)
+	AttachToLot(ctx context.Context, lotID uuid.UUID, urls []string) error
+	// ReleasePhotos deletes the given photos from storage unless something else still references them.
+	ReleasePhotos(ctx context.Context, urls []string)
+	SweepOrphans(ctx context.Context, dryRun bool) (*PhotoSweepReport, error)
+	// SweepUnregisteredPhotos is a one-off pass over storage for photos uploaded before the registry existed.
+	SweepUnregisteredPhotos(ctx context.Context, dryRun bool) (*PhotoSweepReport, error)
+	// StartReprocess re-renders every registered photo in the background and converts single files from before
+	// renditions existed. Only one job runs at a time.
+	StartReprocess(ctx context.Context) (*PhotoReprocessStatus, error)
+	ReprocessStatus() PhotoReprocessStatus
+	Start(ctx context.Context)
+}
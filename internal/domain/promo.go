@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPromoCodeInvalid is returned when a promo code cannot be applied to the order.
+	ErrPromoCodeInvalid = errors.New("promo code cannot be applied")
+	// ErrPromoCodeExists is returned when the code is already taken.
+	ErrPromoCodeExists = errors.New("promo code already exists")
+)
+
+// PromoCodeDateLayout is the layout of promo code validity dates. Both ends are inclusive days.
+const PromoCodeDateLayout = "2006-01-02"
+
+// PromoDiscountType tells how the promo value is applied.
+type PromoDiscountType string
+
+const (
+	PromoDiscountPercent PromoDiscountType = "PERCENT" // Value is a percentage of the eligible items
+	PromoDiscountFixed   PromoDiscountType = "FIXED"   // Value is an amount off the eligible items
+)
+
+// PromoRules is the part of a promo code that decides the discount. Empty filters match any item.
+type PromoRules struct {
+	Type          PromoDiscountType
+	Value         float64
+	MaxDiscount   float64 // Cap for percentage codes, 0 means no cap
+	MinOrderTotal float64 // Order subtotal before the discount
+	LotTypes      []string
+	Brands        []string
+	Conditions    []string
+}
+
+// PromoLine is one order line as seen by the promo rules.
+type PromoLine struct {
+	LotType   string
+	Brand     string
+	Condition string
+	Amount    float64 // Price times quantity
+}
+
+func (r PromoRules) matches(line PromoLine) bool {
+	if len(r.LotTypes) > 0 && !slices.Contains(r.LotTypes, line.LotType) {
+		return false
+	}
+	if len(r.Conditions) > 0 && !slices.Contains(r.Conditions, line.Condition) {
+		return false
+	}
+	if len(r.Brands) > 0 && !slices.ContainsFunc(r.Brands, func(brand string) bool {
+		return strings.EqualFold(brand, line.Brand)
+	}) {
+		return false
+	}
+	return true
+}
+
+// Discount computes the order discount and splits it across the eligible lines proportionally to their
+// amount, rounded to kopecks. The per-line shares always add up to the total.
+func (r PromoRules) Discount(lines []PromoLine) ([]float64, float64, error) {
+	var subtotal, eligible float64
+	for _, line := range lines {
+		subtotal += line.Amount
+		if r.matches(line) {
+			eligible += line.Amount
+		}
+	}
+
+	if subtotal < r.MinOrderTotal {
+		return nil, 0, fmt.Errorf("%w: order total must be at least %.2f", ErrPromoCodeInvalid, r.MinOrderTotal)
+	}
+	if eligible <= 0 {
+		return nil, 0, fmt.Errorf("%w: no items in the order qualify", ErrPromoCodeInvalid)
+	}
+
+	var total float64
+	switch r.Type {
+	case PromoDiscountPercent:
+		total = eligible * r.Value / 100
+		if r.MaxDiscount > 0 && total > r.MaxDiscount {
+			total = r.MaxDiscount
+		}
+	case PromoDiscountFixed:
+		total = math.Min(r.Value, eligible)
+	}
+	total = math.Round(total*100) / 100
+
+	shares := make([]float64, len(lines))
+	last := -1
+	for i, line := range lines {
+		if r.matches(line) {
+			last = i
+		}
+	}
+	var allocated float64
+	for i, line := range lines {
+		if !r.matches(line) {
+			continue
+		}
+		if i == last {
+			shares[i] = math.Round((total-allocated)*100) / 100
+			break
+		}
+		shares[i] = math.Round(total*line.Amount/eligible*100) / 100
+		allocated += shares[i]
+	}
+
+	return shares, total, nil
+}
+
+// CreatePromoCodeDTO creates a promo code. Dates are inclusive days, empty means open-ended.
+type CreatePromoCodeDTO struct {
+	Code          string            `json:"code" binding:"required,min=3,max=40"`
+	Description   string            `json:"description"`
+	Type          PromoDiscountType `json:"type" binding:"required,oneof=PERCENT FIXED"`
+	Value         float64           `json:"value" binding:"required,gt=0"`
+	MaxDiscount   float64           `json:"max_discount" binding:"gte=0"`
+	MinOrderTotal float64           `json:"min_order_total" binding:"gte=0"`
+	LotTypes      []string          `json:"lot_types" binding:"omitempty,dive,oneof=TIRE RIM ACCESSORY"`
+	Brands        []string          `json:"brands"`
+	Conditions    []string          `json:"conditions" binding:"omitempty,dive,oneof=NEW USED"`
+	StartsAt      string            `json:"starts_at" binding:"omitempty,datetime=2006-01-02"`
+	EndsAt        string            `json:"ends_at" binding:"omitempty,datetime=2006-01-02"`
+	UsageLimit    int               `json:"usage_limit" binding:"gte=0"`    // Orders in total, 0 means unlimited
+	PerUserLimit  int               `json:"per_user_limit" binding:"gte=0"` // Orders per buyer, 0 means unlimited
+	Active        *bool             `json:"active"`                         // Defaults to true
+}
+
+// UpdatePromoCodeDTO replaces the rules of a promo code. The code itself cannot change.
+type UpdatePromoCodeDTO struct {
+	Description   string            `json:"description"`
+	Type          PromoDiscountType `json:"type" binding:"required,oneof=PERCENT FIXED"`
+	Value         float64           `json:"value" binding:"required,gt=0"`
+	MaxDiscount   float64           `json:"max_discount" binding:"gte=0"`
+	MinOrderTotal float64           `json:"min_order_total" binding:"gte=0"`
+	LotTypes      []string          `json:"lot_types" binding:"omitempty,dive,oneof=TIRE RIM ACCESSORY"`
+	Brands        []string          `json:"brands"`
+	Conditions    []string          `json:"conditions" binding:"omitempty,dive,oneof=NEW USED"`
+	StartsAt      string            `json:"starts_at" binding:"omitempty,datetime=2006-01-02"`
+	EndsAt        string            `json:"ends_at" binding:"omitempty,datetime=2006-01-02"`
+	UsageLimit    int               `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit  int               `json:"per_user_limit" binding:"gte=0"`
+	Active        bool              `json:"active"`
+}
+
+// PromoCodeResponse represents a promo code with its usage. Cancelled orders do not count as usage.
+type PromoCodeResponse struct {
+	ID            uuid.UUID         `json:"id"`
+	Code          string            `json:"code"`
+	Description   string            `json:"description,omitempty"`
+	Type          PromoDiscountType `json:"type"`
+	Value         float64           `json:"value"`
+	MaxDiscount   float64           `json:"max_discount,omitempty"`
+	MinOrderTotal float64           `json:"min_order_total,omitempty"`
+	LotTypes      []string          `json:"lot_types"`
+	Brands        []string          `json:"brands"`
+	Conditions    []string          `json:"conditions"`
+	StartsAt      string            `json:"starts_at,omitempty"`
+	EndsAt        string            `json:"ends_at,omitempty"`
+	UsageLimit    int               `json:"usage_limit"`
+	PerUserLimit  int               `json:"per_user_limit"`
+	Active        bool              `json:"active"`
+	TimesUsed     int               `json:"times_used"`
+	TotalDiscount float64           `json:"total_discount"`
+	CreatedAt     string            `json:"created_at"`
+}
+
+// PromoCodeUsageOrder is an order that used the promo code.
+type PromoCodeUsageOrder struct {
+	OrderID        uuid.UUID `json:"order_id"`
+	CustomerName   string    `json:"customer_name"`
+	CustomerPhone  string    `json:"customer_phone"`
+	Status         string    `json:"status"`
+	DiscountAmount float64   `json:"discount_amount"`
+	TotalAmount    float64   `json:"total_amount"`
+	CreatedAt      string    `json:"created_at"`
+}
+
+// PromoCodeUsageReport summarizes what a promo code brought in and cost.
+type PromoCodeUsageReport struct {
+	PromoCode       PromoCodeResponse     `json:"promo_code"`
+	Orders          int                   `json:"orders"`
+	CompletedOrders int                   `json:"completed_orders"`
+	Revenue         float64               `json:"revenue"` // Net totals of the orders that used the code
+	TotalDiscount   float64               `json:"total_discount"`
+	UniqueBuyers    int                   `json:"unique_buyers"`
+	Usage           []PromoCodeUsageOrder `json:"usage"`
+}
+
+// PromoCodeFilter defines criteria for listing promo codes.
+type PromoCodeFilter struct {
+	Page     int
+	PageSize int
+	Search   string // Code prefix
+	Active   *bool
+}
+
+// PromoCodeRepository stores promo codes. Redemption happens inside the order transaction.
+type PromoCodeRepository interface {
+	Create(ctx context.Context, dto CreatePromoCodeDTO, userID uuid.UUID) (*PromoCodeResponse, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdatePromoCodeDTO) (*PromoCodeResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*PromoCodeResponse, error)
+	List(ctx context.Context, filter PromoCodeFilter) ([]PromoCodeResponse, int64, error)
+	GetUsage(ctx context.Context, id uuid.UUID) (*PromoCodeUsageReport, error)
+}
+
+// PromoCodeService manages promo codes for admins.
+type PromoCodeService interface {
+	CreatePromoCode(ctx context.Context, dto CreatePromoCodeDTO, userID uuid.UUID) (*PromoCodeResponse, error)
+	UpdatePromoCode(ctx context.Context, id uuid.UUID, dto UpdatePromoCodeDTO) (*PromoCodeResponse, error)
+	GetPromoCode(ctx context.Context, id uuid.UUID) (*PromoCodeResponse, error)
+	ListPromoCodes(ctx context.Context, filter PromoCodeFilter) ([]PromoCodeResponse, int64, error)
+	GetPromoCodeUsage(ctx context.Context, id uuid.UUID) (*PromoCodeUsageReport, error)
+}
@@ -16,13 +16,17 @@ const (
 )
 
 // PnLReport represents the Profit and Loss financial data.
+// Sales and COGS are net of returns registered in the period, revenue is net of promo discounts.
 type PnLReport struct {
-	TotalItemsSold int            `json:"total_items_sold"`
-	TotalRevenue   float64        `json:"total_revenue"`
-	TotalCOGS      float64        `json:"total_cogs"`
-	TotalProfit    float64        `json:"total_profit"`
-	ByWarehouse    []WarehousePnL `json:"by_warehouse"`
-	ByChannel      []ChannelPnL   `json:"by_channel"`
+	TotalItemsSold     int            `json:"total_items_sold"`
+	TotalRevenue       float64        `json:"total_revenue"`
+	TotalCOGS          float64        `json:"total_cogs"`
+	TotalProfit        float64        `json:"total_profit"`
+	TotalDiscounts     float64        `json:"total_discounts"`
+	TotalItemsReturned int            `json:"total_items_returned"`
+	TotalRefunds       float64        `json:"total_refunds"`
+	ByWarehouse        []WarehousePnL `json:"by_warehouse"`
+	ByChannel          []ChannelPnL   `json:"by_channel"`
 }
 
 type LotAnalyticsTotals struct {
@@ -90,11 +94,13 @@ type ReportService interface {
 
 // WarehousePnL contains finances per warehouse.
 type WarehousePnL struct {
-	WarehouseName string  `json:"warehouse_name"`
-	ItemsSold     int     `json:"items_sold"`
-	Revenue       float64 `json:"revenue"`
-	COGS          float64 `json:"cogs"`
-	Profit        float64 `json:"profit"`
+	WarehouseID   uuid.UUID `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	ItemsSold     int       `json:"items_sold"`
+	Revenue       float64   `json:"revenue"`
+	COGS          float64   `json:"cogs"`
+	Profit        float64   `json:"profit"`
+	Discounts     float64   `json:"discounts"`
 }
 
 // ChannelPnL contains financial metrics grouped by sales channel.
@@ -104,4 +110,5 @@ type ChannelPnL struct {
 	Revenue   float64      `json:"revenue"`
 	COGS      float64      `json:"cogs"`
 	Profit    float64      `json:"profit"`
+	Discounts float64      `json:"discounts"`
 }
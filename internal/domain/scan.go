@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ScanEntityType tells which entity a printed QR code points to.
+type ScanEntityType string
+
+const (
+	ScanEntityLot             ScanEntityType = "LOT"
+	ScanEntityTransfer        ScanEntityType = "TRANSFER"
+	ScanEntityStorageContract ScanEntityType = "STORAGE_CONTRACT"
+	ScanEntityLocation        ScanEntityType = "LOCATION"
+	ScanEntityBarcode         ScanEntityType = "BARCODE" // Manufacturer EAN, may match several lots
+)
+
+// Scan code prefixes. Codes only use [A-Za-z0-9_-] so they are valid Telegram startapp parameters.
+var scanCodePrefixes = map[ScanEntityType]string{
+	ScanEntityLot:             "lot_",
+	ScanEntityTransfer:        "transfer_",
+	ScanEntityStorageContract: "storage_",
+	ScanEntityLocation:        "bin_",
+}
+
+// ErrScanForbidden is returned when a guest or buyer scans a code meant for staff only.
+var ErrScanForbidden = errors.New("this code is available to staff only")
+
+// ScanCode builds the code printed in QR labels, e.g. "lot_<uuid>".
+func ScanCode(entity ScanEntityType, id uuid.UUID) string {
+	return scanCodePrefixes[entity] + id.String()
+}
+
+// ParseScanCode accepts a scan code, a full deep link carrying it (startapp/start param or last path segment),
+// or a bare UUID printed by older lot labels.
+func ParseScanCode(raw string) (ScanEntityType, uuid.UUID, error) {
+	code := strings.TrimSpace(raw)
+
+	if strings.Contains(code, "/") || strings.Contains(code, "?") {
+		if parsed, err := url.Parse(code); err == nil {
+			query := parsed.Query()
+			switch {
+			case query.Get("startapp") != "":
+				code = query.Get("startapp")
+			case query.Get("start") != "":
+				code = query.Get("start")
+			default:
+				code = parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]
+			}
+		}
+	}
+
+	for entity, prefix := range scanCodePrefixes {
+		if strings.HasPrefix(code, prefix) {
+			id, err := uuid.Parse(strings.TrimPrefix(code, prefix))
+			if err != nil {
+				return "", uuid.Nil, fmt.Errorf("invalid scan code: %s", raw)
+			}
+			return entity, id, nil
+		}
+	}
+
+	// Legacy lot labels carried just the lot ID.
+	if id, err := uuid.Parse(code); err == nil {
+		return ScanEntityLot, id, nil
+	}
+
+	return "", uuid.Nil, fmt.Errorf("unknown scan code: %s", raw)
+}
+
+// ScanResult is what a scanned code refers to. Only the field matching Type is filled.
+type ScanResult struct {
+	Type            ScanEntityType           `json:"type"`
+	ID              uuid.UUID                `json:"id"`
+	Lot             *LotPublicResponse       `json:"lot,omitempty"`          // Guests and buyers
+	InternalLot     *LotInternalResponse     `json:"internal_lot,omitempty"` // Staff
+	Transfer        *TransferResponse        `json:"transfer,omitempty"`
+	StorageContract *StorageContractResponse `json:"storage_contract,omitempty"`
+	Location        *WarehouseLocation       `json:"location,omitempty"`
+	LocationLots    []LotInternalResponse    `json:"location_lots,omitempty"` // Lots stored in the location and nested ones
+	Barcode         string                   `json:"barcode,omitempty"`
+	Lots            []LotPublicResponse      `json:"lots,omitempty"`          // EAN matches for guests and buyers
+	InternalLots    []LotInternalResponse    `json:"internal_lots,omitempty"` // EAN matches for staff
+}
+
+// ScanService resolves scanned QR codes, lot SKUs and manufacturer EANs for the shared scanner screen.
+type ScanService interface {
+	Resolve(ctx context.Context, code string, role string) (*ScanResult, error)
+}
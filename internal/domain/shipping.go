@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrShippingNotConfigured is returned by providers that are missing credentials.
+	ErrShippingNotConfigured = errors.New("shipping provider is not configured")
+	// ErrShippingProvider wraps failures reported by the carrier API.
+	ErrShippingProvider = errors.New("shipping provider error")
+	// ErrShipmentExists is returned when a waybill was already created for the order.
+	ErrShipmentExists = errors.New("order already has a waybill")
+	// ErrNothingToCollect is returned when cash on delivery is requested for an order with no balance due.
+	ErrNothingToCollect = errors.New("order has no balance to collect on delivery")
+)
+
+// ShippingPayer tells who pays for the delivery.
+type ShippingPayer string
+
+const (
+	ShippingPayerSender    ShippingPayer = "SENDER"
+	ShippingPayerRecipient ShippingPayer = "RECIPIENT"
+)
+
+// ShipmentState is the provider-independent delivery state used for notifications and polling.
+type ShipmentState string
+
+const (
+	ShipmentStateCreated   ShipmentState = "CREATED"    // Waybill exists, parcel not handed over yet
+	ShipmentStateInTransit ShipmentState = "IN_TRANSIT" // Accepted by the carrier
+	ShipmentStateArrived   ShipmentState = "ARRIVED"    // Waiting at the recipient branch
+	ShipmentStateDelivered ShipmentState = "DELIVERED"
+	ShipmentStateReturned  ShipmentState = "RETURNED" // Refused or returned to the sender
+	ShipmentStateCancelled ShipmentState = "CANCELLED"
+)
+
+// IsFinal reports whether tracking can stop.
+func (s ShipmentState) IsFinal() bool {
+	return s == ShipmentStateDelivered || s == ShipmentStateReturned || s == ShipmentStateCancelled
+}
+
+// ShippingCity is a city known to the carrier.
+type ShippingCity struct {
+	Ref  string `json:"ref"`
+	Name string `json:"name"`
+	Area string `json:"area,omitempty"`
+}
+
+// ShippingBranch is a carrier branch or parcel locker in a city.
+type ShippingBranch struct {
+	Ref         string `json:"ref"`
+	Number      string `json:"number"`
+	Description string `json:"description"`
+	CityRef     string `json:"city_ref"`
+}
+
+// ShippingParcel is one seat of a shipment. Sizes are in centimeters, weight in kilograms.
+type ShippingParcel struct {
+	Weight float64 `json:"weight"`
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Fallback seat for lots without usable size params (accessories, incomplete cards).
+var defaultShippingParcel = ShippingParcel{Weight: 2, Length: 40, Width: 30, Height: 20}
+
+// ParcelForLot estimates the packed size of one unit of a lot. A tire travels unboxed, so its
+// seat is the outer diameter squared by the tread width. Weights are rough averages used for the
+// declared weight only; the carrier re-weighs on acceptance.
+func ParcelForLot(lotType string, params LotParams) ShippingParcel {
+	switch {
+	case lotType == "TIRE" && params.Width > 0 && params.Profile > 0 && params.Diameter > 0:
+		outerMM := params.Diameter*25.4 + 2*params.Width*params.Profile/100
+		outer := math.Ceil(outerMM / 10)
+		return ShippingParcel{
+			Weight: math.Round(params.Width*params.Diameter/350*10) / 10,
+			Length: outer,
+			Width:  outer,
+			Height: math.Ceil(params.Width / 10),
+		}
+	case lotType == "RIM" && params.Diameter > 0:
+		side := math.Ceil(params.Diameter*2.54) + 5
+		height := float64(25)
+		if params.Width > 0 {
+			// Rim width is given in inches.
+			height = math.Ceil(params.Width*2.54) + 5
+		}
+		return ShippingParcel{
+			Weight: math.Round(params.Diameter*0.6*10) / 10,
+			Length: side,
+			Width:  side,
+			Height: height,
+		}
+	default:
+		return defaultShippingParcel
+	}
+}
+
+// WaybillRequest is what the provider needs to create a waybill. The sender comes from the provider config.
+type WaybillRequest struct {
+	RecipientFirstName string
+	RecipientLastName  string
+	RecipientPhone     string
+	CityRef            string
+	BranchRef          string
+	Description        string
+	DeclaredValue      float64
+	CashOnDelivery     float64 // 0 disables cash on delivery
+	Payer              ShippingPayer
+	Parcels            []ShippingParcel
+}
+
+// Waybill is the carrier document created for a shipment.
+type Waybill struct {
+	Ref               string
+	TrackingNumber    string
+	Cost              float64
+	EstimatedDelivery string
+}
+
+// TrackingQuery asks for the status of one waybill. The recipient phone unlocks full details on some carriers.
+type TrackingQuery struct {
+	TrackingNumber string
+	Phone          string
+}
+
+// TrackingStatus is the carrier status of a waybill.
+type TrackingStatus struct {
+	TrackingNumber string
+	StatusCode     string
+	Status         string // Carrier wording, shown to staff and buyers as is
+	State          ShipmentState
+}
+
+// ShippingProvider is implemented by carrier adapters.
+type ShippingProvider interface {
+	Name() string
+	SearchCities(ctx context.Context, query string) ([]ShippingCity, error)
+	ListBranches(ctx context.Context, cityRef string, query string) ([]ShippingBranch, error)
+	CreateWaybill(ctx context.Context, req WaybillRequest) (*Waybill, error)
+	Track(ctx context.Context, queries []TrackingQuery) ([]TrackingStatus, error)
+}
+
+// CreateShipmentDTO creates a waybill for an order. Recipient fields default to the order customer.
+type CreateShipmentDTO struct {
+	CityRef            string        `json:"city_ref" binding:"required"`
+	CityName           string        `json:"city_name"`
+	BranchRef          string        `json:"branch_ref" binding:"required"`
+	BranchName         string        `json:"branch_name"`
+	RecipientFirstName string        `json:"recipient_first_name"`
+	RecipientLastName  string        `json:"recipient_last_name"`
+	RecipientPhone     string        `json:"recipient_phone"`
+	Payer              ShippingPayer `json:"payer" binding:"omitempty,oneof=SENDER RECIPIENT"`
+	CashOnDelivery     bool          `json:"cash_on_delivery"` // Collect the outstanding balance on pickup
+	Description        string        `json:"description"`
+}
+
+// ShipmentResponse represents the shipment of an order.
+type ShipmentResponse struct {
+	ID                uuid.UUID        `json:"id"`
+	OrderID           uuid.UUID        `json:"order_id"`
+	Provider          string           `json:"provider"`
+	TrackingNumber    string           `json:"tracking_number"`
+	RecipientName     string           `json:"recipient_name"`
+	RecipientPhone    string           `json:"recipient_phone"`
+	CityName          string           `json:"city_name,omitempty"`
+	BranchName        string           `json:"branch_name,omitempty"`
+	Parcels           []ShippingParcel `json:"parcels"`
+	Cost              float64          `json:"cost"`
+	CashOnDelivery    float64          `json:"cash_on_delivery,omitempty"`
+	EstimatedDelivery string           `json:"estimated_delivery,omitempty"`
+	State             ShipmentState    `json:"state"`
+	StatusCode        string           `json:"status_code,omitempty"`
+	Status            string           `json:"status,omitempty"`
+	LastCheckedAt     string           `json:"last_checked_at,omitempty"`
+	CreatedAt         string           `json:"created_at"`
+}
+
+// CreateShipmentRecord is what the service stores after the carrier accepted the waybill.
+type CreateShipmentRecord struct {
+	OrderID        uuid.UUID
+	Provider       string
+	Waybill        Waybill
+	RecipientName  string
+	RecipientPhone string
+	CityRef        string
+	CityName       string
+	BranchRef      string
+	BranchName     string
+	Parcels        []ShippingParcel
+	CashOnDelivery float64
+	CreatedByID    uuid.UUID
+}
+
+// ShipmentRepository stores waybills and their tracking state.
+type ShipmentRepository interface {
+	// Create stores the shipment and copies the tracking number onto the order.
+	Create(ctx context.Context, record CreateShipmentRecord) (*ShipmentResponse, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*ShipmentResponse, error)
+	ListActive(ctx context.Context, provider string, limit int) ([]ShipmentResponse, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status TrackingStatus) error
+	MarkChecked(ctx context.Context, ids []uuid.UUID) error
+}
+
+// ShippingService handles waybills and tracking for orders.
+type ShippingService interface {
+	SearchCities(ctx context.Context, query string) ([]ShippingCity, error)
+	ListBranches(ctx context.Context, cityRef string, query string) ([]ShippingBranch, error)
+	CreateShipment(ctx context.Context, orderID uuid.UUID, dto CreateShipmentDTO, userID uuid.UUID) (*ShipmentResponse, error)
+	GetShipment(ctx context.Context, orderID uuid.UUID) (*ShipmentResponse, error)
+	RefreshShipment(ctx context.Context, orderID uuid.UUID) (*ShipmentResponse, error)
+	Start(ctx context.Context)
+}
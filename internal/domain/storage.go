@@ -3,13 +3,51 @@ package domain
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // StorageService defines the contract for saving and deleting files from object storage (MinIO/S3).
 type StorageService interface {
-	// UploadPhoto optimizes the image, saves it to S3, and returns the public URL.
-	UploadPhoto(ctx context.Context, file io.Reader, originalFilename string) (string, error)
+	// UploadPhoto strips metadata, renders the thumb, card and full sizes plus a private master,
+	// saves them to S3, and returns the rendition set.
+	UploadPhoto(ctx context.Context, file io.Reader, originalFilename string) (*PhotoAsset, error)
 
-	// DeletePhoto removes the file from S3 using its public URL.
+	// ReprocessPhoto renders the renditions of a stored photo again with the current settings (e.g. a new watermark).
+	// A single file uploaded before renditions existed becomes a rendition set with a new URL; the old file
+	// is kept until the caller has moved the references and deletes it.
+	ReprocessPhoto(ctx context.Context, fileURL string) (*PhotoAsset, error)
+
+	// DeletePhoto removes the file and all its renditions from S3 using its public URL.
 	DeletePhoto(ctx context.Context, fileURL string) error
+
+	// PresignUpload returns a form the client can POST the object with without going through the API.
+	// Storage itself rejects files of another content type or larger than maxSize.
+	PresignUpload(ctx context.Context, objectKey string, contentType string, maxSize int64, ttl time.Duration) (*PresignedPost, error)
+
+	// InspectObject reads the size, real content type and image dimensions of a raw object.
+	InspectObject(ctx context.Context, objectKey string) (*StoredObjectInfo, error)
+
+	// OpenObject streams a raw object.
+	OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error)
+
+	// DeleteObject removes a raw object by its key.
+	DeleteObject(ctx context.Context, objectKey string) error
+
+	// ListPhotos lists every public photo in storage by its URL: full-size renditions and single files
+	// uploaded before renditions existed.
+	ListPhotos(ctx context.Context) ([]StoredPhoto, error)
+}
+
+// StoredPhoto is a photo found by listing storage. URL is the value lots and orders store.
+type StoredPhoto struct {
+	URL        string
+	ModifiedAt time.Time
+	Legacy     bool // A single file uploaded before renditions existed
+}
+
+// PresignedPost is a signed browser upload: a multipart/form-data POST to URL with every FormData field,
+// followed by the file in a field named "file".
+type PresignedPost struct {
+	URL      string
+	FormData map[string]string
 }
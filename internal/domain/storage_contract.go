@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StorageContractStatus defines the lifecycle of a seasonal tire storage ("tire hotel") contract.
+type StorageContractStatus string
+
+const (
+	StorageContractStatusStored   StorageContractStatus = "STORED"
+	StorageContractStatusReturned StorageContractStatus = "RETURNED"
+	StorageContractStatusOverdue  StorageContractStatus = "OVERDUE" // End date passed, tires still with us
+)
+
+// CreateStorageContractDTO is the payload to take customer tires into storage.
+// Stored tires belong to the customer, so they are never turned into lots and never reach P&L.
+type CreateStorageContractDTO struct {
+	CustomerName       string    `json:"customer_name" binding:"required"`
+	CustomerPhone      string    `json:"customer_phone" binding:"required"`
+	CustomerTelegramID *int64    `json:"customer_telegram_id"` // Optional, enables bot reminders
+	VehiclePlate       string    `json:"vehicle_plate" binding:"required"`
+	TireDescription    string    `json:"tire_description" binding:"required"`
+	Quantity           int       `json:"quantity" binding:"required,gt=0"`
+	Photos             []string  `json:"photos"`
+	WarehouseID        uuid.UUID `json:"warehouse_id" binding:"required"`
+	BinLocation        string    `json:"bin_location"`
+	StartDate          string    `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate            string    `json:"end_date" binding:"required,datetime=2006-01-02"`
+	Price              float64   `json:"price" binding:"gte=0"`
+	Comment            string    `json:"comment"`
+}
+
+// UpdateStorageContractDTO contains fields that can be updated while tires are stored.
+type UpdateStorageContractDTO struct {
+	CustomerName       *string    `json:"customer_name"`
+	CustomerPhone      *string    `json:"customer_phone"`
+	CustomerTelegramID *int64     `json:"customer_telegram_id"` // 0 clears it and stops bot reminders
+	VehiclePlate       *string    `json:"vehicle_plate"`
+	TireDescription    *string    `json:"tire_description"`
+	Quantity           *int       `json:"quantity" binding:"omitempty,gt=0"`
+	Photos             []string   `json:"photos"`
+	WarehouseID        *uuid.UUID `json:"warehouse_id"`
+	BinLocation        *string    `json:"bin_location"`
+	EndDate            *string    `json:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	Price              *float64   `json:"price" binding:"omitempty,gte=0"`
+	Comment            *string    `json:"comment"`
+}
+
+// ReturnStorageContractDTO closes the contract when the customer picks the tires up.
+type ReturnStorageContractDTO struct {
+	Comment string `json:"comment"`
+}
+
+// StorageContractFilter defines criteria for searching storage contracts.
+type StorageContractFilter struct {
+	Page        int
+	PageSize    int
+	Status      string
+	WarehouseID string
+	Search      string // Customer name, phone or vehicle plate
+}
+
+// StorageContractResponse represents the storage contract returned to staff.
+type StorageContractResponse struct {
+	ID                    uuid.UUID             `json:"id"`
+	CustomerName          string                `json:"customer_name"`
+	CustomerPhone         string                `json:"customer_phone"`
+	CustomerTelegramID    *int64                `json:"customer_telegram_id,omitempty"`
+	VehiclePlate          string                `json:"vehicle_plate"`
+	TireDescription       string                `json:"tire_description"`
+	Quantity              int                   `json:"quantity"`
+	Photos                []string              `json:"photos"`
+	WarehouseID           uuid.UUID             `json:"warehouse_id"`
+	BinLocation           string                `json:"bin_location,omitempty"`
+	StartDate             string                `json:"start_date"`
+	EndDate               string                `json:"end_date"`
+	Price                 float64               `json:"price"`
+	Status                StorageContractStatus `json:"status"`
+	Comment               string                `json:"comment,omitempty"`
+	CreatedBy             uuid.UUID             `json:"created_by"`
+	ReturnedAt            *string               `json:"returned_at,omitempty"`
+	ReminderSentAt        *string               `json:"reminder_sent_at,omitempty"`
+	OverdueReminderSentAt *string               `json:"overdue_reminder_sent_at,omitempty"`
+	CreatedAt             string                `json:"created_at"`
+}
+
+// StorageContractRepository handles database operations for storage contracts.
+type StorageContractRepository interface {
+	Create(ctx context.Context, dto CreateStorageContractDTO, createdByID uuid.UUID) (uuid.UUID, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdateStorageContractDTO) error
+	MarkReturned(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*StorageContractResponse, error)
+	List(ctx context.Context, filter StorageContractFilter) ([]StorageContractResponse, int64, error)
+	MarkOverdue(ctx context.Context) (int64, error)
+	// ListDueForReminder returns stored contracts ending by the date that were not reminded yet, and overdue
+	// contracts whose customer was not told about it yet.
+	ListDueForReminder(ctx context.Context, endDateBefore string) ([]StorageContractResponse, error)
+	// MarkReminderSent records the pre-end reminder, or the overdue one when overdue is set.
+	MarkReminderSent(ctx context.Context, id uuid.UUID, overdue bool) error
+}
+
+// StorageContractService contains the business logic, labels and reminders for stored tires.
+type StorageContractService interface {
+	CreateContract(ctx context.Context, dto CreateStorageContractDTO, userID uuid.UUID) (uuid.UUID, error)
+	UpdateContract(ctx context.Context, id uuid.UUID, dto UpdateStorageContractDTO) error
+	ReturnContract(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) error
+	DeleteContract(ctx context.Context, id uuid.UUID) error
+	GetContract(ctx context.Context, id uuid.UUID) (*StorageContractResponse, error)
+	ListContracts(ctx context.Context, filter StorageContractFilter) ([]StorageContractResponse, int64, error)
+	GenerateContractQR(ctx context.Context, id uuid.UUID) ([]byte, error)
+	Start(ctx context.Context)
+}
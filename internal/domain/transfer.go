@@ -74,4 +74,5 @@ type TransferService interface {
 	CancelTransfer(ctx context.Context, transferID uuid.UUID, userID uuid.UUID) error
 	ListTransfers(ctx context.Context, filter TransferFilter) ([]TransferResponse, int64, error)
 	GetTransfer(ctx context.Context, id uuid.UUID) (*TransferResponse, error)
+	GenerateTransferQR(ctx context.Context, id uuid.UUID) ([]byte, error)
 }
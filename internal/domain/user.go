@@ -12,6 +12,8 @@ const (
 	RoleAdmin UserRole = "ADMIN"
 	RoleStaff UserRole = "STAFF"
 	RoleBuyer UserRole = "BUYER"
+	// RoleSystem is never assigned to users. It marks transitions made by integrations (payment callbacks).
+	RoleSystem UserRole = "SYSTEM"
 )
 
 // User represents an authenticated entity in the system.
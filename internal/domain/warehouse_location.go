@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// WarehouseLocationType defines a level of the storage hierarchy inside a warehouse.
+type WarehouseLocationType string
+
+const (
+	WarehouseLocationZone  WarehouseLocationType = "ZONE"
+	WarehouseLocationRack  WarehouseLocationType = "RACK"
+	WarehouseLocationShelf WarehouseLocationType = "SHELF"
+	WarehouseLocationBin   WarehouseLocationType = "BIN"
+)
+
+// Depth returns the position of the type in the ZONE > RACK > SHELF > BIN hierarchy.
+func (t WarehouseLocationType) Depth() int {
+	switch t {
+	case WarehouseLocationZone:
+		return 0
+	case WarehouseLocationRack:
+		return 1
+	case WarehouseLocationShelf:
+		return 2
+	case WarehouseLocationBin:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// WarehouseLocation is a zone, rack, shelf or bin inside a warehouse.
+// Path is the chain of codes from the top level, e.g. "A/A-01/A-01-3".
+type WarehouseLocation struct {
+	ID          uuid.UUID             `json:"id"`
+	WarehouseID uuid.UUID             `json:"warehouse_id"`
+	ParentID    *uuid.UUID            `json:"parent_id,omitempty"`
+	Type        WarehouseLocationType `json:"type"`
+	Code        string                `json:"code"`
+	Name        string                `json:"name,omitempty"`
+	Path        string                `json:"path"`
+}
+
+// CreateWarehouseLocationDTO is the payload to add a location to a warehouse.
+// Codes are unique within a warehouse so a scanned label always points to one place.
+type CreateWarehouseLocationDTO struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Type     string     `json:"type" binding:"required,oneof=ZONE RACK SHELF BIN"`
+	Code     string     `json:"code" binding:"required,max=30,excludes=/"`
+	Name     string     `json:"name" binding:"max=100"`
+}
+
+// UpdateWarehouseLocationDTO contains fields that can be changed on a location.
+type UpdateWarehouseLocationDTO struct {
+	Code *string `json:"code" binding:"omitempty,min=1,max=30,excludes=/"`
+	Name *string `json:"name" binding:"omitempty,max=100"`
+}
+
+// MoveLotDTO is the payload to put a lot into another location. A nil location unassigns the lot.
+type MoveLotDTO struct {
+	LocationID *uuid.UUID `json:"location_id"`
+	Comment    string     `json:"comment"`
+}
+
+// LotLocationMoveResponse is a single entry in the lot placement history.
+type LotLocationMoveResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	LotID        uuid.UUID  `json:"lot_id"`
+	FromLocation *string    `json:"from_location,omitempty"` // Location path at the time of the move
+	ToLocation   *string    `json:"to_location,omitempty"`
+	FromID       *uuid.UUID `json:"from_location_id,omitempty"`
+	ToID         *uuid.UUID `json:"to_location_id,omitempty"`
+	MovedBy      uuid.UUID  `json:"moved_by"`
+	Comment      string     `json:"comment,omitempty"`
+	CreatedAt    string     `json:"created_at"`
+}
+
+// WarehouseLocationRepository handles database operations for warehouse locations and lot placement.
+type WarehouseLocationRepository interface {
+	Create(ctx context.Context, warehouseID uuid.UUID, dto CreateWarehouseLocationDTO) (uuid.UUID, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdateWarehouseLocationDTO) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*WarehouseLocation, error)
+	ListByWarehouse(ctx context.Context, warehouseID uuid.UUID) ([]WarehouseLocation, error)
+	MoveLot(ctx context.Context, lotID uuid.UUID, dto MoveLotDTO, userID uuid.UUID) error
+	ListLotMoves(ctx context.Context, lotID uuid.UUID) ([]LotLocationMoveResponse, error)
+}
+
+// WarehouseLocationService contains the business logic for the warehouse storage hierarchy.
+type WarehouseLocationService interface {
+	CreateLocation(ctx context.Context, warehouseID uuid.UUID, dto CreateWarehouseLocationDTO) (uuid.UUID, error)
+	UpdateLocation(ctx context.Context, id uuid.UUID, dto UpdateWarehouseLocationDTO) error
+	DeleteLocation(ctx context.Context, id uuid.UUID) error
+	ListLocations(ctx context.Context, warehouseID uuid.UUID) ([]WarehouseLocation, error)
+	GenerateLocationQR(ctx context.Context, id uuid.UUID) ([]byte, error)
+	MoveLot(ctx context.Context, lotID uuid.UUID, dto MoveLotDTO, userID uuid.UUID) error
+	ListLotMoves(ctx context.Context, lotID uuid.UUID) ([]LotLocationMoveResponse, error)
+}
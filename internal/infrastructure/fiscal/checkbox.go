@@ -0,0 +1,310 @@
+package fiscal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// CheckboxName is stored on receipts fiscalized through the Checkbox adapter.
+const CheckboxName = "CHECKBOX"
+
+const checkboxClientName = "tires-shop"
+
+// errCheckboxNotFound is returned by do for 404 responses.
+var errCheckboxNotFound = errors.New("checkbox resource not found")
+
+// Credentials of the cashier and the cash register in the Checkbox cabinet.
+type Credentials struct {
+	LicenseKey string // Cash register key
+	Login      string // Cashier login
+	Password   string
+}
+
+type checkbox struct {
+	baseURL     string
+	credentials Credentials
+	http        *http.Client
+	logger      *slog.Logger
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewCheckbox creates the Checkbox PRRO adapter. The base URL is configurable so a local stub can stand
+// in for the real API in tests.
+func NewCheckbox(baseURL string, credentials Credentials, logger *slog.Logger) domain.FiscalProvider {
+	return &checkbox{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		credentials: credentials,
+		http:        &http.Client{Timeout: 20 * time.Second},
+		logger:      logger,
+	}
+}
+
+func (p *checkbox) Name() string {
+	return CheckboxName
+}
+
+type checkboxShift struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	OpenedAt string `json:"opened_at"`
+	ClosedAt string `json:"closed_at"`
+}
+
+func (s checkboxShift) toDomain() *domain.FiscalShift {
+	return &domain.FiscalShift{
+		ID:       s.ID,
+		Status:   s.Status,
+		Opened:   s.Status == "OPENED" || s.Status == "OPENING" || s.Status == "CREATED",
+		OpenedAt: s.OpenedAt,
+		ClosedAt: s.ClosedAt,
+	}
+}
+
+func (p *checkbox) CurrentShift(ctx context.Context) (*domain.FiscalShift, error) {
+	var shift *checkboxShift
+	if err := p.do(ctx, http.MethodGet, "/cashier/shift", nil, &shift); err != nil {
+		return nil, err
+	}
+	if shift == nil || shift.ID == "" || shift.Status == "CLOSED" {
+		return nil, nil
+	}
+	return shift.toDomain(), nil
+}
+
+func (p *checkbox) OpenShift(ctx context.Context) (*domain.FiscalShift, error) {
+	var shift checkboxShift
+	if err := p.do(ctx, http.MethodPost, "/shifts", nil, &shift); err != nil {
+		return nil, err
+	}
+	p.logger.Info("checkbox shift opened", slog.String("shift_id", shift.ID), slog.String("status", shift.Status))
+	return shift.toDomain(), nil
+}
+
+func (p *checkbox) CloseShift(ctx context.Context) (*domain.FiscalShift, error) {
+	var shift checkboxShift
+	if err := p.do(ctx, http.MethodPost, "/shifts/close", nil, &shift); err != nil {
+		return nil, err
+	}
+	p.logger.Info("checkbox shift closed", slog.String("shift_id", shift.ID), slog.String("status", shift.Status))
+	return shift.toDomain(), nil
+}
+
+type checkboxReceipt struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"` // CREATED, PENDING, DONE or ERROR
+	FiscalCode string `json:"fiscal_code"`
+}
+
+func (r checkboxReceipt) toDomain() *domain.FiscalReceiptResult {
+	result := &domain.FiscalReceiptResult{
+		ProviderRef: r.ID,
+		Status:      domain.FiscalReceiptProcessing,
+		FiscalCode:  r.FiscalCode,
+	}
+	switch {
+	case r.Status == "DONE" && r.FiscalCode != "":
+		result.Status = domain.FiscalReceiptDone
+	case r.Status == "ERROR":
+		result.Status = domain.FiscalReceiptFailed
+		result.Error = "checkbox rejected the receipt"
+	}
+	return result
+}
+
+// IssueReceipt sends a sale receipt, or a return receipt with every line marked as returned.
+// Amounts go in kopecks and quantities in thousandths, as the API expects.
+func (p *checkbox) IssueReceipt(ctx context.Context, req domain.FiscalReceiptRequest) (*domain.FiscalReceiptResult, error) {
+	isReturn := req.Type == domain.FiscalReceiptReturn
+
+	goods := make([]map[string]interface{}, 0, len(req.Goods))
+	for _, good := range req.Goods {
+		line := map[string]interface{}{
+			"good": map[string]interface{}{
+				"code":  good.Code,
+				"name":  good.Name,
+				"price": toKopecks(good.Price),
+			},
+			"quantity":  good.Quantity * 1000,
+			"is_return": isReturn,
+		}
+		if good.Discount > 0 {
+			line["discounts"] = []map[string]interface{}{{
+				"type":  "DISCOUNT",
+				"mode":  "VALUE",
+				"value": toKopecks(good.Discount),
+			}}
+		}
+		goods = append(goods, line)
+	}
+
+	payments := make([]map[string]interface{}, 0, len(req.Payments))
+	for _, payment := range req.Payments {
+		payments = append(payments, map[string]interface{}{
+			"type":  string(payment.Type),
+			"value": toKopecks(payment.Amount),
+		})
+	}
+
+	body := map[string]interface{}{
+		"id":       req.ID.String(),
+		"goods":    goods,
+		"payments": payments,
+	}
+
+	var receipt checkboxReceipt
+	if err := p.do(ctx, http.MethodPost, "/receipts/sell", body, &receipt); err != nil {
+		return nil, err
+	}
+
+	p.logger.Info("checkbox receipt created", slog.String("receipt_id", receipt.ID), slog.String("status", receipt.Status))
+
+	return receipt.toDomain(), nil
+}
+
+func (p *checkbox) GetReceipt(ctx context.Context, id uuid.UUID) (*domain.FiscalReceiptResult, error) {
+	var receipt checkboxReceipt
+	if err := p.do(ctx, http.MethodGet, "/receipts/"+id.String(), nil, &receipt); err != nil {
+		if errors.Is(err, errCheckboxNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return receipt.toDomain(), nil
+}
+
+// do calls the API as the cashier, signing in again once when the token has expired.
+func (p *checkbox) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	if p.credentials.LicenseKey == "" || p.credentials.Login == "" {
+		return domain.ErrFiscalNotConfigured
+	}
+
+	token, err := p.accessToken(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	status, payload, err := p.send(ctx, method, path, body, token)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusUnauthorized {
+		if token, err = p.accessToken(ctx, true); err != nil {
+			return err
+		}
+		if status, payload, err = p.send(ctx, method, path, body, token); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		return errCheckboxNotFound
+	case status < 200 || status >= 300:
+		return fmt.Errorf("%w: checkbox %s %s returned http %d: %s", domain.ErrFiscalProvider, method, path, status, checkboxErrorMessage(payload))
+	}
+
+	if out == nil || len(payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("%w: failed to decode checkbox response: %v", domain.ErrFiscalProvider, err)
+	}
+	return nil
+}
+
+func (p *checkbox) send(ctx context.Context, method, path string, body interface{}, token string) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to encode checkbox request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build checkbox request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-Name", checkboxClientName)
+	req.Header.Set("X-License-Key", p.credentials.LicenseKey)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: checkbox %s %s request failed: %v", domain.ErrFiscalProvider, method, path, err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: failed to read checkbox response: %v", domain.ErrFiscalProvider, err)
+	}
+	return resp.StatusCode, payload, nil
+}
+
+// accessToken signs the cashier in, reusing the token until the API rejects it.
+func (p *checkbox) accessToken(ctx context.Context, refresh bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && !refresh {
+		return p.token, nil
+	}
+
+	status, payload, err := p.send(ctx, http.MethodPost, "/cashier/signin", map[string]string{
+		"login":    p.credentials.Login,
+		"password": p.credentials.Password,
+	}, "")
+	if err != nil {
+		return "", err
+	}
+	if status < 200 || status >= 300 {
+		return "", fmt.Errorf("%w: checkbox sign in returned http %d: %s", domain.ErrFiscalProvider, status, checkboxErrorMessage(payload))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("%w: checkbox sign in did not return a token", domain.ErrFiscalProvider)
+	}
+
+	p.token = result.AccessToken
+	return p.token, nil
+}
+
+func checkboxErrorMessage(payload []byte) string {
+	var result struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(payload, &result); err == nil && result.Message != "" {
+		return result.Message
+	}
+	if len(payload) > 200 {
+		payload = payload[:200]
+	}
+	return string(payload)
+}
+
+func toKopecks(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
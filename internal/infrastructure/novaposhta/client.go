@@ -0,0 +1,349 @@
+package novaposhta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// ProviderName is stored on shipments created through this adapter.
+const ProviderName = "NOVA_POSHTA"
+
+// trackBatchSize is the API limit of documents per getStatusDocuments call.
+const trackBatchSize = 100
+
+// Sender holds the counterparty refs of the shop in the Nova Poshta business cabinet.
+type Sender struct {
+	Ref        string // Counterparty
+	ContactRef string // Contact person of the counterparty
+	Phone      string
+	CityRef    string
+	AddressRef string // Branch the parcels are handed over at
+}
+
+type client struct {
+	baseURL string
+	apiKey  string
+	sender  Sender
+	http    *http.Client
+	logger  *slog.Logger
+}
+
+// NewClient creates the Nova Poshta JSON API adapter. The base URL is configurable so a local fake can
+// stand in for the real API in tests.
+func NewClient(baseURL, apiKey string, sender Sender, logger *slog.Logger) domain.ShippingProvider {
+	return &client{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		sender:  sender,
+		http:    &http.Client{Timeout: 15 * time.Second},
+		logger:  logger,
+	}
+}
+
+func (c *client) Name() string {
+	return ProviderName
+}
+
+type apiRequest struct {
+	APIKey           string      `json:"apiKey"`
+	ModelName        string      `json:"modelName"`
+	CalledMethod     string      `json:"calledMethod"`
+	MethodProperties interface{} `json:"methodProperties"`
+}
+
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Errors  json.RawMessage `json:"errors"`
+}
+
+// call executes one API method and decodes the data array into out.
+func (c *client) call(ctx context.Context, model, method string, props interface{}, out interface{}) error {
+	if c.apiKey == "" {
+		return domain.ErrShippingNotConfigured
+	}
+
+	body, err := json.Marshal(apiRequest{
+		APIKey:           c.apiKey,
+		ModelName:        model,
+		CalledMethod:     method,
+		MethodProperties: props,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode nova poshta request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build nova poshta request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: nova poshta %s.%s request failed: %v", domain.ErrShippingProvider, model, method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: nova poshta %s.%s returned http %d", domain.ErrShippingProvider, model, method, resp.StatusCode)
+	}
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: failed to decode nova poshta response: %v", domain.ErrShippingProvider, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: nova poshta %s.%s failed: %s", domain.ErrShippingProvider, model, method, decodeErrors(result.Errors))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("%w: failed to decode nova poshta %s.%s data: %v", domain.ErrShippingProvider, model, method, err)
+	}
+	return nil
+}
+
+// decodeErrors handles both shapes the API uses for errors: a list, or an object keyed by index.
+func decodeErrors(raw json.RawMessage) string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return strings.Join(list, "; ")
+	}
+	var keyed map[string]string
+	if err := json.Unmarshal(raw, &keyed); err == nil && len(keyed) > 0 {
+		messages := make([]string, 0, len(keyed))
+		for _, message := range keyed {
+			messages = append(messages, message)
+		}
+		return strings.Join(messages, "; ")
+	}
+	return "unknown error"
+}
+
+func (c *client) SearchCities(ctx context.Context, query string) ([]domain.ShippingCity, error) {
+	var rows []struct {
+		Ref             string `json:"Ref"`
+		Description     string `json:"Description"`
+		AreaDescription string `json:"AreaDescription"`
+	}
+	if err := c.call(ctx, "Address", "getCities", map[string]string{
+		"FindByString": query,
+		"Limit":        "20",
+	}, &rows); err != nil {
+		return nil, err
+	}
+
+	cities := make([]domain.ShippingCity, 0, len(rows))
+	for _, row := range rows {
+		cities = append(cities, domain.ShippingCity{Ref: row.Ref, Name: row.Description, Area: row.AreaDescription})
+	}
+	return cities, nil
+}
+
+func (c *client) ListBranches(ctx context.Context, cityRef string, query string) ([]domain.ShippingBranch, error) {
+	var rows []struct {
+		Ref         string `json:"Ref"`
+		Number      string `json:"Number"`
+		Description string `json:"Description"`
+		CityRef     string `json:"CityRef"`
+	}
+	if err := c.call(ctx, "Address", "getWarehouses", map[string]string{
+		"CityRef":      cityRef,
+		"FindByString": query,
+		"Limit":        "50",
+		"Page":         "1",
+	}, &rows); err != nil {
+		return nil, err
+	}
+
+	branches := make([]domain.ShippingBranch, 0, len(rows))
+	for _, row := range rows {
+		branches = append(branches, domain.ShippingBranch{
+			Ref:         row.Ref,
+			Number:      row.Number,
+			Description: row.Description,
+			CityRef:     row.CityRef,
+		})
+	}
+	return branches, nil
+}
+
+// createRecipient registers the buyer as a private person counterparty and returns its ref and contact ref.
+func (c *client) createRecipient(ctx context.Context, req domain.WaybillRequest) (string, string, error) {
+	var rows []struct {
+		Ref           string `json:"Ref"`
+		ContactPerson struct {
+			Data []struct {
+				Ref string `json:"Ref"`
+			} `json:"data"`
+		} `json:"ContactPerson"`
+	}
+	if err := c.call(ctx, "Counterparty", "save", map[string]string{
+		"FirstName":            req.RecipientFirstName,
+		"LastName":             req.RecipientLastName,
+		"MiddleName":           "",
+		"Phone":                req.RecipientPhone,
+		"Email":                "",
+		"CounterpartyType":     "PrivatePerson",
+		"CounterpartyProperty": "Recipient",
+	}, &rows); err != nil {
+		return "", "", err
+	}
+	if len(rows) == 0 || len(rows[0].ContactPerson.Data) == 0 {
+		return "", "", fmt.Errorf("%w: nova poshta did not return the recipient contact", domain.ErrShippingProvider)
+	}
+	return rows[0].Ref, rows[0].ContactPerson.Data[0].Ref, nil
+}
+
+func (c *client) CreateWaybill(ctx context.Context, req domain.WaybillRequest) (*domain.Waybill, error) {
+	if len(req.Parcels) == 0 {
+		return nil, errors.New("waybill needs at least one parcel")
+	}
+
+	recipientRef, contactRef, err := c.createRecipient(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalWeight float64
+	seats := make([]map[string]string, 0, len(req.Parcels))
+	for _, parcel := range req.Parcels {
+		totalWeight += parcel.Weight
+		seats = append(seats, map[string]string{
+			"volumetricVolume": formatNumber(math.Round(parcel.Length*parcel.Width*parcel.Height/100) / 10000),
+			"volumetricLength": formatNumber(parcel.Length),
+			"volumetricWidth":  formatNumber(parcel.Width),
+			"volumetricHeight": formatNumber(parcel.Height),
+			"weight":           formatNumber(parcel.Weight),
+		})
+	}
+
+	payer := "Recipient"
+	if req.Payer == domain.ShippingPayerSender {
+		payer = "Sender"
+	}
+
+	props := map[string]interface{}{
+		"PayerType":        payer,
+		"PaymentMethod":    "Cash",
+		"DateTime":         time.Now().Format("02.01.2006"),
+		"CargoType":        "Cargo",
+		"ServiceType":      "WarehouseWarehouse",
+		"Weight":           formatNumber(totalWeight),
+		"SeatsAmount":      strconv.Itoa(len(req.Parcels)),
+		"OptionsSeat":      seats,
+		"Description":      req.Description,
+		"Cost":             formatNumber(req.DeclaredValue),
+		"CitySender":       c.sender.CityRef,
+		"Sender":           c.sender.Ref,
+		"SenderAddress":    c.sender.AddressRef,
+		"ContactSender":    c.sender.ContactRef,
+		"SendersPhone":     c.sender.Phone,
+		"CityRecipient":    req.CityRef,
+		"Recipient":        recipientRef,
+		"RecipientAddress": req.BranchRef,
+		"ContactRecipient": contactRef,
+		"RecipientsPhone":  req.RecipientPhone,
+	}
+	if req.CashOnDelivery > 0 {
+		props["BackwardDeliveryData"] = []map[string]string{{
+			"PayerType":        "Recipient",
+			"CargoType":        "Money",
+			"RedeliveryString": formatNumber(req.CashOnDelivery),
+		}}
+	}
+
+	var rows []struct {
+		Ref                   string  `json:"Ref"`
+		IntDocNumber          string  `json:"IntDocNumber"`
+		CostOnSite            float64 `json:"CostOnSite"`
+		EstimatedDeliveryDate string  `json:"EstimatedDeliveryDate"`
+	}
+	if err := c.call(ctx, "InternetDocument", "save", props, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || rows[0].IntDocNumber == "" {
+		return nil, fmt.Errorf("%w: nova poshta did not return a waybill number", domain.ErrShippingProvider)
+	}
+
+	c.logger.Info("nova poshta waybill created", slog.String("tracking_number", rows[0].IntDocNumber))
+
+	return &domain.Waybill{
+		Ref:               rows[0].Ref,
+		TrackingNumber:    rows[0].IntDocNumber,
+		Cost:              rows[0].CostOnSite,
+		EstimatedDelivery: rows[0].EstimatedDeliveryDate,
+	}, nil
+}
+
+func (c *client) Track(ctx context.Context, queries []domain.TrackingQuery) ([]domain.TrackingStatus, error) {
+	statuses := make([]domain.TrackingStatus, 0, len(queries))
+
+	for start := 0; start < len(queries); start += trackBatchSize {
+		end := min(start+trackBatchSize, len(queries))
+
+		documents := make([]map[string]string, 0, end-start)
+		for _, query := range queries[start:end] {
+			documents = append(documents, map[string]string{"DocumentNumber": query.TrackingNumber, "Phone": query.Phone})
+		}
+
+		var rows []struct {
+			Number     string `json:"Number"`
+			StatusCode string `json:"StatusCode"`
+			Status     string `json:"Status"`
+		}
+		if err := c.call(ctx, "TrackingDocument", "getStatusDocuments", map[string]interface{}{
+			"Documents": documents,
+		}, &rows); err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			statuses = append(statuses, domain.TrackingStatus{
+				TrackingNumber: row.Number,
+				StatusCode:     row.StatusCode,
+				Status:         row.Status,
+				State:          mapStatusCode(row.StatusCode),
+			})
+		}
+	}
+
+	return statuses, nil
+}
+
+// mapStatusCode folds Nova Poshta tracking codes into shipment states. Unknown codes are treated as
+// still moving, so tracking continues.
+func mapStatusCode(code string) domain.ShipmentState {
+	switch code {
+	case "1", "3":
+		return domain.ShipmentStateCreated
+	case "2":
+		return domain.ShipmentStateCancelled
+	case "7", "8":
+		return domain.ShipmentStateArrived
+	case "9", "10", "11":
+		return domain.ShipmentStateDelivered
+	case "102", "103", "105", "108":
+		return domain.ShipmentStateReturned
+	default:
+		return domain.ShipmentStateInTransit
+	}
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,143 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// LiqPayName is stored on payments created through the LiqPay adapter.
+const LiqPayName = "LIQPAY"
+
+type liqPay struct {
+	checkoutURL string
+	publicKey   string
+	privateKey  string
+}
+
+// NewLiqPay creates the LiqPay checkout adapter. Links point to checkoutURL, which can be a local stub in tests.
+func NewLiqPay(checkoutURL, publicKey, privateKey string) domain.PaymentProvider {
+	return &liqPay{
+		checkoutURL: checkoutURL,
+		publicKey:   publicKey,
+		privateKey:  privateKey,
+	}
+}
+
+func (p *liqPay) Name() string {
+	return LiqPayName
+}
+
+// CreateInvoice builds a signed checkout link. LiqPay needs no API call for that, the payment is
+// created when the buyer opens the link.
+func (p *liqPay) CreateInvoice(_ context.Context, req domain.InvoiceRequest) (*domain.Invoice, error) {
+	params := map[string]interface{}{
+		"version":     3,
+		"public_key":  p.publicKey,
+		"action":      "pay",
+		"amount":      strconv.FormatFloat(req.Amount, 'f', 2, 64),
+		"currency":    req.Currency,
+		"description": req.Description,
+		"order_id":    req.PaymentID.String(),
+		"server_url":  req.CallbackURL,
+	}
+	if req.ResultURL != "" {
+		params["result_url"] = req.ResultURL
+	}
+	if !req.ExpiresAt.IsZero() {
+		params["expired_date"] = req.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode liqpay params: %w", err)
+	}
+	data := base64.StdEncoding.EncodeToString(raw)
+
+	query := url.Values{}
+	query.Set("data", data)
+	query.Set("signature", p.sign(data))
+
+	return &domain.Invoice{URL: p.checkoutURL + "?" + query.Encode()}, nil
+}
+
+// ParseCallback verifies a server_url notification: a form with base64 data and its signature.
+func (p *liqPay) CancelInvoice(_ context.Context, _ string) error {
+	return domain.ErrPaymentCancelNotSupported
+}
+
+func (p *liqPay) ParseCallback(_ context.Context, callback domain.PaymentCallback) (*domain.PaymentEvent, error) {
+	form, err := url.ParseQuery(string(callback.Body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid liqpay callback body: %w", err)
+	}
+	data := form.Get("data")
+	signature := form.Get("signature")
+	if data == "" || !hmac.Equal([]byte(signature), []byte(p.sign(data))) {
+		return nil, domain.ErrPaymentSignature
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid liqpay callback data: %w", err)
+	}
+	var payload struct {
+		Status         string      `json:"status"`
+		OrderID        string      `json:"order_id"`
+		PaymentID      json.Number `json:"payment_id"`
+		Amount         float64     `json:"amount"`
+		ErrCode        string      `json:"err_code"`
+		ErrDescription string      `json:"err_description"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("invalid liqpay callback data: %w", err)
+	}
+
+	paymentID, err := uuid.Parse(payload.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("liqpay callback has unknown order_id %q", payload.OrderID)
+	}
+
+	status := mapLiqPayStatus(payload.Status)
+	event := &domain.PaymentEvent{
+		PaymentID:   paymentID,
+		ProviderRef: payload.PaymentID.String(),
+		Status:      status,
+		Amount:      payload.Amount,
+		Raw:         raw,
+	}
+	if status == domain.PaymentStatusFailed {
+		event.FailureReason = strings.TrimSpace(payload.ErrCode + " " + payload.ErrDescription)
+	}
+	return event, nil
+}
+
+// sign is base64(sha1(private_key + data + private_key)) as LiqPay defines it.
+func (p *liqPay) sign(data string) string {
+	sum := sha1.Sum([]byte(p.privateKey + data + p.privateKey))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// mapLiqPayStatus folds LiqPay statuses into payment statuses. "sandbox" is a successful test payment,
+// intermediate statuses (3DS, hold, manual review) keep the payment pending.
+func mapLiqPayStatus(status string) domain.PaymentStatus {
+	switch status {
+	case "success", "sandbox":
+		return domain.PaymentStatusSuccess
+	case "failure", "error":
+		return domain.PaymentStatusFailed
+	case "reversed":
+		return domain.PaymentStatusReversed
+	default:
+		return domain.PaymentStatusPending
+	}
+}
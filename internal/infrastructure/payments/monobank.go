@@ -0,0 +1,257 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// MonobankName is stored on payments created through the Monobank acquiring adapter.
+const MonobankName = "MONOBANK"
+
+// monobankCurrencyUAH is the ISO 4217 numeric code Monobank expects.
+const monobankCurrencyUAH = 980
+
+type monobank struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	pubKey *ecdsa.PublicKey
+}
+
+// NewMonobank creates the Monobank acquiring adapter. The base URL is configurable so a local stub
+// can stand in for the real API in tests.
+func NewMonobank(baseURL, token string, logger *slog.Logger) domain.PaymentProvider {
+	return &monobank{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+		logger:  logger,
+	}
+}
+
+func (p *monobank) Name() string {
+	return MonobankName
+}
+
+func (p *monobank) CreateInvoice(ctx context.Context, req domain.InvoiceRequest) (*domain.Invoice, error) {
+	body := map[string]interface{}{
+		"amount": int64(math.Round(req.Amount * 100)),
+		"ccy":    monobankCurrencyUAH,
+		"merchantPaymInfo": map[string]string{
+			"reference":   req.PaymentID.String(),
+			"destination": req.Description,
+		},
+		"webHookUrl": req.CallbackURL,
+	}
+	if req.ResultURL != "" {
+		body["redirectUrl"] = req.ResultURL
+	}
+	if !req.ExpiresAt.IsZero() {
+		body["validity"] = int64(time.Until(req.ExpiresAt).Seconds())
+	}
+
+	var result struct {
+		InvoiceID string `json:"invoiceId"`
+		PageURL   string `json:"pageUrl"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/api/merchant/invoice/create", body, &result); err != nil {
+		return nil, err
+	}
+	if result.InvoiceID == "" || result.PageURL == "" {
+		return nil, fmt.Errorf("%w: monobank did not return an invoice", domain.ErrPaymentProvider)
+	}
+
+	p.logger.Info("monobank invoice created", slog.String("invoice_id", result.InvoiceID))
+
+	return &domain.Invoice{ProviderRef: result.InvoiceID, URL: result.PageURL}, nil
+}
+
+// CancelInvoice removes an unpaid invoice, so its page stops accepting payments. Paid invoices are refunded
+// by staff, not here.
+func (p *monobank) CancelInvoice(ctx context.Context, providerRef string) error {
+	if err := p.do(ctx, http.MethodPost, "/api/merchant/invoice/remove", map[string]string{"invoiceId": providerRef}, nil); err != nil {
+		return err
+	}
+
+	p.logger.Info("monobank invoice removed", slog.String("invoice_id", providerRef))
+	return nil
+}
+
+// ParseCallback verifies the X-Sign header, an ECDSA signature of the raw body made with the merchant
+// webhook key, and decodes the invoice status.
+func (p *monobank) ParseCallback(ctx context.Context, callback domain.PaymentCallback) (*domain.PaymentEvent, error) {
+	signature, err := base64.StdEncoding.DecodeString(callback.Header.Get("X-Sign"))
+	if err != nil || len(signature) == 0 {
+		return nil, domain.ErrPaymentSignature
+	}
+
+	if err := p.verify(ctx, callback.Body, signature); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		InvoiceID     string `json:"invoiceId"`
+		Status        string `json:"status"`
+		FailureReason string `json:"failureReason"`
+		Amount        int64  `json:"amount"`
+		FinalAmount   int64  `json:"finalAmount"`
+		Reference     string `json:"reference"`
+	}
+	if err := json.Unmarshal(callback.Body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid monobank callback body: %w", err)
+	}
+
+	paymentID, err := uuid.Parse(payload.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("monobank callback has unknown reference %q", payload.Reference)
+	}
+
+	amount := payload.FinalAmount
+	if amount == 0 {
+		amount = payload.Amount
+	}
+
+	return &domain.PaymentEvent{
+		PaymentID:     paymentID,
+		ProviderRef:   payload.InvoiceID,
+		Status:        mapMonobankStatus(payload.Status),
+		Amount:        float64(amount) / 100,
+		FailureReason: payload.FailureReason,
+		Raw:           callback.Body,
+	}, nil
+}
+
+// verify checks the signature with the cached webhook key. The key is refetched once on mismatch,
+// because Monobank may rotate it.
+func (p *monobank) verify(ctx context.Context, body, signature []byte) error {
+	hash := sha256.Sum256(body)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		key, err := p.publicKey(ctx, attempt > 0)
+		if err != nil {
+			return err
+		}
+		if ecdsa.VerifyASN1(key, hash[:], signature) {
+			return nil
+		}
+	}
+	return domain.ErrPaymentSignature
+}
+
+func (p *monobank) publicKey(ctx context.Context, refresh bool) (*ecdsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pubKey != nil && !refresh {
+		return p.pubKey, nil
+	}
+
+	var result struct {
+		Key string `json:"key"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/api/merchant/pubkey", nil, &result); err != nil {
+		return nil, err
+	}
+
+	pemBytes, err := base64.StdEncoding.DecodeString(result.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid monobank public key: %v", domain.ErrPaymentProvider, err)
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("%w: monobank public key is not PEM", domain.ErrPaymentProvider)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid monobank public key: %v", domain.ErrPaymentProvider, err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: monobank public key is not ECDSA", domain.ErrPaymentProvider)
+	}
+
+	p.pubKey = key
+	return key, nil
+}
+
+func (p *monobank) do(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	if p.token == "" {
+		return domain.ErrPaymentProviderUnknown
+	}
+
+	var body io.Reader = http.NoBody
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode monobank request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build monobank request: %w", err)
+	}
+	req.Header.Set("X-Token", p.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: monobank %s request failed: %v", domain.ErrPaymentProvider, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			ErrCode string `json:"errCode"`
+			ErrText string `json:"errText"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%w: monobank %s returned http %d: %s %s", domain.ErrPaymentProvider, path, resp.StatusCode, apiErr.ErrCode, apiErr.ErrText)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: failed to decode monobank response: %v", domain.ErrPaymentProvider, err)
+	}
+	return nil
+}
+
+// mapMonobankStatus folds invoice statuses into payment statuses. A hold is not money yet, so it stays pending.
+func mapMonobankStatus(status string) domain.PaymentStatus {
+	switch status {
+	case "success":
+		return domain.PaymentStatusSuccess
+	case "failure":
+		return domain.PaymentStatusFailed
+	case "expired":
+		return domain.PaymentStatusExpired
+	case "reversed":
+		return domain.PaymentStatusReversed
+	default:
+		return domain.PaymentStatusPending
+	}
+}
@@ -0,0 +1,165 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// Telegram limits for invoice texts.
+const (
+	telegramInvoiceTitleLimit       = 32
+	telegramInvoiceDescriptionLimit = 255
+)
+
+type telegramPayments struct {
+	apiURL        string
+	botToken      string
+	providerToken string
+	webhookSecret string
+	http          *http.Client
+	logger        *slog.Logger
+}
+
+// NewTelegram creates the Telegram Payments adapter for the client bot. Telegram does not sign updates,
+// so callbacks are trusted only when they carry the secret token the webhook was registered with.
+func NewTelegram(apiURL, botToken, providerToken, webhookSecret string, logger *slog.Logger) domain.PaymentProvider {
+	return &telegramPayments{
+		apiURL:        apiURL,
+		botToken:      botToken,
+		providerToken: providerToken,
+		webhookSecret: webhookSecret,
+		http:          &http.Client{Timeout: 10 * time.Second},
+		logger:        logger,
+	}
+}
+
+func (p *telegramPayments) Name() string {
+	return domain.PaymentProviderTelegram
+}
+
+// CreateInvoice creates an invoice link. The payment ID travels as the invoice payload and comes back in
+// the pre-checkout query and in successful_payment.
+func (p *telegramPayments) CreateInvoice(ctx context.Context, req domain.InvoiceRequest) (*domain.Invoice, error) {
+	var link string
+	if err := p.call(ctx, "createInvoiceLink", map[string]interface{}{
+		"title":          domain.TruncateRunes(fmt.Sprintf("Замовлення %s", req.OrderID.String()[:8]), telegramInvoiceTitleLimit),
+		"description":    domain.TruncateRunes(req.Description, telegramInvoiceDescriptionLimit),
+		"payload":        req.PaymentID.String(),
+		"provider_token": p.providerToken,
+		"currency":       req.Currency,
+		"prices": []map[string]interface{}{{
+			"label":  domain.TruncateRunes(req.Description, telegramInvoiceTitleLimit),
+			"amount": int64(math.Round(req.Amount * 100)),
+		}},
+	}, &link); err != nil {
+		return nil, err
+	}
+	return &domain.Invoice{URL: link}, nil
+}
+
+// ParseCallback decodes the successful_payment object of a client bot update.
+func (p *telegramPayments) ParseCallback(_ context.Context, callback domain.PaymentCallback) (*domain.PaymentEvent, error) {
+	token := callback.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if p.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.webhookSecret)) != 1 {
+		return nil, domain.ErrPaymentSignature
+	}
+
+	var payload struct {
+		Currency                string `json:"currency"`
+		TotalAmount             int64  `json:"total_amount"`
+		InvoicePayload          string `json:"invoice_payload"`
+		TelegramPaymentChargeID string `json:"telegram_payment_charge_id"`
+	}
+	if err := json.Unmarshal(callback.Body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid telegram payment body: %w", err)
+	}
+
+	paymentID, err := uuid.Parse(payload.InvoicePayload)
+	if err != nil {
+		return nil, fmt.Errorf("telegram payment has unknown payload %q", payload.InvoicePayload)
+	}
+
+	return &domain.PaymentEvent{
+		PaymentID:   paymentID,
+		ProviderRef: payload.TelegramPaymentChargeID,
+		Status:      domain.PaymentStatusSuccess,
+		Amount:      float64(payload.TotalAmount) / 100,
+		Raw:         callback.Body,
+	}, nil
+}
+
+// CancelInvoice has nothing to call: Telegram invoices cannot be revoked, but the pre-checkout answer
+// already refuses payments that are no longer pending.
+func (p *telegramPayments) CancelInvoice(_ context.Context, _ string) error {
+	return nil
+}
+
+// AnswerCheckout answers a pre-checkout query. Telegram cancels the payment when no answer comes within
+// 10 seconds, an empty error message approves it.
+func (p *telegramPayments) AnswerCheckout(ctx context.Context, queryID string, errorMessage string) error {
+	params := map[string]interface{}{
+		"pre_checkout_query_id": queryID,
+		"ok":                    errorMessage == "",
+	}
+	if errorMessage != "" {
+		params["error_message"] = errorMessage
+	}
+	return p.call(ctx, "answerPreCheckoutQuery", params, nil)
+}
+
+func (p *telegramPayments) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	if p.botToken == "" || p.providerToken == "" {
+		return domain.ErrPaymentProviderUnknown
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode telegram request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", p.apiURL, p.botToken, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: telegram %s request failed: %v", domain.ErrPaymentProvider, method, err)
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("%w: failed to decode telegram response: %v", domain.ErrPaymentProvider, err)
+	}
+	if !payload.OK {
+		if payload.Description == "" {
+			payload.Description = "telegram api returned not ok"
+		}
+		return fmt.Errorf("%w: telegram %s: %s", domain.ErrPaymentProvider, method, payload.Description)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload.Result, out); err != nil {
+		return fmt.Errorf("%w: failed to decode telegram %s result: %v", domain.ErrPaymentProvider, method, err)
+	}
+	return nil
+}
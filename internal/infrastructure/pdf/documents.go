@@ -0,0 +1,290 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// CompanyDetails are the seller requisites printed on order documents.
+type CompanyDetails struct {
+	Name           string
+	TaxID          string // ЄДРПОУ or ІПН of the sole proprietor
+	Address        string
+	Phone          string
+	IBAN           string
+	Bank           string
+	WarrantyMonths int
+	WarrantyTerms  string // Printed on the warranty card under the items
+}
+
+// DocumentRenderer produces A4 order documents.
+type DocumentRenderer interface {
+	RenderOrderDocument(docType domain.OrderDocumentType, number string, issuedAt time.Time, order domain.OrderResponse) ([]byte, error)
+}
+
+type documentRenderer struct {
+	company   CompanyDetails
+	fontBytes []byte
+}
+
+// NewDocumentRenderer creates a renderer for invoices, receipts and warranty cards.
+// fontPath optionally points to a UTF-8 TTF font; without it Cyrillic text is transliterated to Latin.
+func NewDocumentRenderer(fontPath string, company CompanyDetails) (DocumentRenderer, error) {
+	r := &documentRenderer{company: company}
+
+	if fontPath != "" {
+		fontBytes, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document font: %w", err)
+		}
+		r.fontBytes = fontBytes
+	}
+
+	return r, nil
+}
+
+var documentTitles = map[domain.OrderDocumentType]string{
+	domain.OrderDocumentInvoice:  "Рахунок на оплату",
+	domain.OrderDocumentReceipt:  "Товарний чек",
+	domain.OrderDocumentWarranty: "Гарантійний талон",
+}
+
+// Page geometry in millimeters.
+const (
+	documentMargin = 15.0
+	documentWidth  = 210 - 2*documentMargin
+)
+
+// Item table columns: number, goods, quantity, price, discount, sum.
+var documentColumns = []float64{8, 82, 16, 26, 22, 26}
+
+func (r *documentRenderer) RenderOrderDocument(docType domain.OrderDocumentType, number string, issuedAt time.Time, order domain.OrderResponse) ([]byte, error) {
+	title, ok := documentTitles[docType]
+	if !ok {
+		return nil, fmt.Errorf("unknown document type: %s", docType)
+	}
+
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(documentMargin, documentMargin, documentMargin)
+	doc.SetAutoPageBreak(true, documentMargin)
+	doc.AddPage()
+
+	w := &documentWriter{labelWriter: newLabelWriter(doc, r.fontBytes)}
+
+	// Seller
+	w.text("B", 13, r.company.Name)
+	for _, line := range []string{
+		prefixed("Код ЄДРПОУ / ІПН: ", r.company.TaxID),
+		r.company.Address,
+		prefixed("Тел.: ", r.company.Phone),
+	} {
+		if line != "" {
+			w.text("", 9, line)
+		}
+	}
+	if docType == domain.OrderDocumentInvoice {
+		if r.company.IBAN != "" {
+			w.text("", 9, "IBAN: "+r.company.IBAN)
+		}
+		if r.company.Bank != "" {
+			w.text("", 9, "Банк: "+r.company.Bank)
+		}
+	}
+	doc.Ln(6)
+
+	// Title
+	w.centered("B", 14, fmt.Sprintf("%s № %s від %s", title, number, issuedAt.Format("02.01.2006")))
+	w.centered("", 9, "Замовлення "+order.ID.String())
+	doc.Ln(4)
+
+	// Buyer
+	buyer := order.CustomerName
+	if order.CustomerPhone != "" {
+		buyer += ", " + order.CustomerPhone
+	}
+	w.text("", 10, "Покупець: "+buyer)
+	doc.Ln(2)
+
+	// Items
+	w.tableHeader()
+	var gross float64
+	for i, item := range order.Items {
+		gross += item.Price * float64(item.Quantity)
+		w.tableRow(i+1, item)
+	}
+	doc.Ln(3)
+
+	if docType == domain.OrderDocumentWarranty {
+		r.writeWarranty(w, issuedAt)
+	} else {
+		r.writeTotals(w, docType, gross, order)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render document pdf: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r *documentRenderer) writeTotals(w *documentWriter, docType domain.OrderDocumentType, gross float64, order domain.OrderResponse) {
+	if order.DiscountAmount > 0 {
+		w.total("", "Сума без знижки:", gross)
+		label := "Знижка:"
+		if order.PromoCode != "" {
+			label = fmt.Sprintf("Знижка (%s):", order.PromoCode)
+		}
+		w.total("", label, -order.DiscountAmount)
+	}
+	w.total("B", "Всього:", order.TotalAmount)
+	w.total("", "Сплачено:", order.PaidAmount)
+	if order.Balance > 0 {
+		w.total("B", "До сплати:", order.Balance)
+	}
+	w.doc.Ln(2)
+	w.text("", 10, "Статус оплати: "+paymentStatusText(order))
+
+	w.doc.Ln(12)
+	w.text("", 10, "Продавець ____________________")
+	if docType == domain.OrderDocumentInvoice {
+		w.doc.Ln(2)
+		w.text("", 8, "У призначенні платежу вкажіть номер рахунку.")
+	}
+}
+
+func (r *documentRenderer) writeWarranty(w *documentWriter, issuedAt time.Time) {
+	if r.company.WarrantyMonths > 0 {
+		until := issuedAt.AddDate(0, r.company.WarrantyMonths, 0)
+		w.text("B", 10, fmt.Sprintf("Гарантійний строк: %d міс. з дати продажу, до %s", r.company.WarrantyMonths, until.Format("02.01.2006")))
+	}
+	if r.company.WarrantyTerms != "" {
+		w.doc.Ln(2)
+		w.paragraph(9, r.company.WarrantyTerms)
+	}
+
+	w.doc.Ln(12)
+	w.text("", 10, "Продавець ____________________          М.П.")
+	w.doc.Ln(6)
+	w.text("", 10, "З умовами гарантії ознайомлений. Покупець ____________________")
+}
+
+func paymentStatusText(order domain.OrderResponse) string {
+	switch {
+	case order.TotalAmount > 0 && order.Balance <= 0:
+		return "оплачено"
+	case order.PaidAmount > 0:
+		return "оплачено частково"
+	default:
+		return "не оплачено"
+	}
+}
+
+func prefixed(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + value
+}
+
+// documentWriter lays out flowing text on top of the label font handling.
+type documentWriter struct {
+	*labelWriter
+}
+
+func (w *documentWriter) text(style string, size float64, value string) {
+	w.doc.SetFont(w.family, style, size)
+	w.doc.SetX(documentMargin)
+	w.doc.CellFormat(documentWidth, lineHeight(size), w.translate(value), "", 1, "L", false, 0, "")
+}
+
+func (w *documentWriter) centered(style string, size float64, value string) {
+	w.doc.SetFont(w.family, style, size)
+	w.doc.SetX(documentMargin)
+	w.doc.CellFormat(documentWidth, lineHeight(size), w.translate(value), "", 1, "C", false, 0, "")
+}
+
+func (w *documentWriter) paragraph(size float64, value string) {
+	w.doc.SetFont(w.family, "", size)
+	w.doc.SetX(documentMargin)
+	w.doc.MultiCell(documentWidth, lineHeight(size), w.translate(value), "", "L", false)
+}
+
+func (w *documentWriter) total(style, label string, amount float64) {
+	w.doc.SetFont(w.family, style, 10)
+	labelWidth := documentWidth - documentColumns[len(documentColumns)-1]
+	w.doc.SetX(documentMargin)
+	w.doc.CellFormat(labelWidth, 6, w.translate(label), "", 0, "R", false, 0, "")
+	w.doc.CellFormat(documentColumns[len(documentColumns)-1], 6, w.translate(formatAmount(amount)), "", 1, "R", false, 0, "")
+}
+
+func (w *documentWriter) tableHeader() {
+	w.doc.SetFont(w.family, "B", 9)
+	w.doc.SetFillColor(235, 235, 235)
+	w.doc.SetX(documentMargin)
+	for i, title := range []string{"№", "Товар", "К-сть", "Ціна", "Знижка", "Сума"} {
+		w.doc.CellFormat(documentColumns[i], 7, w.translate(title), "1", 0, "C", true, 0, "")
+	}
+	w.doc.Ln(-1)
+}
+
+func (w *documentWriter) tableRow(index int, item domain.OrderItemResponse) {
+	w.doc.SetFont(w.family, "", 9)
+	w.doc.SetX(documentMargin)
+
+	name := strings.TrimSpace(item.Brand + " " + item.Model)
+	cells := []struct {
+		value string
+		align string
+	}{
+		{strconv.Itoa(index), "C"},
+		{w.fit(name, documentColumns[1]-2), "L"},
+		{strconv.Itoa(item.Quantity), "C"},
+		{formatAmount(item.Price), "R"},
+		{formatAmount(item.Discount), "R"},
+		{formatAmount(item.Total), "R"},
+	}
+	for i, cell := range cells {
+		w.doc.CellFormat(documentColumns[i], 7, cell.value, "1", 0, cell.align, false, 0, "")
+	}
+	w.doc.Ln(-1)
+}
+
+// fit translates the text and truncates it to the width in the current font.
+func (w *documentWriter) fit(text string, width float64) string {
+	value := w.translate(text)
+	if w.doc.GetStringWidth(value) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 && w.doc.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// formatAmount renders money with kopecks and without the currency, e.g. "12 500.00".
+func formatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	intPart, fraction, _ := strings.Cut(strconv.FormatFloat(amount, 'f', 2, 64), ".")
+
+	var grouped strings.Builder
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteByte(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + "." + fraction
+}
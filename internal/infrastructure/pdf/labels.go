@@ -0,0 +1,357 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// DefaultLabelTemplate is used when the caller does not pick a template.
+const DefaultLabelTemplate = "a4-3x8"
+
+// LabelRenderer produces printable PDF label sheets.
+type LabelRenderer interface {
+	Render(templateName string, labels []domain.LotLabel) ([]byte, error)
+	Templates() []domain.LabelTemplateInfo
+}
+
+// LabelTemplate describes the page and label geometry. All sizes are in millimeters, fonts in points.
+// A thermal label is simply a page with a single 1x1 label.
+type LabelTemplate struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	PageWidth     float64 `json:"page_width"`
+	PageHeight    float64 `json:"page_height"`
+	Columns       int     `json:"columns"`
+	Rows          int     `json:"rows"`
+	LabelWidth    float64 `json:"label_width"`
+	LabelHeight   float64 `json:"label_height"`
+	MarginLeft    float64 `json:"margin_left"`
+	MarginTop     float64 `json:"margin_top"`
+	GapX          float64 `json:"gap_x"`
+	GapY          float64 `json:"gap_y"`
+	Padding       float64 `json:"padding"`
+	QRSize        float64 `json:"qr_size"`
+	TitleFontSize float64 `json:"title_font_size"`
+	TextFontSize  float64 `json:"text_font_size"`
+	PriceFontSize float64 `json:"price_font_size"`
+	Border        bool    `json:"border"` // Dashed cut lines around every label
+}
+
+var builtinLabelTemplates = []LabelTemplate{
+	{
+		Name:        "a4-3x8",
+		Description: "A4 sheet, 24 labels 70x37 mm",
+		PageWidth:   210, PageHeight: 297,
+		Columns: 3, Rows: 8,
+		LabelWidth: 70, LabelHeight: 37,
+		MarginTop: 0.5,
+		Padding:   2.5, QRSize: 24,
+		TitleFontSize: 10, TextFontSize: 8, PriceFontSize: 14,
+		Border: true,
+	},
+	{
+		Name:        "a4-2x4",
+		Description: "A4 sheet, 8 large tags 105x74 mm for tire stacks",
+		PageWidth:   210, PageHeight: 297,
+		Columns: 2, Rows: 4,
+		LabelWidth: 105, LabelHeight: 74.25,
+		Padding: 5, QRSize: 40,
+		TitleFontSize: 14, TextFontSize: 11, PriceFontSize: 24,
+		Border: true,
+	},
+	{
+		Name:        "thermal-58x40",
+		Description: "Single thermal label 58x40 mm",
+		PageWidth:   58, PageHeight: 40,
+		Columns: 1, Rows: 1,
+		LabelWidth: 58, LabelHeight: 40,
+		Padding: 2, QRSize: 22,
+		TitleFontSize: 8, TextFontSize: 6.5, PriceFontSize: 12,
+	},
+	{
+		Name:        "thermal-100x50",
+		Description: "Single thermal label 100x50 mm",
+		PageWidth:   100, PageHeight: 50,
+		Columns: 1, Rows: 1,
+		LabelWidth: 100, LabelHeight: 50,
+		Padding: 3, QRSize: 34,
+		TitleFontSize: 11, TextFontSize: 9, PriceFontSize: 18,
+	},
+}
+
+type labelRenderer struct {
+	templates map[string]LabelTemplate
+	fontBytes []byte
+}
+
+// NewLabelRenderer creates a renderer with the built-in templates.
+// templatesPath optionally points to a JSON array of LabelTemplate that adds or overrides templates by name.
+// fontPath optionally points to a UTF-8 TTF font; without it Cyrillic text is transliterated to Latin.
+func NewLabelRenderer(fontPath string, templatesPath string) (LabelRenderer, error) {
+	r := &labelRenderer{templates: make(map[string]LabelTemplate, len(builtinLabelTemplates))}
+	for _, template := range builtinLabelTemplates {
+		r.templates[template.Name] = template
+	}
+
+	if templatesPath != "" {
+		raw, err := os.ReadFile(templatesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read label templates: %w", err)
+		}
+
+		var custom []LabelTemplate
+		if err := json.Unmarshal(raw, &custom); err != nil {
+			return nil, fmt.Errorf("failed to parse label templates: %w", err)
+		}
+
+		for _, template := range custom {
+			if err := template.validate(); err != nil {
+				return nil, err
+			}
+			r.templates[template.Name] = template
+		}
+	}
+
+	if fontPath != "" {
+		fontBytes, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read label font: %w", err)
+		}
+		r.fontBytes = fontBytes
+	}
+
+	return r, nil
+}
+
+func (t LabelTemplate) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("label template name is required")
+	}
+	if t.PageWidth <= 0 || t.PageHeight <= 0 || t.LabelWidth <= 0 || t.LabelHeight <= 0 {
+		return fmt.Errorf("label template %s: page and label sizes must be positive", t.Name)
+	}
+	if t.Columns <= 0 || t.Rows <= 0 {
+		return fmt.Errorf("label template %s: columns and rows must be positive", t.Name)
+	}
+	if t.TitleFontSize <= 0 || t.TextFontSize <= 0 || t.PriceFontSize <= 0 {
+		return fmt.Errorf("label template %s: font sizes must be positive", t.Name)
+	}
+	return nil
+}
+
+func (r *labelRenderer) Templates() []domain.LabelTemplateInfo {
+	infos := make([]domain.LabelTemplateInfo, 0, len(r.templates))
+	for _, template := range r.templates {
+		infos = append(infos, domain.LabelTemplateInfo{
+			Name:        template.Name,
+			Description: template.Description,
+			LabelWidth:  template.LabelWidth,
+			LabelHeight: template.LabelHeight,
+			PerPage:     template.Columns * template.Rows,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Render lays the labels out row by row, starting a new page when the grid is full.
+func (r *labelRenderer) Render(templateName string, labels []domain.LotLabel) ([]byte, error) {
+	if templateName == "" {
+		templateName = DefaultLabelTemplate
+	}
+	template, ok := r.templates[templateName]
+	if !ok {
+		return nil, fmt.Errorf("unknown label template: %s", templateName)
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("nothing to print")
+	}
+
+	doc := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: template.PageWidth, Ht: template.PageHeight},
+	})
+	doc.SetMargins(0, 0, 0)
+	doc.SetAutoPageBreak(false, 0)
+
+	w := newLabelWriter(doc, r.fontBytes)
+
+	perPage := template.Columns * template.Rows
+	for i, label := range labels {
+		slot := i % perPage
+		if slot == 0 {
+			doc.AddPage()
+		}
+
+		x := template.MarginLeft + float64(slot%template.Columns)*(template.LabelWidth+template.GapX)
+		y := template.MarginTop + float64(slot/template.Columns)*(template.LabelHeight+template.GapY)
+		w.drawLabel(template, x, y, label)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render label pdf: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// labelWriter hides the font handling: a UTF-8 font when configured, otherwise a core font with transliteration.
+type labelWriter struct {
+	doc       *fpdf.Fpdf
+	family    string
+	translate func(string) string
+}
+
+func newLabelWriter(doc *fpdf.Fpdf, fontBytes []byte) *labelWriter {
+	if len(fontBytes) > 0 {
+		doc.AddUTF8FontFromBytes("label", "", fontBytes)
+		doc.AddUTF8FontFromBytes("label", "B", fontBytes)
+		return &labelWriter{doc: doc, family: "label", translate: func(s string) string { return s }}
+	}
+
+	cp1252 := doc.UnicodeTranslatorFromDescriptor("")
+	return &labelWriter{
+		doc:       doc,
+		family:    "Helvetica",
+		translate: func(s string) string { return cp1252(Transliterate(s)) },
+	}
+}
+
+func (w *labelWriter) drawLabel(t LabelTemplate, x, y float64, label domain.LotLabel) {
+	if t.Border {
+		w.doc.SetDrawColor(180, 180, 180)
+		w.doc.SetLineWidth(0.1)
+		w.doc.SetDashPattern([]float64{1, 1}, 0)
+		w.doc.Rect(x, y, t.LabelWidth, t.LabelHeight, "D")
+		w.doc.SetDashPattern([]float64{}, 0)
+	}
+
+	innerX := x + t.Padding
+	innerY := y + t.Padding
+	innerW := t.LabelWidth - 2*t.Padding
+	innerH := t.LabelHeight - 2*t.Padding
+
+	textX := innerX
+	if len(label.QRCode) > 0 && t.QRSize > 0 {
+		qrSize := minFloat(t.QRSize, minFloat(innerH, innerW/2))
+		imageName := "qr-" + label.LotID.String()
+		w.doc.RegisterImageOptionsReader(imageName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(label.QRCode))
+		w.doc.ImageOptions(imageName, innerX, innerY+(innerH-qrSize)/2, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		textX = innerX + qrSize + t.Padding
+	}
+	textW := innerX + innerW - textX
+
+	lineY := innerY
+	lineY = w.line(textX, lineY, textW, "B", t.TitleFontSize, label.Brand)
+	if label.Model != "" {
+		lineY = w.line(textX, lineY, textW, "", t.TextFontSize, label.Model)
+	}
+	if label.Size != "" {
+		lineY = w.line(textX, lineY, textW, "B", t.TitleFontSize, label.Size)
+	}
+
+	details := make([]string, 0, 2)
+	for _, value := range []string{label.Season, label.Condition} {
+		if value != "" {
+			details = append(details, value)
+		}
+	}
+	if len(details) > 0 {
+		w.line(textX, lineY, textW, "", t.TextFontSize, strings.Join(details, " / "))
+	}
+
+	// Price is pinned to the bottom so it lines up across the sheet.
+	priceY := innerY + innerH - lineHeight(t.PriceFontSize)
+	w.line(textX, priceY, textW, "B", t.PriceFontSize, FormatPrice(label.Price))
+}
+
+// line prints a single line truncated to the given width and returns the Y of the next line.
+func (w *labelWriter) line(x, y, width float64, style string, size float64, text string) float64 {
+	w.doc.SetFont(w.family, style, size)
+	w.doc.SetTextColor(0, 0, 0)
+
+	value := w.translate(text)
+	if w.doc.GetStringWidth(value) > width {
+		runes := []rune(value)
+		for len(runes) > 0 && w.doc.GetStringWidth(string(runes)+"...") > width {
+			runes = runes[:len(runes)-1]
+		}
+		value = string(runes) + "..."
+	}
+
+	height := lineHeight(size)
+	w.doc.SetXY(x, y)
+	w.doc.CellFormat(width, height, value, "", 0, "L", false, 0, "")
+	return y + height
+}
+
+// lineHeight converts a font size in points to a comfortable line height in millimeters.
+func lineHeight(size float64) float64 {
+	return size * 0.3528 * 1.2
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// FormatPrice renders a price with thousand separators, e.g. "12 500 грн".
+func FormatPrice(price float64) string {
+	whole := strconv.FormatFloat(price, 'f', 0, 64)
+	if price != float64(int64(price)) {
+		whole = strconv.FormatFloat(price, 'f', 2, 64)
+	}
+
+	intPart, fraction, hasFraction := strings.Cut(whole, ".")
+	var grouped strings.Builder
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteByte(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	if hasFraction {
+		grouped.WriteString("." + fraction)
+	}
+
+	return grouped.String() + " грн"
+}
+
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e", 'є': "ie", 'ж': "zh",
+	'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ь': "", 'ю': "iu", 'я': "ia", 'ы': "y", 'э': "e",
+	'ё': "e", 'ъ': "", '№': "No",
+}
+
+// Transliterate converts Ukrainian/Russian Cyrillic to Latin for fonts without Cyrillic glyphs.
+func Transliterate(value string) string {
+	var b strings.Builder
+	for _, char := range value {
+		lower := []rune(strings.ToLower(string(char)))[0]
+		latin, ok := transliteration[lower]
+		if !ok {
+			b.WriteRune(char)
+			continue
+		}
+		if lower != char && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
@@ -2,6 +2,8 @@ package qrcode
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 
 	qr "github.com/skip2/go-qrcode"
 )
@@ -9,12 +11,29 @@ import (
 // Generator defines the contract for creating QR codes.
 type Generator interface {
 	GeneratePNG(data string, size int) ([]byte, error)
+	// GenerateLinkPNG encodes a scan code (e.g. "lot_<id>") as a deep link built by Link.
+	GenerateLinkPNG(code string, size int) ([]byte, error)
+	// Link turns a scan code into the URL printed on labels.
+	Link(code string) string
 }
 
-type qrGenerator struct{}
+// LinkConfig controls what a scanned label opens.
+// URLTemplate wins when set and must contain "{code}", e.g. "https://shop.example.com/scan/{code}".
+// Otherwise BotUsername (and optionally MiniAppName) produce a Telegram Mini App deep link.
+// With nothing configured the bare code is encoded.
+type LinkConfig struct {
+	URLTemplate string
+	BotUsername string
+	MiniAppName string
+}
+
+type qrGenerator struct {
+	links LinkConfig
+}
 
-func NewQRGenerator() Generator {
-	return &qrGenerator{}
+func NewQRGenerator(links LinkConfig) Generator {
+	links.BotUsername = strings.TrimPrefix(strings.TrimSpace(links.BotUsername), "@")
+	return &qrGenerator{links: links}
 }
 
 // GeneratePNG creates a PNG image buffer from the given string.
@@ -26,3 +45,20 @@ func (g *qrGenerator) GeneratePNG(data string, size int) ([]byte, error) {
 	}
 	return pngBytes, nil
 }
+
+func (g *qrGenerator) GenerateLinkPNG(code string, size int) ([]byte, error) {
+	return g.GeneratePNG(g.Link(code), size)
+}
+
+func (g *qrGenerator) Link(code string) string {
+	switch {
+	case g.links.URLTemplate != "":
+		return strings.ReplaceAll(g.links.URLTemplate, "{code}", url.QueryEscape(code))
+	case g.links.BotUsername != "" && g.links.MiniAppName != "":
+		return fmt.Sprintf("https://t.me/%s/%s?startapp=%s", g.links.BotUsername, g.links.MiniAppName, url.QueryEscape(code))
+	case g.links.BotUsername != "":
+		return fmt.Sprintf("https://t.me/%s?startapp=%s", g.links.BotUsername, url.QueryEscape(code))
+	default:
+		return code
+	}
+}
@@ -0,0 +1,198 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table is one sheet of rows under a header. Cells are strings, ints or float64s, numbers stay numeric
+// in XLSX so accountants can sum them. In CSV, text that a spreadsheet would read as a formula is prefixed
+// with an apostrophe, since names and comments come from buyers. XLSX needs no escaping, inline strings
+// are never evaluated.
+type Table struct {
+	Name   string // Sheet name in XLSX, up to 31 characters
+	Header []string
+	Rows   [][]any
+}
+
+// WriteCSV renders the table as comma-separated UTF-8 with a BOM, so Excel opens Cyrillic text correctly.
+func WriteCSV(t Table) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(t.Header))
+	for i, title := range t.Header {
+		header[i] = escapeFormula(title)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = formatText(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteXLSX renders the table as a single-sheet Office Open XML workbook with a frozen, bold header.
+func WriteXLSX(t Table) ([]byte, error) {
+	name := t.Name
+	if name == "" {
+		name = "Sheet1"
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sheet.WriteString(`<sheetData>`)
+	header := make([]any, len(t.Header))
+	for i, title := range t.Header {
+		header[i] = title
+	}
+	writeXLSXRow(&sheet, 1, header, 1)
+	for i, row := range t.Rows {
+		writeXLSXRow(&sheet, i+2, row, 0)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escapeXML(name) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to xlsx: %w", file.name, err)
+		}
+		if _, err := w.Write([]byte(file.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s to xlsx: %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write xlsx: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeXLSXRow writes a row of inline cells. style is the cellXfs index, 1 is bold.
+func writeXLSXRow(buf *bytes.Buffer, index int, cells []any, style int) {
+	fmt.Fprintf(buf, `<row r="%d">`, index)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(index)
+		styleAttr := ""
+		if style > 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+
+		switch v := cell.(type) {
+		case nil:
+			continue
+		case int, int64, float64:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, formatCell(v))
+		default:
+			text := formatCell(v)
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, escapeXML(text))
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// columnName turns a zero-based column index into its letters: 0 is A, 26 is AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatText formats a CSV cell: numbers as they are, anything else escaped by escapeFormula.
+func formatText(cell any) string {
+	switch cell.(type) {
+	case int, int64, float64:
+		return formatCell(cell)
+	default:
+		return escapeFormula(formatCell(cell))
+	}
+}
+
+// escapeFormula keeps Excel and LibreOffice from evaluating text such as "=HYPERLINK(...)" or "@SUM(...)".
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
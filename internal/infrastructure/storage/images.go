@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+const (
+	// Masters are clean copies kept privately, so renditions can be re-rendered with a new watermark.
+	masterWidth  = 2048
+	mastersPath  = "masters/"
+	photosPath   = "lots/"
+	masterSuffix = ".jpg"
+)
+
+// ImageOptions configures how uploaded photos are rendered.
+type ImageOptions struct {
+	Watermark *Watermark // Optional, applied to every public rendition
+}
+
+// renderedFile is one encoded rendition ready to be stored.
+type renderedFile struct {
+	Size        domain.PhotoSize
+	ObjectName  string
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// renderedPhoto is a new upload: the private master and the public renditions.
+type renderedPhoto struct {
+	Master     renderedFile
+	Renditions []renderedFile
+}
+
+// renderPhoto decodes the upload once and prepares the master and every rendition.
+// Objects share a folder per photo: "lots/<id>/thumb.jpg", "lots/<id>/card.jpg", "lots/<id>/full.jpg",
+// and the master is stored as "masters/<id>.jpg".
+//
+// Re-encoding drops all metadata: Go encoders never write EXIF, so GPS tags and camera details
+// from phones never reach storage. The orientation tag is applied to the pixels before that.
+func renderPhoto(file io.Reader, opts ImageOptions) (*renderedPhoto, error) {
+	return renderPhotoInto(file, photosPath+uuid.New().String()+"/", opts)
+}
+
+// renderLegacyPhoto turns a single "lots/<id>.jpg" file into a rendition set in "lots/<id>/", so the photo
+// keeps its ID. Such files predate metadata stripping, re-encoding drops their EXIF.
+func renderLegacyPhoto(file io.Reader, objectName string, opts ImageOptions) (*renderedPhoto, error) {
+	folder := strings.TrimSuffix(objectName, ".jpg") + "/"
+	return renderPhotoInto(file, folder, opts)
+}
+
+func renderPhotoInto(file io.Reader, folder string, opts ImageOptions) (*renderedPhoto, error) {
+	img, err := imaging.Decode(file, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	master := img
+	if img.Bounds().Dx() > masterWidth {
+		master = imaging.Resize(img, masterWidth, 0, imaging.Lanczos)
+	}
+	var masterBuf bytes.Buffer
+	if err := imaging.Encode(&masterBuf, master, imaging.JPEG, imaging.JPEGQuality(90)); err != nil {
+		return nil, fmt.Errorf("failed to encode master: %w", err)
+	}
+
+	renditions, err := renderRenditions(master, folder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderedPhoto{
+		Master: renderedFile{
+			ObjectName:  masterObjectName(folder),
+			ContentType: "image/jpeg",
+			Data:        masterBuf.Bytes(),
+			Width:       master.Bounds().Dx(),
+			Height:      master.Bounds().Dy(),
+		},
+		Renditions: renditions,
+	}, nil
+}
+
+// renderRenditions encodes every size as JPEG into folder.
+func renderRenditions(img image.Image, folder string, opts ImageOptions) ([]renderedFile, error) {
+	files := make([]renderedFile, 0, len(domain.PhotoSizes))
+
+	for _, size := range domain.PhotoSizes {
+		resized := img
+		if img.Bounds().Dx() > domain.PhotoSizeWidths[size] {
+			resized = imaging.Resize(img, domain.PhotoSizeWidths[size], 0, imaging.Lanczos)
+		}
+		// Every size under lots/ is public, the thumb included.
+		if opts.Watermark != nil {
+			resized = opts.Watermark.apply(resized)
+		}
+		width, height := resized.Bounds().Dx(), resized.Bounds().Dy()
+
+		// JPEG is fast, reliable, and works on ARM without CGO
+		var jpegBuf bytes.Buffer
+		if err := imaging.Encode(&jpegBuf, resized, imaging.JPEG, imaging.JPEGQuality(80)); err != nil {
+			return nil, fmt.Errorf("failed to encode %s to jpeg: %w", size, err)
+		}
+		files = append(files, renderedFile{
+			Size:        size,
+			ObjectName:  fmt.Sprintf("%s%s.jpg", folder, size),
+			ContentType: "image/jpeg",
+			Data:        jpegBuf.Bytes(),
+			Width:       width,
+			Height:      height,
+		})
+	}
+
+	return files, nil
+}
+
+// buildPhotoAsset groups stored renditions by size. urlOf maps an object name to its public URL.
+func buildPhotoAsset(files []renderedFile, urlOf func(objectName string) string) *domain.PhotoAsset {
+	asset := &domain.PhotoAsset{Renditions: make([]domain.PhotoRendition, 0, len(domain.PhotoSizes))}
+
+	for _, size := range domain.PhotoSizes {
+		rendition := domain.PhotoRendition{Size: size}
+		for _, f := range files {
+			if f.Size != size {
+				continue
+			}
+			rendition.Width, rendition.Height = f.Width, f.Height
+			rendition.URL = urlOf(f.ObjectName)
+		}
+		asset.Renditions = append(asset.Renditions, rendition)
+
+		if size == domain.PhotoSizeFull {
+			asset.URL = rendition.URL
+			asset.Width, asset.Height = rendition.Width, rendition.Height
+		}
+	}
+
+	return asset
+}
+
+// renditionFolder returns the folder holding all renditions when objectName is exactly "lots/<uuid>/full.jpg",
+// or "" for anything else. Object names come from client URLs, so the folder is rebuilt from the parsed ID
+// and can never point at "lots/" itself or outside it.
+func renditionFolder(objectName string) string {
+	rest, ok := strings.CutPrefix(objectName, photosPath)
+	if !ok {
+		return ""
+	}
+	id, name, ok := strings.Cut(rest, "/")
+	if !ok || name != string(domain.PhotoSizeFull)+".jpg" {
+		return ""
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil || parsed.String() != id {
+		return ""
+	}
+	return photosPath + parsed.String() + "/"
+}
+
+// isLegacyPhoto reports whether objectName is a single "lots/<uuid>.jpg" file uploaded before renditions existed.
+func isLegacyPhoto(objectName string) bool {
+	rest, ok := strings.CutPrefix(objectName, photosPath)
+	if !ok {
+		return false
+	}
+	id, ok := strings.CutSuffix(rest, ".jpg")
+	if !ok {
+		return false
+	}
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.String() == id
+}
+
+// isPhotoObject reports whether objectName is the file a photo URL points at, a full-size rendition or a legacy file.
+func isPhotoObject(objectName string) bool {
+	return renditionFolder(objectName) != "" || isLegacyPhoto(objectName)
+}
+
+// masterObjectName maps "lots/<id>/" to "masters/<id>.jpg". folder must come from renditionFolder.
+func masterObjectName(folder string) string {
+	id := strings.TrimSuffix(strings.TrimPrefix(folder, photosPath), "/")
+	return mastersPath + id + masterSuffix
+}
+
+// inspectImage sniffs the content type and reads the dimensions from the image header only,
+// so oversized or fake files are rejected before they are decoded.
+func inspectImage(r io.Reader) (*domain.StoredObjectInfo, error) {
+	buffered := bufio.NewReader(r)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read image header: %w", err)
+	}
+
+	config, _, err := image.DecodeConfig(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %w", err)
+	}
+
+	return &domain.StoredObjectInfo{
+		ContentType: http.DetectContentType(head),
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
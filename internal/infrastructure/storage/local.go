@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// ErrPresignNotSupported is returned by drivers that cannot accept uploads bypassing the API.
+var ErrPresignNotSupported = errors.New("direct uploads are not supported by this storage driver, use POST /staff/lots/upload")
+
+type localStorage struct {
+	dir       string
+	publicURL string
+	images    ImageOptions
+	logger    *slog.Logger
+}
+
+// NewLocalStorage stores files under dir. publicURL is where dir is served from,
+// e.g. "http://localhost:8083/media", so URLs look the same as with MinIO: <base>/lots/<id>/full.jpg.
+// Only the "lots" subdirectory should be served; masters and raw uploads stay private.
+func NewLocalStorage(dir, publicURL string, images ImageOptions, logger *slog.Logger) (domain.StorageService, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &localStorage{
+		dir:       dir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		images:    images,
+		logger:    logger,
+	}, nil
+}
+
+func (s *localStorage) UploadPhoto(ctx context.Context, file io.Reader, originalFilename string) (*domain.PhotoAsset, error) {
+	s.logger.Debug("processing image upload", slog.String("original_name", originalFilename))
+
+	photo, err := renderPhoto(file, s.images)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.writeFiles(append([]renderedFile{photo.Master}, photo.Renditions...)); err != nil {
+		return nil, err
+	}
+
+	asset := buildPhotoAsset(photo.Renditions, s.objectURL)
+
+	s.logger.Debug("photo uploaded successfully", slog.String("url", asset.URL), slog.Int("files", len(photo.Renditions)+1))
+	return asset, nil
+}
+
+// ReprocessPhoto renders the renditions again from the master with the current settings, keeping the URLs.
+// Photos uploaded before masters existed use their clean full-size file, which becomes the master.
+// Single files from before renditions existed are converted, see renderLegacyPhoto.
+func (s *localStorage) ReprocessPhoto(ctx context.Context, fileURL string) (*domain.PhotoAsset, error) {
+	objectName := strings.TrimPrefix(fileURL, s.publicURL+"/")
+	if objectName != fileURL && isLegacyPhoto(objectName) {
+		return s.convertLegacyPhoto(objectName)
+	}
+	folder := renditionFolder(objectName)
+	if objectName == fileURL || folder == "" {
+		return nil, fmt.Errorf("not a rendition set: %s", fileURL)
+	}
+	masterName := masterObjectName(folder)
+
+	source := masterName
+	data, err := os.ReadFile(s.objectPath(masterName))
+	if os.IsNotExist(err) {
+		source = objectName
+		data, err = os.ReadFile(s.objectPath(objectName))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+
+	files, err := renderRenditions(img, folder, s.images)
+	if err != nil {
+		return nil, err
+	}
+	if source != masterName {
+		files = append(files, renderedFile{ObjectName: masterName, ContentType: "image/jpeg", Data: data})
+	}
+
+	if err := s.writeFiles(files); err != nil {
+		return nil, err
+	}
+
+	return buildPhotoAsset(files, s.objectURL), nil
+}
+
+func (s *localStorage) convertLegacyPhoto(objectName string) (*domain.PhotoAsset, error) {
+	file, err := os.Open(s.objectPath(objectName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	defer file.Close()
+
+	photo, err := renderLegacyPhoto(file, objectName, s.images)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writeFiles(append([]renderedFile{photo.Master}, photo.Renditions...)); err != nil {
+		return nil, err
+	}
+
+	return buildPhotoAsset(photo.Renditions, s.objectURL), nil
+}
+
+func (s *localStorage) writeFiles(files []renderedFile) error {
+	for _, f := range files {
+		filePath := s.objectPath(f.ObjectName)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			return fmt.Errorf("failed to create photo directory: %w", err)
+		}
+		if err := os.WriteFile(filePath, f.Data, 0o644); err != nil {
+			return fmt.Errorf("failed to write photo: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *localStorage) DeletePhoto(ctx context.Context, fileURL string) error {
+	objectName := strings.TrimPrefix(fileURL, s.publicURL+"/")
+	if objectName == fileURL {
+		s.logger.Warn("file url does not match storage prefix, skipping deletion", slog.String("url", fileURL))
+		return nil
+	}
+
+	// Renditions live next to the full-size file; legacy uploads are a single file. Anything else is refused,
+	// the URL comes from the client.
+	folder := renditionFolder(objectName)
+	switch {
+	case folder != "":
+		if err := os.RemoveAll(s.objectPath(folder)); err != nil {
+			return fmt.Errorf("failed to delete photo renditions: %w", err)
+		}
+		if err := s.DeleteObject(ctx, masterObjectName(folder)); err != nil {
+			return err
+		}
+	case isLegacyPhoto(objectName):
+		if err := s.DeleteObject(ctx, objectName); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("not a photo url: %s", fileURL)
+	}
+
+	s.logger.Debug("photo deleted successfully from storage", slog.String("object", objectName))
+	return nil
+}
+
+func (s *localStorage) PresignUpload(ctx context.Context, objectKey string, contentType string, maxSize int64, ttl time.Duration) (*domain.PresignedPost, error) {
+	return nil, ErrPresignNotSupported
+}
+
+func (s *localStorage) InspectObject(ctx context.Context, objectKey string) (*domain.StoredObjectInfo, error) {
+	file, err := os.Open(s.objectPath(objectKey))
+	if err != nil {
+		return nil, fmt.Errorf("uploaded object not found: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat uploaded object: %w", err)
+	}
+
+	info, err := inspectImage(file)
+	if err != nil {
+		return nil, err
+	}
+	info.Size = stat.Size()
+
+	return info, nil
+}
+
+func (s *localStorage) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	file, err := os.Open(s.objectPath(objectKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	return file, nil
+}
+
+func (s *localStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	if err := os.Remove(s.objectPath(objectKey)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) ListPhotos(ctx context.Context) ([]domain.StoredPhoto, error) {
+	var photos []domain.StoredPhoto
+	root := s.objectPath(photosPath)
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filePath == root {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return err
+		}
+		objectName := filepath.ToSlash(rel)
+		if !isPhotoObject(objectName) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		photos = append(photos, domain.StoredPhoto{URL: s.objectURL(objectName), ModifiedAt: info.ModTime(), Legacy: isLegacyPhoto(objectName)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list photos: %w", err)
+	}
+
+	return photos, nil
+}
+
+// objectPath maps an object key to a file inside dir. Cleaning the key as an absolute path
+// drops any "../" so a crafted URL cannot reach files outside the storage directory.
+func (s *localStorage) objectPath(objectKey string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+objectKey)))
+}
+
+func (s *localStorage) objectURL(objectName string) string {
+	return s.publicURL + "/" + objectName
+}
@@ -6,32 +6,51 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/disintegration/imaging"
-	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
 type minioStorage struct {
-	client     *minio.Client
-	bucketName string
-	publicURL  string
-	logger     *slog.Logger
+	client        *minio.Client
+	presignClient *minio.Client // Signs URLs for the public endpoint, since clients cannot reach the internal one
+	bucketName    string
+	publicURL     string
+	images        ImageOptions
+	logger        *slog.Logger
 }
 
-// NewMinioStorage initializes a new MinIO/S3 client and ensures the target bucket exists and is public.
-func NewMinioStorage(endpoint, accessKey, secretKey, bucketName, publicURL string, useSSL bool, logger *slog.Logger) (domain.StorageService, error) {
+// NewMinioStorage initializes a new MinIO/S3 client and ensures the target bucket exists and its photos are public.
+func NewMinioStorage(endpoint, accessKey, secretKey, bucketName, publicURL, region string, useSSL bool, images ImageOptions, logger *slog.Logger) (domain.StorageService, error) {
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
 		Secure: useSSL,
+		Region: region,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to init minio client: %w", err)
 	}
 
+	// Presigned URLs are bound to the host they were signed for. With the region set,
+	// signing needs no request to the server, so the public host does not have to be reachable from here.
+	public, err := url.Parse(publicURL)
+	if err != nil || public.Host == "" {
+		return nil, fmt.Errorf("invalid minio public url: %s", publicURL)
+	}
+	presignClient, err := minio.New(public.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: public.Scheme == "https",
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init minio presign client: %w", err)
+	}
+
 	ctx := context.Background()
 	exists, err := client.BucketExists(ctx, bucketName)
 	if err == nil && !exists {
@@ -39,65 +58,138 @@ func NewMinioStorage(endpoint, accessKey, secretKey, bucketName, publicURL strin
 		err = client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
 		if err != nil {
 			logger.Error("failed to create bucket", slog.String("bucket", bucketName))
-		} else {
-			// Make the bucket public for reading (allows frontend to access images directly)
-			policy := fmt.Sprintf(`{"Version": "2012-10-17","Statement": [{"Action": ["s3:GetObject"],"Effect": "Allow","Principal": {"AWS": ["*"]},"Resource": ["arn:aws:s3:::%s/*"]}]}`, bucketName)
-			client.SetBucketPolicy(ctx, bucketName, policy)
+		}
+	}
+	if err == nil {
+		// Make photos public for reading (allows frontend to access images directly).
+		// Masters and raw direct uploads stay private. Applied on every start to update older buckets.
+		policy := fmt.Sprintf(`{"Version": "2012-10-17","Statement": [{"Action": ["s3:GetObject"],"Effect": "Allow","Principal": {"AWS": ["*"]},"Resource": ["arn:aws:s3:::%s/%s*"]}]}`, bucketName, photosPath)
+		if err := client.SetBucketPolicy(ctx, bucketName, policy); err != nil {
+			logger.Warn("failed to set bucket policy", slog.String("bucket", bucketName), slog.String("error", err.Error()))
 		}
 	}
 
 	return &minioStorage{
-		client:     client,
-		bucketName: bucketName,
-		publicURL:  publicURL,
-		logger:     logger,
+		client:        client,
+		presignClient: presignClient,
+		bucketName:    bucketName,
+		publicURL:     publicURL,
+		images:        images,
+		logger:        logger,
 	}, nil
 }
 
-func (s *minioStorage) UploadPhoto(ctx context.Context, file io.Reader, originalFilename string) (string, error) {
+func (s *minioStorage) UploadPhoto(ctx context.Context, file io.Reader, originalFilename string) (*domain.PhotoAsset, error) {
 	// Log the original filename (resolves unused parameter warning and helps with debugging)
 	s.logger.Debug("processing image upload", slog.String("original_name", originalFilename))
 
-	// 1. Decode the original image
-	img, err := imaging.Decode(file, imaging.AutoOrientation(true))
+	// 1. Decode once, strip metadata and render the master plus thumb, card and full sizes
+	photo, err := renderPhoto(file, s.images)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Upload the master and every rendition to MinIO
+	if err := s.putFiles(ctx, append([]renderedFile{photo.Master}, photo.Renditions...)); err != nil {
+		return nil, err
+	}
+
+	// 3. Return the rendition set. Its URL (full JPEG) is what gets saved in lot photos.
+	// Example: http://localhost:9000/tires-shop/lots/123-456/full.jpg
+	asset := buildPhotoAsset(photo.Renditions, s.objectURL)
+
+	s.logger.Debug("photo uploaded successfully", slog.String("url", asset.URL), slog.Int("files", len(photo.Renditions)+1))
+	return asset, nil
+}
+
+// ReprocessPhoto renders the renditions again from the master with the current settings, keeping the URLs.
+// Photos uploaded before masters existed use their clean full-size file, which becomes the master.
+// Single files from before renditions existed are converted, see renderLegacyPhoto.
+func (s *minioStorage) ReprocessPhoto(ctx context.Context, fileURL string) (*domain.PhotoAsset, error) {
+	objectName := strings.TrimPrefix(fileURL, fmt.Sprintf("%s/%s/", s.publicURL, s.bucketName))
+	if objectName != fileURL && isLegacyPhoto(objectName) {
+		return s.convertLegacyPhoto(ctx, objectName)
+	}
+	folder := renditionFolder(objectName)
+	if objectName == fileURL || folder == "" {
+		return nil, fmt.Errorf("not a rendition set: %s", fileURL)
+	}
+	masterName := masterObjectName(folder)
+
+	source := masterName
+	if _, err := s.client.StatObject(ctx, s.bucketName, masterName, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			return nil, fmt.Errorf("failed to read photo master: %w", err)
+		}
+		source = objectName
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucketName, source, minio.GetObjectOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to decode image: %w", err)
+		return nil, fmt.Errorf("failed to read photo: %w", err)
 	}
+	defer object.Close()
 
-	// 2. Resize. If the width is greater than 1080px, scale it down proportionally.
-	if img.Bounds().Dx() > 1080 {
-		img = imaging.Resize(img, 1080, 0, imaging.Lanczos)
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
 	}
 
-	// 3. Convert to JPEG (fast, reliable, works on ARM without CGO)
-	var buf bytes.Buffer
-	err = imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(80))
+	files, err := renderRenditions(img, folder, s.images)
 	if err != nil {
-		return "", fmt.Errorf("failed to encode to jpeg: %w", err)
+		return nil, err
+	}
+	if source != masterName {
+		files = append(files, renderedFile{ObjectName: masterName, ContentType: "image/jpeg", Data: data})
 	}
 
-	// 4. Generate a unique filename
-	filename := fmt.Sprintf("lots/%s.jpg", uuid.New().String())
+	if err := s.putFiles(ctx, files); err != nil {
+		return nil, err
+	}
 
-	// 5. Upload to MinIO
-	_, err = s.client.PutObject(ctx, s.bucketName, filename, &buf, int64(buf.Len()), minio.PutObjectOptions{
-		ContentType: "image/jpeg",
-	})
+	return buildPhotoAsset(files, s.objectURL), nil
+}
+
+func (s *minioStorage) convertLegacyPhoto(ctx context.Context, objectName string) (*domain.PhotoAsset, error) {
+	object, err := s.client.GetObject(ctx, s.bucketName, objectName, minio.GetObjectOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to minio: %w", err)
+		return nil, fmt.Errorf("failed to read photo: %w", err)
 	}
+	defer object.Close()
 
-	// 6. Return the public URL to be saved in the database
-	// Example: http://localhost:9000/tires-shop/lots/123-456.jpg
-	fileURL := fmt.Sprintf("%s/%s/%s", s.publicURL, s.bucketName, filename)
+	photo, err := renderLegacyPhoto(object, objectName, s.images)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.putFiles(ctx, append([]renderedFile{photo.Master}, photo.Renditions...)); err != nil {
+		return nil, err
+	}
+
+	return buildPhotoAsset(photo.Renditions, s.objectURL), nil
+}
+
+func (s *minioStorage) putFiles(ctx context.Context, files []renderedFile) error {
+	for _, f := range files {
+		_, err := s.client.PutObject(ctx, s.bucketName, f.ObjectName, bytes.NewReader(f.Data), int64(len(f.Data)), minio.PutObjectOptions{
+			ContentType: f.ContentType,
+			// Not immutable: reprocessing rewrites renditions in place.
+			CacheControl: "public, max-age=86400",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload to minio: %w", err)
+		}
+	}
 
-	s.logger.Debug("photo uploaded successfully", slog.String("url", fileURL))
-	return fileURL, nil
+	return nil
 }
 
 func (s *minioStorage) DeletePhoto(ctx context.Context, fileURL string) error {
-	// fileURL looks like "http://localhost:9000/tires-shop/lots/123-456.jpg"
-	// We need to extract the object name within the bucket: "lots/123-456.jpg"
+	// fileURL looks like "http://localhost:9000/tires-shop/lots/123-456/full.jpg"
+	// We need to extract the object name within the bucket: "lots/123-456/full.jpg"
 	prefix := fmt.Sprintf("%s/%s/", s.publicURL, s.bucketName)
 	objectName := strings.TrimPrefix(fileURL, prefix)
 
@@ -109,11 +201,114 @@ func (s *minioStorage) DeletePhoto(ctx context.Context, fileURL string) error {
 		return nil
 	}
 
-	err := s.client.RemoveObject(ctx, s.bucketName, objectName, minio.RemoveObjectOptions{})
+	// Renditions live next to the full-size file; legacy uploads are a single object. Anything else is refused,
+	// the URL comes from the client.
+	objectNames := []string{objectName}
+	folder := renditionFolder(objectName)
+	if folder == "" && !isLegacyPhoto(objectName) {
+		return fmt.Errorf("not a photo url: %s", fileURL)
+	}
+	if folder != "" {
+		objectNames = []string{masterObjectName(folder)}
+		for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{Prefix: folder}) {
+			if object.Err != nil {
+				return fmt.Errorf("failed to list photo renditions: %w", object.Err)
+			}
+			objectNames = append(objectNames, object.Key)
+		}
+	}
+
+	for _, name := range objectNames {
+		if err := s.client.RemoveObject(ctx, s.bucketName, name, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("failed to delete file from minio: %w", err)
+		}
+	}
+
+	s.logger.Debug("photo deleted successfully from storage", slog.String("object", objectName), slog.Int("files", len(objectNames)))
+	return nil
+}
+
+func (s *minioStorage) objectURL(objectName string) string {
+	return fmt.Sprintf("%s/%s/%s", s.publicURL, s.bucketName, objectName)
+}
+
+// PresignUpload signs a POST policy rather than a PUT URL: only a policy lets S3 itself enforce the
+// content type and the size limit.
+func (s *minioStorage) PresignUpload(ctx context.Context, objectKey string, contentType string, maxSize int64, ttl time.Duration) (*domain.PresignedPost, error) {
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(s.bucketName); err != nil {
+		return nil, fmt.Errorf("failed to build upload policy: %w", err)
+	}
+	if err := policy.SetKey(objectKey); err != nil {
+		return nil, fmt.Errorf("failed to build upload policy: %w", err)
+	}
+	if err := policy.SetContentType(contentType); err != nil {
+		return nil, fmt.Errorf("failed to build upload policy: %w", err)
+	}
+	if err := policy.SetContentLengthRange(1, maxSize); err != nil {
+		return nil, fmt.Errorf("failed to build upload policy: %w", err)
+	}
+	if err := policy.SetExpires(time.Now().UTC().Add(ttl)); err != nil {
+		return nil, fmt.Errorf("failed to build upload policy: %w", err)
+	}
+
+	presigned, formData, err := s.presignClient.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	return &domain.PresignedPost{URL: presigned.String(), FormData: formData}, nil
+}
+
+func (s *minioStorage) InspectObject(ctx context.Context, objectKey string) (*domain.StoredObjectInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucketName, objectKey, minio.StatObjectOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to delete file from minio: %w", err)
+		return nil, fmt.Errorf("uploaded object not found: %w", err)
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucketName, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded object: %w", err)
+	}
+	defer object.Close()
+
+	info, err := inspectImage(object)
+	if err != nil {
+		return nil, err
+	}
+	info.Size = stat.Size
+
+	return info, nil
+}
+
+func (s *minioStorage) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucketName, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object from minio: %w", err)
+	}
+
+	return object, nil
+}
+
+func (s *minioStorage) DeleteObject(ctx context.Context, objectKey string) error {
+	if err := s.client.RemoveObject(ctx, s.bucketName, objectKey, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object from minio: %w", err)
 	}
 
-	s.logger.Debug("photo deleted successfully from storage", slog.String("object", objectName))
 	return nil
 }
+
+func (s *minioStorage) ListPhotos(ctx context.Context) ([]domain.StoredPhoto, error) {
+	var photos []domain.StoredPhoto
+	for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{Prefix: photosPath, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list photos: %w", object.Err)
+		}
+		if !isPhotoObject(object.Key) {
+			continue
+		}
+		photos = append(photos, domain.StoredPhoto{URL: s.objectURL(object.Key), ModifiedAt: object.LastModified, Legacy: isLegacyPhoto(object.Key)})
+	}
+
+	return photos, nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// minWatermarkWidth keeps the logo readable on thumbs, where the configured scale would make it a few pixels wide.
+const minWatermarkWidth = 64
+
+// Watermark positions.
+const (
+	WatermarkTopLeft     = "top-left"
+	WatermarkTopRight    = "top-right"
+	WatermarkBottomLeft  = "bottom-left"
+	WatermarkBottomRight = "bottom-right"
+	WatermarkCenter      = "center"
+)
+
+// Watermark is a logo blended over public renditions.
+type Watermark struct {
+	logo     image.Image
+	position string
+	opacity  float64
+	scale    float64
+}
+
+// LoadWatermark reads the logo (PNG with transparency works best). scale is the logo width
+// relative to the photo width, opacity is between 0 and 1.
+func LoadWatermark(logoPath, position string, opacity, scale float64) (*Watermark, error) {
+	switch position {
+	case WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+	default:
+		return nil, fmt.Errorf("unknown watermark position: %s", position)
+	}
+	if opacity <= 0 || opacity > 1 {
+		return nil, fmt.Errorf("watermark opacity must be in (0, 1], got %v", opacity)
+	}
+	if scale <= 0 || scale > 1 {
+		return nil, fmt.Errorf("watermark scale must be in (0, 1], got %v", scale)
+	}
+
+	logo, err := imaging.Open(logoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watermark logo: %w", err)
+	}
+
+	return &Watermark{
+		logo:     logo,
+		position: position,
+		opacity:  opacity,
+		scale:    scale,
+	}, nil
+}
+
+func (w *Watermark) apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	width := int(float64(bounds.Dx()) * w.scale)
+	if width < minWatermarkWidth {
+		// Never wider than a third of the photo, so small renditions still show the tires.
+		width = min(minWatermarkWidth, bounds.Dx()/3)
+	}
+	logo := imaging.Resize(w.logo, width, 0, imaging.Lanczos)
+	margin := bounds.Dx() / 40
+
+	freeX := bounds.Dx() - logo.Bounds().Dx()
+	freeY := bounds.Dy() - logo.Bounds().Dy()
+
+	var pos image.Point
+	switch w.position {
+	case WatermarkTopLeft:
+		pos = image.Pt(margin, margin)
+	case WatermarkTopRight:
+		pos = image.Pt(freeX-margin, margin)
+	case WatermarkBottomLeft:
+		pos = image.Pt(margin, freeY-margin)
+	case WatermarkCenter:
+		pos = image.Pt(freeX/2, freeY/2)
+	default:
+		pos = image.Pt(freeX-margin, freeY-margin)
+	}
+
+	return imaging.Overlay(img, logo, pos.Add(bounds.Min), w.opacity)
+}
@@ -1,11 +1,13 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"time"
@@ -20,6 +22,7 @@ type Sender interface {
 	SendMessage(chatID int64, message string) (int64, error)
 	SendReplyableMessage(chatID int64, message string) (int64, error)
 	SendHTMLMessage(chatID int64, message string) (int64, error)
+	SendDocument(chatID int64, fileName string, content []byte, caption string) (int64, error)
 }
 
 type botNotifier struct {
@@ -102,6 +105,50 @@ func (b *botNotifier) sendMessage(chatID int64, message string, forceReply bool,
 	}
 	defer resp.Body.Close()
 
+	return decodeSentMessage(resp)
+}
+
+// SendDocument uploads a file to the chat as a document, e.g. an order invoice PDF.
+func (b *botNotifier) SendDocument(chatID int64, fileName string, content []byte, caption string) (int64, error) {
+	if chatID == 0 {
+		return 0, fmt.Errorf("telegram chat id is required")
+	}
+	if len(content) == 0 {
+		return 0, fmt.Errorf("document is empty")
+	}
+	if b.token == "" {
+		return 0, fmt.Errorf("telegram bot token is not configured")
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("chat_id", fmt.Sprintf("%d", chatID))
+	if caption != "" {
+		_ = writer.WriteField("caption", caption)
+	}
+	part, err := writer.CreateFormFile("document", fileName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build telegram document upload: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return 0, fmt.Errorf("failed to build telegram document upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to build telegram document upload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendDocument", b.token)
+	resp, err := b.client.Post(endpoint, writer.FormDataContentType(), &body)
+	if err != nil {
+		return 0, fmt.Errorf("telegram send request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeSentMessage(resp)
+}
+
+// decodeSentMessage reads the message ID of a sendMessage or sendDocument response.
+func decodeSentMessage(resp *http.Response) (int64, error) {
 	var payload struct {
 		OK          bool   `json:"ok"`
 		Description string `json:"description"`
@@ -17,7 +17,9 @@ type webhookResponse struct {
 	} `json:"result"`
 }
 
-func EnsureWebhook(botToken string, webhookURL string) error {
+// EnsureWebhook registers the webhook URL. Telegram does not report the secret token back, so when a
+// secret is set the webhook is registered on every start to keep the token in sync.
+func EnsureWebhook(botToken string, webhookURL string, secretToken string) error {
 	if botToken == "" {
 		return fmt.Errorf("telegram bot token is not configured")
 	}
@@ -31,11 +33,11 @@ func EnsureWebhook(botToken string, webhookURL string) error {
 	if err != nil {
 		return err
 	}
-	if currentURL == webhookURL {
+	if currentURL == webhookURL && secretToken == "" {
 		return nil
 	}
 
-	return setWebhookURL(client, botToken, webhookURL)
+	return setWebhookURL(client, botToken, webhookURL, secretToken)
 }
 
 func getWebhookURL(client *http.Client, botToken string) (string, error) {
@@ -61,11 +63,14 @@ func getWebhookURL(client *http.Client, botToken string) (string, error) {
 	return payload.Result.URL, nil
 }
 
-func setWebhookURL(client *http.Client, botToken string, webhookURL string) error {
+func setWebhookURL(client *http.Client, botToken string, webhookURL string, secretToken string) error {
 	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/setWebhook", botToken)
 
 	form := url.Values{}
 	form.Set("url", webhookURL)
+	if secretToken != "" {
+		form.Set("secret_token", secretToken)
+	}
 
 	resp, err := client.PostForm(endpoint, form)
 	if err != nil {
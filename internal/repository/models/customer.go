@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Customer is a buyer record shared by their orders. Orders keep their own copy of the contact data as
+// it was at checkout.
+type Customer struct {
+	Base
+	Name         string         `gorm:"type:varchar(100)"`
+	Phone        string         `gorm:"type:varchar(20);index"` // Normalized, see domain.NormalizePhone
+	Username     string         `gorm:"type:varchar(100);index"`
+	TelegramID   *int64         `gorm:"index"`
+	Notes        string         `gorm:"type:text"`
+	Tags         datatypes.JSON `gorm:"type:jsonb"`      // Upper-cased tags, e.g. VIP
+	MergedIntoID *uuid.UUID     `gorm:"type:uuid;index"` // Set on deleted duplicates
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// FiscalReceipt is a sale or return receipt of an offline order in the fiscalization queue.
+// The request is kept as sent so every retry reports the same goods and payments.
+type FiscalReceipt struct {
+	Base
+	OrderID       uuid.UUID      `gorm:"type:uuid;not null;index"`
+	OrderReturnID *uuid.UUID     `gorm:"type:uuid;uniqueIndex"`
+	Type          string         `gorm:"type:varchar(20);not null"`       // domain.FiscalReceiptType
+	Status        string         `gorm:"type:varchar(20);not null;index"` // domain.FiscalReceiptStatus
+	Provider      string         `gorm:"type:varchar(30);not null"`
+	Amount        float64        `gorm:"not null"`
+	Request       datatypes.JSON `gorm:"type:jsonb;not null"`
+	ProviderRef   string         `gorm:"type:varchar(128)"`
+	FiscalCode    string         `gorm:"type:varchar(64);index"`
+	Attempts      int            `gorm:"not null;default:0"`
+	LastError     string         `gorm:"type:varchar(500)"`
+	NextAttemptAt time.Time      `gorm:"not null;index"`
+	FiscalizedAt  *time.Time
+}
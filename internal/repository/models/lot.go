@@ -21,13 +21,15 @@ const (
 // Lot represents the database schema for the lots table.
 type Lot struct {
 	Base
-	WarehouseID uuid.UUID `gorm:"type:uuid;not null;index"` // Where this lot is stored
+	WarehouseID uuid.UUID  `gorm:"type:uuid;not null;index"` // Where this lot is stored
+	LocationID  *uuid.UUID `gorm:"type:uuid;index"`          // Bin/shelf inside the warehouse, optional
 
 	// Attributes
 	Type      LotType      `gorm:"type:varchar(20);not null"` // TIRE, RIM or ACCESSORY
 	Condition LotCondition `gorm:"type:varchar(20);not null"` // NEW or USED
 	Brand     string       `gorm:"type:varchar(100);not null;index"`
 	Model     string       `gorm:"type:varchar(100)"`
+	Barcode   string       `gorm:"type:varchar(13);index"` // Manufacturer EAN, optional
 
 	// JSONB strongly typed to domain.LotParams in the application layer
 	Params datatypes.JSON `gorm:"type:jsonb"`
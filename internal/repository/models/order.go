@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -12,9 +14,25 @@ type Order struct {
 	CustomerPhone      string     `gorm:"type:varchar(20);index"`
 	CustomerUsername   string     `gorm:"type:varchar(100);index"`
 	CustomerTelegramID *int64     `gorm:"index"`
+	CustomerID         *uuid.UUID `gorm:"type:uuid;index"` // Linked by normalized phone or Telegram ID, see Customer
 	Channel            string     `gorm:"type:varchar(20);default:'ONLINE';index"`
-	Status             string     `gorm:"type:varchar(20);default:'NEW';index"` // NEW, PREPAYMENT, DONE, CANCELLED
+	Status             string     `gorm:"type:varchar(20);default:'NEW';index"` // domain.OrderStatus
 	TotalAmount        float64    `gorm:"not null"`
+	TrackingNumber     string     `gorm:"type:varchar(64);index"` // Carrier waybill, see Shipment
+	PromoCodeID        *uuid.UUID `gorm:"type:uuid;index"`
+	PromoCode          string     `gorm:"type:varchar(40)"`   // Code as entered, kept for history
+	DiscountAmount     float64    `gorm:"not null;default:0"` // Sum of the item discounts, TotalAmount is net of it
+	FiscalCode         string     `gorm:"type:varchar(64)"`   // Fiscal number of the sale receipt, offline orders only
+
+	// Assignment and SLA timers, see domain.OrderSLA. Due dates are nil for orders placed before tracking.
+	AssigneeID               *uuid.UUID `gorm:"type:uuid;index"`
+	AssignedAt               *time.Time
+	FirstResponseDueAt       *time.Time `gorm:"index"`
+	FirstRespondedAt         *time.Time
+	FirstResponseEscalatedAt *time.Time
+	ProcessingDueAt          *time.Time `gorm:"index"`
+	ProcessedAt              *time.Time
+	ProcessingEscalatedAt    *time.Time
 
 	// Has-Many relationship
 	Items []OrderItem `gorm:"foreignKey:OrderID"`
@@ -32,4 +50,6 @@ type OrderItem struct {
 	Quantity      int     `gorm:"not null"`
 	PriceAtMoment float64 `gorm:"not null"` // Sell price at the time of order
 	CostAtMoment  float64 `gorm:"not null"` // Purchase price at the time of order (for P&L)
+	// DiscountAmount is this line's share of the order discount, for the whole quantity.
+	DiscountAmount float64 `gorm:"not null;default:0"`
 }
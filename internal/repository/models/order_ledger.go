@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderLedgerEntry is money received for an order or paid back to the buyer. The order balance is
+// computed from these entries, online payments add theirs when the provider confirms them.
+type OrderLedgerEntry struct {
+	Base
+	OrderID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Type         string     `gorm:"type:varchar(20);not null"` // domain.LedgerEntryType
+	Method       string     `gorm:"type:varchar(20);not null"` // domain.PaymentMethod
+	Amount       float64    `gorm:"not null"`
+	PaymentID    *uuid.UUID `gorm:"type:uuid;index"`
+	ReceivedByID *uuid.UUID `gorm:"type:uuid"`
+	ReceivedAt   time.Time  `gorm:"not null;index"`
+	Comment      string     `gorm:"type:text"`
+}
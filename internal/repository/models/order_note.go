@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// OrderNote is an internal staff note on an order, never shown to the buyer.
+type OrderNote struct {
+	Base
+	OrderID    uuid.UUID      `gorm:"type:uuid;not null;index"`
+	AuthorID   uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Text       string         `gorm:"type:text;not null"`
+	MentionIDs datatypes.JSON `gorm:"type:jsonb"` // IDs of the mentioned staff
+}
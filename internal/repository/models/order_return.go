@@ -0,0 +1,27 @@
+package models
+
+import "github.com/google/uuid"
+
+// OrderReturn is a return (RMA) registered on a completed order. P&L books it on its own CreatedAt.
+type OrderReturn struct {
+	Base
+	OrderID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedByID  uuid.UUID `gorm:"type:uuid;not null"`
+	Reason       string    `gorm:"type:text;not null"`
+	RefundAmount float64   `gorm:"not null"`
+	FiscalCode   string    `gorm:"type:varchar(64)"` // Fiscal number of the return receipt
+
+	Items []OrderReturnItem `gorm:"foreignKey:ReturnID"`
+}
+
+// OrderReturnItem is a returned part of an order item.
+type OrderReturnItem struct {
+	Base
+	ReturnID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	OrderItemID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	LotID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	Quantity     int       `gorm:"not null"`
+	Disposition  string    `gorm:"type:varchar(20);not null"` // RESTOCK, WRITE_OFF
+	RefundAmount float64   `gorm:"not null"`                  // Share of the return refund, reduces revenue
+	CostAmount   float64   `gorm:"not null"`                  // Cost of the units, reduces COGS only when restocked
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Payment is one payment attempt of an order through an acquiring provider.
+type Payment struct {
+	Base
+	OrderID       uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Provider      string         `gorm:"type:varchar(30);not null;index"`
+	ProviderRef   string         `gorm:"type:varchar(128);index"`   // Invoice or transaction id on the provider side
+	Purpose       string         `gorm:"type:varchar(20);not null"` // domain.PaymentPurpose
+	Amount        float64        `gorm:"not null"`
+	Currency      string         `gorm:"type:varchar(3);not null;default:'UAH'"`
+	Status        string         `gorm:"type:varchar(20);not null;index"` // domain.PaymentStatus
+	PaymentURL    string         `gorm:"type:text"`
+	FailureReason string         `gorm:"type:varchar(300)"`
+	Callback      datatypes.JSON `gorm:"type:jsonb"` // Last verified provider notification
+	CreatedByID   uuid.UUID      `gorm:"type:uuid"`
+	ExpiresAt     *time.Time
+	PaidAt        *time.Time `gorm:"index"`
+}
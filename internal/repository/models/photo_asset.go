@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// PhotoAsset is the upload registry entry of a photo with the renditions generated for it.
+type PhotoAsset struct {
+	Base
+	URL          string         `gorm:"type:text;not null;uniqueIndex"` // Full-size JPEG, referenced from lot photos
+	Width        int            `gorm:"not null;default:0"`
+	Height       int            `gorm:"not null;default:0"`
+	Renditions   datatypes.JSON `gorm:"type:jsonb"`
+	UploadedByID *uuid.UUID     `gorm:"type:uuid;index"`
+	LotID        *uuid.UUID     `gorm:"type:uuid;index"` // Last lot the photo was attached to
+	AttachedAt   *time.Time
+}
+
+// PhotoUploadSession tracks a photo uploaded straight to storage with a presigned URL.
+type PhotoUploadSession struct {
+	Base
+	ObjectKey    string    `gorm:"type:varchar(255);not null;uniqueIndex"` // Raw upload, removed after processing
+	ContentType  string    `gorm:"type:varchar(50);not null"`
+	Status       string    `gorm:"type:varchar(20);not null;index"`
+	UploadedByID uuid.UUID `gorm:"type:uuid;not null;index"`
+	URL          string    `gorm:"type:text"` // Full-size JPEG once processed
+	Error        string    `gorm:"type:text"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
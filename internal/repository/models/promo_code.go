@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PromoCode is a discount buyers enter when ordering online. Usage is counted from the orders that
+// reference it, so cancelling an order gives the use back.
+type PromoCode struct {
+	Base
+	Code          string         `gorm:"type:varchar(40);uniqueIndex;not null"` // Stored upper-case
+	Description   string         `gorm:"type:text"`
+	Type          string         `gorm:"type:varchar(20);not null"` // domain.PromoDiscountType
+	Value         float64        `gorm:"not null"`
+	MaxDiscount   float64        `gorm:"not null;default:0"`
+	MinOrderTotal float64        `gorm:"not null;default:0"`
+	LotTypes      pq.StringArray `gorm:"type:text[]"`
+	Brands        pq.StringArray `gorm:"type:text[]"`
+	Conditions    pq.StringArray `gorm:"type:text[]"`
+	StartsAt      *time.Time     `gorm:"type:date"`
+	EndsAt        *time.Time     `gorm:"type:date"`
+	UsageLimit    int            `gorm:"not null;default:0"`
+	PerUserLimit  int            `gorm:"not null;default:0"`
+	Active        bool           `gorm:"not null;default:true;index"`
+	CreatedByID   uuid.UUID      `gorm:"type:uuid"`
+}
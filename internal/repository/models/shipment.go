@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Shipment is a carrier waybill created for an order.
+type Shipment struct {
+	Base
+	OrderID           uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex"`
+	Provider          string         `gorm:"type:varchar(30);not null;index"`
+	ProviderRef       string         `gorm:"type:varchar(64)"`
+	TrackingNumber    string         `gorm:"type:varchar(64);not null;index"`
+	RecipientName     string         `gorm:"type:varchar(200)"`
+	RecipientPhone    string         `gorm:"type:varchar(20)"`
+	CityRef           string         `gorm:"type:varchar(64)"`
+	CityName          string         `gorm:"type:varchar(200)"`
+	BranchRef         string         `gorm:"type:varchar(64)"`
+	BranchName        string         `gorm:"type:varchar(300)"`
+	Parcels           datatypes.JSON `gorm:"type:jsonb"`
+	Cost              float64
+	CashOnDelivery    float64
+	EstimatedDelivery string     `gorm:"type:varchar(20)"`
+	State             string     `gorm:"type:varchar(20);not null;index"` // domain.ShipmentState
+	StatusCode        string     `gorm:"type:varchar(10)"`
+	Status            string     `gorm:"type:varchar(300)"`
+	CreatedByID       uuid.UUID  `gorm:"type:uuid"`
+	LastCheckedAt     *time.Time `gorm:"index"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// StorageContract represents customer tires kept in our warehouse between seasons ("tire hotel").
+// It is deliberately separate from Lot: stored tires are not for sale and never affect stock or P&L.
+type StorageContract struct {
+	Base
+	CustomerName       string         `gorm:"type:varchar(100);not null"`
+	CustomerPhone      string         `gorm:"type:varchar(20);not null;index"`
+	CustomerTelegramID *int64         `gorm:"index"`
+	VehiclePlate       string         `gorm:"type:varchar(20);not null;index"`
+	TireDescription    string         `gorm:"type:text;not null"`
+	Quantity           int            `gorm:"not null"`
+	Photos             pq.StringArray `gorm:"type:text[]"`
+
+	WarehouseID uuid.UUID `gorm:"type:uuid;not null;index"`
+	BinLocation string    `gorm:"type:varchar(50)"`
+
+	StartDate time.Time `gorm:"type:date;not null"`
+	EndDate   time.Time `gorm:"type:date;not null;index"`
+	Price     float64   `gorm:"not null;default:0"`
+
+	Status         string     `gorm:"type:varchar(20);default:'STORED';index"` // STORED, RETURNED, OVERDUE
+	Comment        string     `gorm:"type:text"`
+	CreatedByID    uuid.UUID  `gorm:"type:uuid;not null"`
+	ReturnedByID   *uuid.UUID `gorm:"type:uuid"`
+	ReturnedAt     *time.Time
+	ReminderSentAt *time.Time // Filled once the end-of-season reminder was delivered
+
+	OverdueReminderSentAt *time.Time // Filled once the customer was told the storage term has passed
+}
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/google/uuid"
+
+// WarehouseLocation is a node of the storage hierarchy inside a warehouse (zone > rack > shelf > bin).
+type WarehouseLocation struct {
+	Base
+	WarehouseID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ParentID    *uuid.UUID `gorm:"type:uuid;index"`
+	Type        string     `gorm:"type:varchar(20);not null"` // ZONE, RACK, SHELF, BIN
+	Code        string     `gorm:"type:varchar(30);not null;index"`
+	Name        string     `gorm:"type:varchar(100)"`
+	Path        string     `gorm:"type:varchar(255);not null;index"` // Codes from the top level joined with "/"
+}
+
+// LotLocationMove records every time a lot is put into (or taken out of) a location.
+type LotLocationMove struct {
+	Base
+	LotID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FromLocationID *uuid.UUID `gorm:"type:uuid"`
+	ToLocationID   *uuid.UUID `gorm:"type:uuid"`
+	FromPath       string     `gorm:"type:varchar(255)"` // Snapshot, so history survives renamed or deleted locations
+	ToPath         string     `gorm:"type:varchar(255)"`
+	MovedByID      uuid.UUID  `gorm:"type:uuid;not null"`
+	Comment        string     `gorm:"type:text"`
+}
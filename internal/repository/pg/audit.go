@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
 	"github.com/horoshi10v/tires-shop/internal/repository/models"
 	"gorm.io/gorm"
@@ -71,19 +72,7 @@ func (r *AuditRepo) List(ctx context.Context, filter domain.AuditLogFilter) ([]d
 
 	response := make([]domain.AuditLogResponse, 0, len(rows))
 	for _, row := range rows {
-		userLabel := strings.TrimSpace(row.Username)
-		if userLabel == "" {
-			fullName := strings.TrimSpace(fmt.Sprintf("%s %s", row.FirstName, row.LastName))
-			if fullName != "" {
-				userLabel = fullName
-			}
-		}
-		if userLabel == "" && row.PhoneNumber != "" {
-			userLabel = row.PhoneNumber
-		}
-		if userLabel == "" {
-			userLabel = row.UserID.String()
-		}
+		userLabel := formatUserLabel(row.UserID, row.Username, row.FirstName, row.LastName, row.PhoneNumber)
 
 		response = append(response, domain.AuditLogResponse{
 			ID:        row.ID,
@@ -101,3 +90,17 @@ func (r *AuditRepo) List(ctx context.Context, filter domain.AuditLogFilter) ([]d
 
 	return response, total, nil
 }
+
+// formatUserLabel names a user by username, full name or phone, falling back to the ID.
+func formatUserLabel(id uuid.UUID, username, firstName, lastName, phoneNumber string) string {
+	if label := strings.TrimSpace(username); label != "" {
+		return label
+	}
+	if fullName := strings.TrimSpace(fmt.Sprintf("%s %s", firstName, lastName)); fullName != "" {
+		return fullName
+	}
+	if phoneNumber != "" {
+		return phoneNumber
+	}
+	return id.String()
+}
@@ -0,0 +1,466 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+// customerLinkLockKey serializes linking so two orders of a new buyer do not create two customers.
+const customerLinkLockKey = 7041002
+
+type CustomerRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomerRepository(db *gorm.DB) domain.CustomerRepository {
+	return &CustomerRepo{db: db}
+}
+
+func (r *CustomerRepo) LinkOrder(ctx context.Context, orderID uuid.UUID) (*uuid.UUID, error) {
+	var customerID *uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", customerLinkLockKey).Error; err != nil {
+			return fmt.Errorf("failed to lock customer linking: %w", err)
+		}
+
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+		if order.CustomerID != nil {
+			customerID = order.CustomerID
+			return nil
+		}
+
+		phone := domain.NormalizePhone(order.CustomerPhone)
+		telegramID := order.CustomerTelegramID
+		if telegramID != nil && *telegramID == 0 {
+			telegramID = nil
+		}
+		if phone == "" && telegramID == nil {
+			return nil
+		}
+
+		customer, err := findCustomer(tx, phone, telegramID)
+		if err != nil {
+			return err
+		}
+
+		if customer == nil {
+			customer = &models.Customer{
+				Name:       order.CustomerName,
+				Phone:      phone,
+				Username:   strings.TrimPrefix(order.CustomerUsername, "@"),
+				TelegramID: telegramID,
+				Tags:       datatypes.JSON("[]"),
+			}
+			if err := tx.Create(customer).Error; err != nil {
+				return fmt.Errorf("failed to create customer: %w", err)
+			}
+		} else {
+			// Fill what the customer record is missing, never overwrite what staff already has.
+			updates := map[string]interface{}{}
+			if customer.Phone == "" && phone != "" {
+				updates["phone"] = phone
+			}
+			if customer.TelegramID == nil && telegramID != nil {
+				updates["telegram_id"] = *telegramID
+			}
+			if customer.Username == "" && order.CustomerUsername != "" {
+				updates["username"] = strings.TrimPrefix(order.CustomerUsername, "@")
+			}
+			if customer.Name == "" && order.CustomerName != "" {
+				updates["name"] = order.CustomerName
+			}
+			if len(updates) > 0 {
+				if err := tx.Model(customer).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to update customer: %w", err)
+				}
+			}
+		}
+
+		if err := tx.Model(&order).Update("customer_id", customer.ID).Error; err != nil {
+			return fmt.Errorf("failed to link order to customer: %w", err)
+		}
+		customerID = &customer.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customerID, nil
+}
+
+// maxMergeHops bounds the merged_into_id chain, a customer merged into one that was merged again.
+const maxMergeHops = 10
+
+// findCustomer matches by Telegram ID first, it is the stronger identity, then by phone. Merged duplicates keep
+// their identifiers, so when no live customer matches, a match on a merged one leads to the customer it
+// was merged into.
+func findCustomer(tx *gorm.DB, phone string, telegramID *int64) (*models.Customer, error) {
+	customer, err := matchCustomer(tx, false, phone, telegramID)
+	if err != nil || customer != nil {
+		return customer, err
+	}
+
+	merged, err := matchCustomer(tx, true, phone, telegramID)
+	if err != nil || merged == nil {
+		return merged, err
+	}
+	return followMerge(tx, merged)
+}
+
+// matchCustomer looks up live customers, or with merged only deleted duplicates that were merged.
+func matchCustomer(tx *gorm.DB, merged bool, phone string, telegramID *int64) (*models.Customer, error) {
+	query := func() *gorm.DB {
+		if merged {
+			return tx.Unscoped().Where("merged_into_id IS NOT NULL")
+		}
+		return tx
+	}
+
+	var customer models.Customer
+	if telegramID != nil {
+		err := query().Order("created_at ASC").First(&customer, "telegram_id = ?", *telegramID).Error
+		if err == nil {
+			return &customer, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find customer by telegram id: %w", err)
+		}
+	}
+	if phone != "" {
+		err := query().Order("created_at ASC").First(&customer, "phone = ?", phone).Error
+		if err == nil {
+			return &customer, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find customer by phone: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// followMerge walks merged_into_id to the surviving customer. A chain that ends in a customer deleted
+// without a merge yields nil, and a new customer is created.
+func followMerge(tx *gorm.DB, customer *models.Customer) (*models.Customer, error) {
+	for hop := 0; hop < maxMergeHops && customer.MergedIntoID != nil; hop++ {
+		var next models.Customer
+		if err := tx.Unscoped().First(&next, "id = ?", *customer.MergedIntoID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to follow merged customer: %w", err)
+		}
+		customer = &next
+	}
+	if customer.DeletedAt.Valid {
+		return nil, nil
+	}
+	return customer, nil
+}
+
+func (r *CustomerRepo) ListUnlinkedOrderIDs(ctx context.Context, after *uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("customer_id IS NULL AND (customer_phone <> '' OR customer_telegram_id IS NOT NULL)")
+	if after != nil {
+		query = query.Where("(created_at, id) > (SELECT created_at, id FROM orders WHERE id = ?)", *after)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Order("created_at ASC, id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list unlinked orders: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *CustomerRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.CustomerResponse, error) {
+	var customer models.Customer
+	if err := r.db.WithContext(ctx).First(&customer, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch customer: %w", err)
+	}
+
+	stats, err := loadCustomerStats(r.db.WithContext(ctx), []uuid.UUID{customer.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapCustomerModel(customer, stats[customer.ID])
+	return &response, nil
+}
+
+func (r *CustomerRepo) List(ctx context.Context, filter domain.CustomerFilter) ([]domain.CustomerResponse, int64, error) {
+	var customers []models.Customer
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Customer{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		conditions := "name ILIKE ? OR username ILIKE ?"
+		args := []interface{}{"%" + search + "%", "%" + strings.TrimPrefix(search, "@") + "%"}
+		if phone := domain.NormalizePhone(search); phone != "" {
+			conditions += " OR phone LIKE ?"
+			args = append(args, "%"+phone+"%")
+		}
+		query = query.Where(conditions, args...)
+	}
+	if tag := domain.NormalizeCustomerTag(filter.Tag); tag != "" {
+		encoded, _ := json.Marshal([]string{tag})
+		query = query.Where("tags @> ?::jsonb", string(encoded))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(filter.PageSize).Find(&customers).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch customers: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(customers))
+	for _, customer := range customers {
+		ids = append(ids, customer.ID)
+	}
+	stats, err := loadCustomerStats(r.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]domain.CustomerResponse, 0, len(customers))
+	for _, customer := range customers {
+		responses = append(responses, mapCustomerModel(customer, stats[customer.ID]))
+	}
+	return responses, total, nil
+}
+
+func (r *CustomerRepo) Update(ctx context.Context, id uuid.UUID, dto domain.UpdateCustomerDTO) error {
+	updates := map[string]interface{}{}
+	if dto.Name != nil {
+		updates["name"] = strings.TrimSpace(*dto.Name)
+	}
+	if dto.Notes != nil {
+		updates["notes"] = *dto.Notes
+	}
+	if dto.Tags != nil {
+		encoded, _ := json.Marshal(normalizeCustomerTags(*dto.Tags))
+		updates["tags"] = datatypes.JSON(encoded)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.Customer{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update customer: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCustomerNotFound
+	}
+	return nil
+}
+
+func (r *CustomerRepo) Merge(ctx context.Context, targetID, sourceID, userID uuid.UUID, comment string) error {
+	if targetID == sourceID {
+		return domain.ErrCustomerMergeSelf
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var customers []models.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{targetID, sourceID}).
+			Order("id").
+			Find(&customers).Error; err != nil {
+			return fmt.Errorf("failed to lock customers: %w", err)
+		}
+
+		var target, source *models.Customer
+		for i := range customers {
+			switch customers[i].ID {
+			case targetID:
+				target = &customers[i]
+			case sourceID:
+				source = &customers[i]
+			}
+		}
+		if target == nil || source == nil {
+			return domain.ErrCustomerNotFound
+		}
+
+		var movedOrders int64
+		result := tx.Model(&models.Order{}).Where("customer_id = ?", source.ID).Update("customer_id", target.ID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to move customer orders: %w", result.Error)
+		}
+		movedOrders = result.RowsAffected
+
+		updates := map[string]interface{}{}
+		if target.Name == "" && source.Name != "" {
+			updates["name"] = source.Name
+		}
+		if target.Phone == "" && source.Phone != "" {
+			updates["phone"] = source.Phone
+		}
+		if target.Username == "" && source.Username != "" {
+			updates["username"] = source.Username
+		}
+		if target.TelegramID == nil && source.TelegramID != nil {
+			updates["telegram_id"] = *source.TelegramID
+		}
+		if strings.TrimSpace(source.Notes) != "" {
+			notes := source.Notes
+			if strings.TrimSpace(target.Notes) != "" {
+				notes = target.Notes + "\n\n" + source.Notes
+			}
+			updates["notes"] = notes
+		}
+		var targetTags, sourceTags []string
+		_ = json.Unmarshal(target.Tags, &targetTags)
+		_ = json.Unmarshal(source.Tags, &sourceTags)
+		encodedTags, _ := json.Marshal(normalizeCustomerTags(append(targetTags, sourceTags...)))
+		updates["tags"] = datatypes.JSON(encodedTags)
+
+		if err := tx.Model(target).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update merged customer: %w", err)
+		}
+
+		if err := tx.Model(source).Update("merged_into_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to mark merged customer: %w", err)
+		}
+		if err := tx.Delete(source).Error; err != nil {
+			return fmt.Errorf("failed to delete merged customer: %w", err)
+		}
+
+		oldVal, _ := json.Marshal(map[string]interface{}{
+			"source_id":          source.ID,
+			"source_name":        source.Name,
+			"source_phone":       source.Phone,
+			"source_telegram_id": source.TelegramID,
+		})
+		newVal, _ := json.Marshal(map[string]interface{}{
+			"target_id":    target.ID,
+			"moved_orders": movedOrders,
+		})
+		auditLog := models.AuditLog{
+			Entity:   "CUSTOMER",
+			EntityID: target.ID,
+			UserID:   userID,
+			Action:   "MERGED",
+			OldValue: datatypes.JSON(oldVal),
+			NewValue: datatypes.JSON(newVal),
+			Comment:  comment,
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+type customerStatsRow struct {
+	CustomerID     uuid.UUID
+	OrdersCount    int64
+	PurchasesCount int64
+	Gross          float64
+	Refunded       float64
+	LastPurchaseAt *time.Time
+}
+
+// loadCustomerStats aggregates the orders of the customers. Refunds of returns on DONE orders reduce
+// the amount spent.
+func loadCustomerStats(db *gorm.DB, customerIDs []uuid.UUID) (map[uuid.UUID]domain.CustomerStats, error) {
+	stats := make(map[uuid.UUID]domain.CustomerStats, len(customerIDs))
+	if len(customerIDs) == 0 {
+		return stats, nil
+	}
+
+	var rows []customerStatsRow
+	if err := db.Raw(`
+		SELECT
+			orders.customer_id,
+			COUNT(*) AS orders_count,
+			COUNT(*) FILTER (WHERE orders.status = @done) AS purchases_count,
+			COALESCE(SUM(orders.total_amount) FILTER (WHERE orders.status = @done), 0) AS gross,
+			COALESCE(SUM(refunds.amount) FILTER (WHERE orders.status = @done), 0) AS refunded,
+			MAX(orders.created_at) FILTER (WHERE orders.status = @done) AS last_purchase_at
+		FROM orders
+		LEFT JOIN (
+			SELECT order_id, SUM(refund_amount) AS amount
+			FROM order_returns
+			WHERE deleted_at IS NULL
+			GROUP BY order_id
+		) refunds ON refunds.order_id = orders.id
+		WHERE orders.customer_id IN @ids AND orders.deleted_at IS NULL
+		GROUP BY orders.customer_id`,
+		map[string]interface{}{"done": string(domain.OrderStatusDone), "ids": customerIDs},
+	).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load customer stats: %w", err)
+	}
+
+	for _, row := range rows {
+		spent := roundKopecks(row.Gross - row.Refunded)
+		customerStats := domain.CustomerStats{
+			OrdersCount:    row.OrdersCount,
+			PurchasesCount: row.PurchasesCount,
+			TotalSpent:     spent,
+		}
+		if row.PurchasesCount > 0 {
+			customerStats.AverageOrderValue = roundKopecks(spent / float64(row.PurchasesCount))
+		}
+		if row.LastPurchaseAt != nil {
+			customerStats.LastPurchaseAt = row.LastPurchaseAt.Format("2006-01-02 15:04:05")
+		}
+		stats[row.CustomerID] = customerStats
+	}
+	return stats, nil
+}
+
+func normalizeCustomerTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = domain.NormalizeCustomerTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func mapCustomerModel(customer models.Customer, stats domain.CustomerStats) domain.CustomerResponse {
+	tags := []string{}
+	_ = json.Unmarshal(customer.Tags, &tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return domain.CustomerResponse{
+		ID:         customer.ID,
+		Name:       customer.Name,
+		Phone:      customer.Phone,
+		Username:   customer.Username,
+		TelegramID: customer.TelegramID,
+		Notes:      customer.Notes,
+		Tags:       tags,
+		Stats:      stats,
+		CreatedAt:  customer.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
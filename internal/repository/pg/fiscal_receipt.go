@@ -0,0 +1,277 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type FiscalReceiptRepo struct {
+	db *gorm.DB
+}
+
+func NewFiscalReceiptRepository(db *gorm.DB) domain.FiscalReceiptRepository {
+	return &FiscalReceiptRepo{db: db}
+}
+
+// Enqueue locks the order so a sale is queued once even when the order is paid and completed at the same time.
+func (r *FiscalReceiptRepo) Enqueue(ctx context.Context, record domain.CreateFiscalReceiptRecord) (*domain.FiscalReceiptResponse, bool, error) {
+	var receipt models.FiscalReceipt
+	created := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", record.OrderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		existing := tx.Where("order_id = ? AND type = ?", record.OrderID, string(record.Request.Type))
+		if record.OrderReturnID != nil {
+			existing = existing.Where("order_return_id = ?", *record.OrderReturnID)
+		}
+		err := existing.First(&receipt).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check fiscal receipt: %w", err)
+		}
+
+		request := record.Request
+		request.ID = uuid.New()
+		payload, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to encode fiscal receipt: %w", err)
+		}
+
+		receipt = models.FiscalReceipt{
+			OrderID:       record.OrderID,
+			OrderReturnID: record.OrderReturnID,
+			Type:          string(request.Type),
+			Status:        string(domain.FiscalReceiptPending),
+			Provider:      record.Provider,
+			Amount:        fiscalReceiptAmount(request),
+			Request:       datatypes.JSON(payload),
+			NextAttemptAt: time.Now(),
+		}
+		receipt.ID = request.ID
+		if err := tx.Create(&receipt).Error; err != nil {
+			return fmt.Errorf("failed to create fiscal receipt: %w", err)
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	response, err := mapFiscalReceiptModel(receipt)
+	if err != nil {
+		return nil, false, err
+	}
+	return response, created, nil
+}
+
+func (r *FiscalReceiptRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.FiscalReceiptResponse, error) {
+	var receipt models.FiscalReceipt
+	if err := r.db.WithContext(ctx).First(&receipt, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch fiscal receipt: %w", err)
+	}
+	return mapFiscalReceiptModel(receipt)
+}
+
+// GetSale returns nil when the order has no sale receipt.
+func (r *FiscalReceiptRepo) GetSale(ctx context.Context, orderID uuid.UUID) (*domain.FiscalReceiptResponse, error) {
+	var receipt models.FiscalReceipt
+	if err := r.db.WithContext(ctx).
+		First(&receipt, "order_id = ? AND type = ?", orderID, string(domain.FiscalReceiptSale)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch sale receipt: %w", err)
+	}
+	return mapFiscalReceiptModel(receipt)
+}
+
+func (r *FiscalReceiptRepo) List(ctx context.Context, filter domain.FiscalReceiptFilter) ([]domain.FiscalReceiptResponse, int64, error) {
+	var receipts []models.FiscalReceipt
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.FiscalReceipt{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", string(filter.Status))
+	}
+	if filter.OrderID != nil {
+		query = query.Where("order_id = ?", *filter.OrderID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count fiscal receipts: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(filter.PageSize).Find(&receipts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch fiscal receipts: %w", err)
+	}
+
+	responses, err := mapFiscalReceiptModels(receipts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return responses, total, nil
+}
+
+func (r *FiscalReceiptRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.FiscalReceiptResponse, error) {
+	var receipts []models.FiscalReceipt
+	if err := r.db.WithContext(ctx).
+		Where("status IN ? AND next_attempt_at <= ?", []string{string(domain.FiscalReceiptPending), string(domain.FiscalReceiptProcessing)}, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&receipts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list due fiscal receipts: %w", err)
+	}
+	return mapFiscalReceiptModels(receipts)
+}
+
+func (r *FiscalReceiptRepo) SaveAttempt(ctx context.Context, id uuid.UUID, attempt domain.FiscalAttempt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var receipt models.FiscalReceipt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&receipt, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("fiscal receipt not found or locked: %w", err)
+		}
+
+		updates := map[string]interface{}{
+			"status":     string(attempt.Status),
+			"attempts":   receipt.Attempts + 1,
+			"last_error": truncateFiscalError(attempt.Error),
+		}
+		if attempt.ProviderRef != "" {
+			updates["provider_ref"] = attempt.ProviderRef
+		}
+		if !attempt.NextAttemptAt.IsZero() {
+			updates["next_attempt_at"] = attempt.NextAttemptAt
+		}
+		if attempt.Status == domain.FiscalReceiptDone {
+			updates["fiscal_code"] = attempt.FiscalCode
+			updates["fiscalized_at"] = time.Now()
+		}
+
+		if err := tx.Model(&receipt).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update fiscal receipt: %w", err)
+		}
+
+		if attempt.Status != domain.FiscalReceiptDone {
+			return nil
+		}
+
+		// Keep the fiscal number next to the document it belongs to.
+		if receipt.OrderReturnID != nil {
+			if err := tx.Model(&models.OrderReturn{}).
+				Where("id = ?", *receipt.OrderReturnID).
+				Update("fiscal_code", attempt.FiscalCode).Error; err != nil {
+				return fmt.Errorf("failed to store fiscal code on return: %w", err)
+			}
+			return nil
+		}
+		if err := tx.Model(&models.Order{}).
+			Where("id = ?", receipt.OrderID).
+			Update("fiscal_code", attempt.FiscalCode).Error; err != nil {
+			return fmt.Errorf("failed to store fiscal code on order: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *FiscalReceiptRepo) Postpone(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.FiscalReceipt{}).
+		Where("id = ?", id).
+		Update("next_attempt_at", nextAttemptAt).Error; err != nil {
+		return fmt.Errorf("failed to postpone fiscal receipt: %w", err)
+	}
+	return nil
+}
+
+func (r *FiscalReceiptRepo) Requeue(ctx context.Context, id uuid.UUID) (*domain.FiscalReceiptResponse, error) {
+	result := r.db.WithContext(ctx).Model(&models.FiscalReceipt{}).
+		Where("id = ? AND status = ?", id, string(domain.FiscalReceiptFailed)).
+		Updates(map[string]interface{}{
+			"status":          string(domain.FiscalReceiptPending),
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to requeue fiscal receipt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrFiscalReceiptNotRetryable
+	}
+	return r.GetByID(ctx, id)
+}
+
+func fiscalReceiptAmount(request domain.FiscalReceiptRequest) float64 {
+	var amount float64
+	for _, payment := range request.Payments {
+		amount += payment.Amount
+	}
+	return roundKopecks(amount)
+}
+
+func truncateFiscalError(message string) string {
+	runes := []rune(message)
+	if len(runes) > 500 {
+		return string(runes[:500])
+	}
+	return message
+}
+
+func mapFiscalReceiptModels(receipts []models.FiscalReceipt) ([]domain.FiscalReceiptResponse, error) {
+	responses := make([]domain.FiscalReceiptResponse, 0, len(receipts))
+	for _, receipt := range receipts {
+		response, err := mapFiscalReceiptModel(receipt)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+func mapFiscalReceiptModel(receipt models.FiscalReceipt) (*domain.FiscalReceiptResponse, error) {
+	var request domain.FiscalReceiptRequest
+	if err := json.Unmarshal(receipt.Request, &request); err != nil {
+		return nil, fmt.Errorf("failed to decode fiscal receipt %s: %w", receipt.ID, err)
+	}
+
+	response := &domain.FiscalReceiptResponse{
+		ID:            receipt.ID,
+		OrderID:       receipt.OrderID,
+		OrderReturnID: receipt.OrderReturnID,
+		Type:          domain.FiscalReceiptType(receipt.Type),
+		Status:        domain.FiscalReceiptStatus(receipt.Status),
+		Provider:      receipt.Provider,
+		Amount:        receipt.Amount,
+		ProviderRef:   receipt.ProviderRef,
+		FiscalCode:    receipt.FiscalCode,
+		Attempts:      receipt.Attempts,
+		LastError:     receipt.LastError,
+		CreatedAt:     receipt.CreatedAt.Format("2006-01-02 15:04:05"),
+		Request:       request,
+	}
+	if response.Status == domain.FiscalReceiptPending || response.Status == domain.FiscalReceiptProcessing {
+		response.NextAttemptAt = receipt.NextAttemptAt.Format("2006-01-02 15:04:05")
+	}
+	if receipt.FiscalizedAt != nil {
+		response.FiscalizedAt = receipt.FiscalizedAt.Format("2006-01-02 15:04:05")
+	}
+	return response, nil
+}
@@ -34,12 +34,26 @@ func (r *LotRepo) Create(ctx context.Context, dto *domain.CreateLotDTO) (uuid.UU
 		return uuid.Nil, fmt.Errorf("failed to marshal lot params: %w", err)
 	}
 
+	if dto.LocationID != nil {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&models.WarehouseLocation{}).
+			Where("id = ? AND warehouse_id = ?", *dto.LocationID, dto.WarehouseID).
+			Count(&count).Error; err != nil {
+			return uuid.Nil, fmt.Errorf("failed to check lot location: %w", err)
+		}
+		if count == 0 {
+			return uuid.Nil, fmt.Errorf("location does not belong to warehouse %s", dto.WarehouseID)
+		}
+	}
+
 	dbModel := models.Lot{
 		WarehouseID:     dto.WarehouseID,
+		LocationID:      dto.LocationID,
 		Type:            models.LotType(dto.Type),
 		Condition:       models.LotCondition(dto.Condition),
 		Brand:           dto.Brand,
 		Model:           dto.Model,
+		Barcode:         dto.Barcode,
 		Params:          datatypes.JSON(paramsBytes),
 		Defects:         dto.Defects,
 		Photos:          dto.Photos,
@@ -57,7 +71,7 @@ func (r *LotRepo) Create(ctx context.Context, dto *domain.CreateLotDTO) (uuid.UU
 	return dbModel.ID, nil
 }
 
-func (r *LotRepo) Update(ctx context.Context, id uuid.UUID, dto *domain.UpdateLotDTO) error {
+func (r *LotRepo) Update(ctx context.Context, id uuid.UUID, dto *domain.UpdateLotDTO, userID uuid.UUID) error {
 	updates := map[string]interface{}{}
 
 	if dto.WarehouseID != nil {
@@ -75,6 +89,9 @@ func (r *LotRepo) Update(ctx context.Context, id uuid.UUID, dto *domain.UpdateLo
 	if dto.Model != nil {
 		updates["model"] = *dto.Model
 	}
+	if dto.Barcode != nil {
+		updates["barcode"] = *dto.Barcode
+	}
 	if dto.Params != nil {
 		paramsBytes, err := json.Marshal(dto.Params)
 		if err != nil {
@@ -99,15 +116,38 @@ func (r *LotRepo) Update(ctx context.Context, id uuid.UUID, dto *domain.UpdateLo
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).Model(&models.Lot{}).Where("id = ?", id).Updates(updates)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update lot: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("lot not found")
-	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lot models.Lot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("lot not found: %w", err)
+		}
 
-	return nil
+		// Bins belong to a single warehouse, so the placement is dropped when the lot leaves it
+		// and the move is recorded like any other.
+		if dto.WarehouseID != nil && *dto.WarehouseID != lot.WarehouseID && lot.LocationID != nil {
+			updates["location_id"] = nil
+
+			move := models.LotLocationMove{
+				LotID:          lot.ID,
+				FromLocationID: lot.LocationID,
+				MovedByID:      userID,
+				Comment:        "moved to another warehouse",
+			}
+			var from models.WarehouseLocation
+			if err := tx.Unscoped().First(&from, "id = ?", *lot.LocationID).Error; err == nil {
+				move.FromPath = from.Path
+			}
+			if err := tx.Create(&move).Error; err != nil {
+				return fmt.Errorf("failed to record lot move: %w", err)
+			}
+		}
+
+		if err := tx.Model(&lot).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update lot: %w", err)
+		}
+
+		return nil
+	})
 }
 
 func (r *LotRepo) Delete(ctx context.Context, id uuid.UUID) error {
@@ -141,9 +181,14 @@ func (r *LotRepo) ListPublic(ctx context.Context, filter domain.LotFilter) ([]do
 		return nil, 0, fmt.Errorf("failed to fetch public lots: %w", err)
 	}
 
+	photos, err := loadPhotoAssets(ctx, r.db, lotPhotoURLs(dbModels))
+	if err != nil {
+		return nil, 0, err
+	}
+
 	responses := make([]domain.LotPublicResponse, 0, len(dbModels))
 	for _, m := range dbModels {
-		responses = append(responses, mapToPublicResponse(m))
+		responses = append(responses, mapToPublicResponse(m, photos))
 	}
 
 	return responses, total, nil
@@ -158,6 +203,19 @@ func (r *LotRepo) ListInternal(ctx context.Context, filter domain.LotFilter) ([]
 	if filter.Status != "" {
 		query = query.Where("status = ?", filter.Status)
 	}
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.LocationID != "" {
+		query = query.Where(`location_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM warehouse_locations WHERE id = ? AND deleted_at IS NULL
+				UNION ALL
+				SELECT wl.id FROM warehouse_locations wl JOIN tree ON wl.parent_id = tree.id WHERE wl.deleted_at IS NULL
+			)
+			SELECT id FROM tree
+		)`, filter.LocationID)
+	}
 	query = applyFilters(query, filter)
 
 	if err := query.Count(&total).Error; err != nil {
@@ -171,20 +229,144 @@ func (r *LotRepo) ListInternal(ctx context.Context, filter domain.LotFilter) ([]
 		return nil, 0, fmt.Errorf("failed to fetch internal lots: %w", err)
 	}
 
+	relations, err := r.loadLotRelations(ctx, dbModels)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	responses := make([]domain.LotInternalResponse, 0, len(dbModels))
 	for _, m := range dbModels {
-		responses = append(responses, domain.LotInternalResponse{
-			LotPublicResponse: mapToPublicResponse(m),
-			WarehouseID:       m.WarehouseID,
-			InitialQty:        m.InitialQuantity,
-			PurchasePrice:     m.PurchasePrice,
-			Status:            m.Status,
-		})
+		responses = append(responses, mapToInternalResponse(m, relations))
 	}
 
 	return responses, total, nil
 }
 
+// GetBySKU finds the lot whose ID starts with the prefix encoded in our own SKU. The prefix is turned into
+// a range of UUIDs so the primary key index is used. Two lots sharing the prefix are reported as ambiguous
+// rather than picking one of them.
+func (r *LotRepo) GetBySKU(ctx context.Context, sku string) (*domain.LotInternalResponse, error) {
+	prefix, ok := domain.ParseLotSKU(sku)
+	if !ok {
+		return nil, fmt.Errorf("invalid sku: %s", sku)
+	}
+
+	var dbModels []models.Lot
+	if err := r.db.WithContext(ctx).
+		Where("id BETWEEN ? AND ?", prefix+"-0000-0000-000000000000", prefix+"-ffff-ffff-ffffffffffff").
+		Limit(2).
+		Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lot by sku: %w", err)
+	}
+	switch len(dbModels) {
+	case 0:
+		return nil, fmt.Errorf("lot not found: %w", gorm.ErrRecordNotFound)
+	case 2:
+		return nil, domain.ErrAmbiguousSKU
+	}
+	dbModel := dbModels[0]
+
+	relations, err := r.loadLotRelations(ctx, []models.Lot{dbModel})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToInternalResponse(dbModel, relations)
+	return &response, nil
+}
+
+// ListByBarcode returns every lot with the EAN across all warehouses, newest first.
+func (r *LotRepo) ListByBarcode(ctx context.Context, barcode string) ([]domain.LotInternalResponse, error) {
+	var dbModels []models.Lot
+	if err := r.db.WithContext(ctx).
+		Where("barcode = ?", barcode).
+		Order("created_at DESC").
+		Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lots by barcode: %w", err)
+	}
+
+	relations, err := r.loadLotRelations(ctx, dbModels)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]domain.LotInternalResponse, 0, len(dbModels))
+	for _, m := range dbModels {
+		responses = append(responses, mapToInternalResponse(m, relations))
+	}
+
+	return responses, nil
+}
+
+func (r *LotRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.LotInternalResponse, error) {
+	var dbModel models.Lot
+	if err := r.db.WithContext(ctx).First(&dbModel, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("lot not found: %w", err)
+	}
+
+	relations, err := r.loadLotRelations(ctx, []models.Lot{dbModel})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToInternalResponse(dbModel, relations)
+	return &response, nil
+}
+
+// lotRelations holds data joined to lots from other tables.
+type lotRelations struct {
+	locations map[uuid.UUID]*domain.WarehouseLocation
+	photos    map[string]domain.PhotoAsset
+}
+
+func (r *LotRepo) loadLotRelations(ctx context.Context, lots []models.Lot) (lotRelations, error) {
+	locations, err := r.loadLotLocations(ctx, lots)
+	if err != nil {
+		return lotRelations{}, err
+	}
+
+	photos, err := loadPhotoAssets(ctx, r.db, lotPhotoURLs(lots))
+	if err != nil {
+		return lotRelations{}, err
+	}
+
+	return lotRelations{locations: locations, photos: photos}, nil
+}
+
+func lotPhotoURLs(lots []models.Lot) []string {
+	var urls []string
+	for _, lot := range lots {
+		urls = append(urls, lot.Photos...)
+	}
+	return urls
+}
+
+// loadLotLocations fetches the locations of the given lots in a single query.
+func (r *LotRepo) loadLotLocations(ctx context.Context, lots []models.Lot) (map[uuid.UUID]*domain.WarehouseLocation, error) {
+	ids := make([]uuid.UUID, 0, len(lots))
+	for _, lot := range lots {
+		if lot.LocationID != nil {
+			ids = append(ids, *lot.LocationID)
+		}
+	}
+
+	result := make(map[uuid.UUID]*domain.WarehouseLocation, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var locations []models.WarehouseLocation
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lot locations: %w", err)
+	}
+
+	for _, location := range locations {
+		result[location.ID] = mapToDomainWarehouseLocation(location)
+	}
+
+	return result, nil
+}
+
 func (r *LotRepo) ListSuggestions(ctx context.Context, filter domain.LotFilter, internal bool, limit int) ([]string, error) {
 	if limit <= 0 {
 		limit = 8
@@ -474,13 +656,7 @@ func suggestionScore(value string, valueLower string, queryLower string, freeTex
 }
 
 func buildSuggestionSizeLabel(params domain.LotParams, lotType models.LotType) string {
-	if params.Width > 0 && params.Profile > 0 && params.Diameter > 0 {
-		return fmt.Sprintf("%s/%s R%s", formatNumericParam(params.Width), formatNumericParam(params.Profile), formatNumericParam(params.Diameter))
-	}
-	if lotType == models.LotTypeRim && params.Diameter > 0 {
-		return fmt.Sprintf("R%s", formatNumericParam(params.Diameter))
-	}
-	return ""
+	return params.SizeLabel(string(lotType))
 }
 
 func applySorting(query *gorm.DB, filter domain.LotFilter) *gorm.DB {
@@ -669,6 +845,10 @@ func applyFilters(query *gorm.DB, filter domain.LotFilter) *gorm.DB {
 				numberText := strconv.Itoa(numberValue)
 				args = append(args, numberText, numberText, numberText, numberText)
 			}
+			if domain.IsEAN(parsed.freeText) {
+				orClauses = append(orClauses, "barcode = ?")
+				args = append(args, parsed.freeText)
+			}
 
 			query = query.Where("("+strings.Join(orClauses, " OR ")+")", args...)
 		}
@@ -765,7 +945,25 @@ func applyFilters(query *gorm.DB, filter domain.LotFilter) *gorm.DB {
 	return query
 }
 
-func mapToPublicResponse(m models.Lot) domain.LotPublicResponse {
+func mapToInternalResponse(m models.Lot, relations lotRelations) domain.LotInternalResponse {
+	var location *domain.WarehouseLocation
+	if m.LocationID != nil {
+		location = relations.locations[*m.LocationID]
+	}
+
+	return domain.LotInternalResponse{
+		LotPublicResponse: mapToPublicResponse(m, relations.photos),
+		WarehouseID:       m.WarehouseID,
+		Location:          location,
+		SKU:               domain.LotSKU(m.ID),
+		Barcode:           m.Barcode,
+		InitialQty:        m.InitialQuantity,
+		PurchasePrice:     m.PurchasePrice,
+		Status:            m.Status,
+	}
+}
+
+func mapToPublicResponse(m models.Lot, photos map[string]domain.PhotoAsset) domain.LotPublicResponse {
 	var params domain.LotParams
 	if len(m.Params) > 0 {
 		_ = json.Unmarshal(m.Params, &params)
@@ -780,6 +978,7 @@ func mapToPublicResponse(m models.Lot) domain.LotPublicResponse {
 		Params:          params,
 		Defects:         m.Defects,
 		Photos:          m.Photos,
+		Images:          photoImages(m.Photos, photos),
 		CurrentQuantity: m.CurrentQuantity,
 		SellPrice:       m.SellPrice,
 	}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -96,7 +98,25 @@ func (r *OrderRepo) CreateOrderTx(ctx context.Context, dto domain.CreateOrderDTO
 			totalAmount += priceAtMoment * float64(item.Quantity)
 		}
 
-		// 6. Create the main Order record
+		// 6. Apply the promo code, spreading the discount over the items
+		var promoCodeID *uuid.UUID
+		var promoCode string
+		var discountAmount float64
+		if dto.PromoCode != "" {
+			promo, err := redeemPromoCode(tx, dto.PromoCode, userID, dto.CustomerPhone)
+			if err != nil {
+				return err
+			}
+			discountAmount, err = applyPromoDiscount(tx, promo, orderItems)
+			if err != nil {
+				return err
+			}
+			promoCodeID = &promo.ID
+			promoCode = promo.Code
+			totalAmount -= discountAmount
+		}
+
+		// 7. Create the main Order record
 		order := models.Order{
 			UserID:             userID,
 			CustomerName:       dto.CustomerName,
@@ -104,8 +124,11 @@ func (r *OrderRepo) CreateOrderTx(ctx context.Context, dto domain.CreateOrderDTO
 			CustomerUsername:   dto.CustomerUsername,
 			CustomerTelegramID: dto.CustomerTelegramID,
 			Channel:            string(dto.Channel),
-			Status:             "NEW",
+			Status:             string(domain.OrderStatusNew),
 			TotalAmount:        totalAmount,
+			PromoCodeID:        promoCodeID,
+			PromoCode:          promoCode,
+			DiscountAmount:     discountAmount,
 			Items:              orderItems, // GORM will automatically insert these related items
 		}
 
@@ -141,61 +164,124 @@ func (r *OrderRepo) CreateOrderTx(ctx context.Context, dto domain.CreateOrderDTO
 	return orderID, nil
 }
 
-// UpdateStatus changes the status of an existing order and records an Audit Log atomically.
-func (r *OrderRepo) UpdateStatus(ctx context.Context, id uuid.UUID, newStatus string, userID uuid.UUID, comment string) error {
-	// Start transaction
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// UpdateStatus moves an order along the state machine and records an Audit Log atomically.
+func (r *OrderRepo) UpdateStatus(ctx context.Context, id uuid.UUID, newStatus domain.OrderStatus, userID uuid.UUID, role domain.UserRole, comment string, overrideBalance bool) (*domain.OrderTransition, error) {
+	return r.changeStatus(ctx, id, newStatus, userID, role, comment, overrideBalance, nil)
+}
+
+func (r *OrderRepo) CancelByBuyer(ctx context.Context, id, buyerID uuid.UUID, reason string, policy domain.BuyerCancelPolicy) (*domain.OrderTransition, error) {
+	return r.changeStatus(ctx, id, domain.OrderStatusCancelled, buyerID, domain.RoleBuyer, reason, false, func(tx *gorm.DB, order models.Order) error {
+		if order.UserID == nil || *order.UserID != buyerID {
+			return domain.ErrOrderNotFound
+		}
+		if !policy.Allows(domain.OrderStatus(order.Status), order.CreatedAt, time.Now()) {
+			return fmt.Errorf("%w: status %s", domain.ErrOrderCancelNotAllowed, order.Status)
+		}
+
+		// Money already taken, e.g. cash or an early payment, has to be refunded by staff.
+		balance, err := loadOrderBalance(tx, order)
+		if err != nil {
+			return err
+		}
+		if balance.PaidAmount >= 0.005 {
+			return fmt.Errorf("%w: order has payments of %.2f", domain.ErrOrderCancelNotAllowed, balance.PaidAmount)
+		}
+
+		// Close open links so the buyer cannot pay for the cancelled order.
+		if err := tx.Model(&models.Payment{}).
+			Where("order_id = ? AND status = ?", order.ID, string(domain.PaymentStatusPending)).
+			Updates(map[string]interface{}{
+				"status":         string(domain.PaymentStatusExpired),
+				"failure_reason": domain.PaymentReasonOrderCancelled,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to expire payment links: %w", err)
+		}
+		return nil
+	})
+}
+
+// changeStatus applies a status change under the order row lock. check, when set, vets the locked order
+// in the same transaction before anything else so callers can add their own preconditions and side effects.
+func (r *OrderRepo) changeStatus(ctx context.Context, id uuid.UUID, newStatus domain.OrderStatus, userID uuid.UUID, role domain.UserRole, comment string, overrideBalance bool, check func(*gorm.DB, models.Order) error) (*domain.OrderTransition, error) {
+	var applied *domain.OrderTransition
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var order models.Order
 
 		// 1. Lock the order row and Preload Items for potential restocking
 		if err := tx.Preload("Items").Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrOrderNotFound
+			}
 			return fmt.Errorf("order not found or locked: %w", err)
 		}
+		if check != nil {
+			if err := check(tx, order); err != nil {
+				return err
+			}
+		}
 
-		oldStatus := order.Status
+		oldStatus := domain.OrderStatus(order.Status)
 
 		// If status is the same, do nothing
 		if oldStatus == newStatus {
 			return nil
 		}
 
-		// 2. Handle Stock Logic for Cancellations
-		if newStatus == "CANCELLED" && oldStatus != "CANCELLED" {
-			// Return items to stock
-			for _, item := range order.Items {
-				var lot models.Lot
-				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, "id = ?", item.LotID).Error; err != nil {
-					return fmt.Errorf("lot %s not found during restocking: %w", item.LotID, err)
-				}
+		// 2. Validate against the state machine while the row is locked
+		transition, err := domain.CheckOrderTransition(oldStatus, newStatus, role)
+		if err != nil {
+			return fmt.Errorf("%w: %s -> %s", err, oldStatus, newStatus)
+		}
 
-				lot.CurrentQuantity += item.Quantity
-				// Reactivate lot if it was archived due to 0 stock
-				if lot.CurrentQuantity > 0 && lot.Status == "ARCHIVED" {
-					lot.Status = "ACTIVE"
+		// 3. Completed orders must be paid in full, admins may close them anyway
+		var balanceOverride *float64
+		if newStatus == domain.OrderStatusDone {
+			balance, err := loadOrderBalance(tx, order)
+			if err != nil {
+				return err
+			}
+			if !balance.IsSettled() {
+				if !overrideBalance {
+					return fmt.Errorf("%w: balance is %.2f", domain.ErrOrderBalanceOutstanding, balance.Balance)
 				}
-
-				if err := tx.Save(&lot).Error; err != nil {
-					return fmt.Errorf("failed to restock lot %s: %w", lot.ID, err)
+				if role != domain.RoleAdmin {
+					return fmt.Errorf("%w: only admins can complete an order with an unsettled balance", domain.ErrOrderTransitionForbidden)
 				}
+				balanceOverride = &balance.Balance
 			}
 		}
 
-		// Prevent re-opening a cancelled order for safety (simplified logic)
-		if oldStatus == "CANCELLED" && newStatus != "CANCELLED" {
-			return fmt.Errorf("cannot reopen a cancelled order directly, please create a new order")
+		// 4. Return items to stock for cancellations and returns
+		if transition.Restock {
+			if err := restockOrderItems(tx, order.Items); err != nil {
+				return err
+			}
 		}
 
-		// 3. Update the order
-		order.Status = newStatus
+		// 5. Update the order. Staff moving the order on stops its SLA timers.
+		order.Status = string(newStatus)
+		now := time.Now()
+		staffReacted := oldStatus == domain.OrderStatusNew && role != domain.RoleSystem
+		if (staffReacted || newStatus.EndsProcessing()) && order.FirstRespondedAt == nil {
+			order.FirstRespondedAt = &now
+		}
+		if newStatus.EndsProcessing() && order.ProcessedAt == nil {
+			order.ProcessedAt = &now
+		}
 		if err := tx.Save(&order).Error; err != nil {
 			return fmt.Errorf("failed to update order: %w", err)
 		}
 
-		// 4. Prepare old and new values for the Audit Log (as JSON)
-		oldVal, _ := json.Marshal(map[string]string{"status": oldStatus})
-		newVal, _ := json.Marshal(map[string]string{"status": newStatus})
+		// 6. Prepare old and new values for the Audit Log (as JSON)
+		oldVal, _ := json.Marshal(map[string]string{"status": string(oldStatus)})
+		newValues := map[string]interface{}{"status": string(newStatus)}
+		if balanceOverride != nil {
+			newValues["balance_override"] = *balanceOverride
+		}
+		newVal, _ := json.Marshal(newValues)
 
-		// 5. Create the Audit Log record
+		// 7. Create the Audit Log record
 		auditLog := models.AuditLog{
 			Entity:   "ORDER",
 			EntityID: order.ID,
@@ -206,13 +292,29 @@ func (r *OrderRepo) UpdateStatus(ctx context.Context, id uuid.UUID, newStatus st
 			Comment:  comment,
 		}
 
-		// 6. Save the Audit Log
+		// 8. Save the Audit Log
 		if err := tx.Create(&auditLog).Error; err != nil {
 			return fmt.Errorf("failed to write audit log: %w", err)
 		}
 
+		applied = &transition
 		return nil // Commit transaction
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// restockOrderItems returns the order items to their lots.
+func restockOrderItems(tx *gorm.DB, items []models.OrderItem) error {
+	for _, item := range items {
+		if _, err := restockLot(tx, item.LotID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *OrderRepo) UpdateItemPrice(ctx context.Context, orderID, itemID, userID uuid.UUID, price float64, comment string) error {
@@ -225,8 +327,8 @@ func (r *OrderRepo) UpdateItemPrice(ctx context.Context, orderID, itemID, userID
 		if order.Channel != string(domain.OrderChannelOffline) {
 			return fmt.Errorf("price override is allowed only for offline orders")
 		}
-		if order.Status == "CANCELLED" {
-			return fmt.Errorf("cannot change price for a cancelled order")
+		if domain.OrderStatus(order.Status).IsFinal() {
+			return fmt.Errorf("cannot change price for a %s order", strings.ToLower(order.Status))
 		}
 
 		var orderItem models.OrderItem
@@ -251,7 +353,7 @@ func (r *OrderRepo) UpdateItemPrice(ctx context.Context, orderID, itemID, userID
 			if item.ID == orderItem.ID {
 				itemPrice = price
 			}
-			totalAmount += itemPrice * float64(item.Quantity)
+			totalAmount += itemPrice*float64(item.Quantity) - item.DiscountAmount
 		}
 
 		order.TotalAmount = totalAmount
@@ -292,32 +394,13 @@ func (r *OrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.OrderRes
 		return nil, fmt.Errorf("failed to fetch order: %w", err)
 	}
 
-	var items []domain.OrderItemResponse
-	for _, item := range order.Items {
-		items = append(items, domain.OrderItemResponse{
-			ID:       item.ID,
-			LotID:    item.LotID,
-			Brand:    item.Brand,
-			Model:    item.Model,
-			Photo:    item.Photo,
-			Quantity: item.Quantity,
-			Price:    item.PriceAtMoment,
-			Total:    item.PriceAtMoment * float64(item.Quantity),
-		})
+	balances, err := loadOrderBalances(r.db.WithContext(ctx), []models.Order{order})
+	if err != nil {
+		return nil, err
 	}
 
-	return &domain.OrderResponse{
-		ID:                 order.ID,
-		CustomerName:       order.CustomerName,
-		CustomerPhone:      order.CustomerPhone,
-		CustomerUsername:   order.CustomerUsername,
-		CustomerTelegramID: order.CustomerTelegramID,
-		Channel:            domain.OrderChannel(order.Channel),
-		Status:             order.Status,
-		TotalAmount:        order.TotalAmount,
-		CreatedAt:          order.CreatedAt.Format("2006-01-02 15:04:05"),
-		Items:              items,
-	}, nil
+	response := mapOrderModel(order, balances[order.ID])
+	return &response, nil
 }
 
 func (r *OrderRepo) CreateMessage(ctx context.Context, dto domain.CreateOrderMessageDTO) (*domain.OrderMessage, error) {
@@ -412,49 +495,144 @@ func (r *OrderRepo) List(ctx context.Context, filter domain.OrderFilter) ([]doma
 	if filter.Customer != "" {
 		query = query.Where("customer_name ILIKE ? OR customer_phone ILIKE ?", "%"+filter.Customer+"%", "%"+filter.Customer+"%")
 	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.Unassigned {
+		query = query.Where("assignee_id IS NULL")
+	}
+	if filter.Overdue {
+		now := time.Now()
+		query = query.Where(
+			"(first_responded_at IS NULL AND first_response_due_at <= ?) OR (processed_at IS NULL AND processing_due_at <= ?)",
+			now, now,
+		)
+	}
+	query = applyOrderFilters(query, filter)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
 	}
 
 	offset := (filter.Page - 1) * filter.PageSize
-	if err := query.Order("created_at DESC").Offset(offset).Limit(filter.PageSize).Find(&dbOrders).Error; err != nil {
+	if err := applyOrderSorting(query, filter).Offset(offset).Limit(filter.PageSize).Find(&dbOrders).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to fetch orders: %w", err)
 	}
 
+	balances, err := loadOrderBalances(r.db.WithContext(ctx), dbOrders)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var responses []domain.OrderResponse
 	for _, order := range dbOrders {
-		var items []domain.OrderItemResponse
-		for _, item := range order.Items {
-			items = append(items, domain.OrderItemResponse{
-				ID:       item.ID,
-				LotID:    item.LotID,
-				Brand:    item.Brand,
-				Model:    item.Model,
-				Photo:    item.Photo,
-				Quantity: item.Quantity,
-				Price:    item.PriceAtMoment,
-				Total:    item.PriceAtMoment * float64(item.Quantity),
-			})
-		}
-
-		responses = append(responses, domain.OrderResponse{
-			ID:                 order.ID,
-			CustomerName:       order.CustomerName,
-			CustomerPhone:      order.CustomerPhone,
-			CustomerUsername:   order.CustomerUsername,
-			CustomerTelegramID: order.CustomerTelegramID,
-			Channel:            domain.OrderChannel(order.Channel),
-			Status:             order.Status,
-			TotalAmount:        order.TotalAmount,
-			CreatedAt:          order.CreatedAt.Format("2006-01-02 15:04:05"),
-			Items:              items,
-		})
+		responses = append(responses, mapOrderModel(order, balances[order.ID]))
 	}
 
 	return responses, total, nil
 }
 
+// orderPaidSQL and orderDueSQL compute the balance of an order in SQL, the same way loadOrderBalances does.
+var (
+	orderPaidSQL = fmt.Sprintf(
+		"COALESCE((SELECT SUM(CASE WHEN e.type = '%s' THEN -e.amount ELSE e.amount END) FROM order_ledger_entries e WHERE e.order_id = orders.id AND e.deleted_at IS NULL), 0)",
+		domain.LedgerEntryRefund,
+	)
+	orderDueSQL = fmt.Sprintf(
+		"(CASE WHEN orders.status IN ('%s', '%s') THEN 0 ELSE orders.total_amount - COALESCE((SELECT SUM(r.refund_amount) FROM order_returns r WHERE r.order_id = orders.id AND r.deleted_at IS NULL), 0) END)",
+		domain.OrderStatusCancelled, domain.OrderStatusReturned,
+	)
+)
+
+// applyOrderFilters adds the date, amount, creator, payment and item filters of the staff order search.
+func applyOrderFilters(query *gorm.DB, filter domain.OrderFilter) *gorm.DB {
+	if filter.StartDate != nil {
+		query = query.Where("orders.created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("orders.created_at <= ?", *filter.EndDate)
+	}
+	if filter.MinTotal != nil {
+		query = query.Where("orders.total_amount >= ?", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		query = query.Where("orders.total_amount <= ?", *filter.MaxTotal)
+	}
+	if filter.CreatedBy != nil {
+		query = query.Where("orders.user_id = ?", *filter.CreatedBy)
+	}
+
+	balance := orderDueSQL + " - " + orderPaidSQL
+	switch filter.PaymentState {
+	case domain.OrderPaymentUnpaid:
+		query = query.Where(balance + " >= 0.005 AND " + orderPaidSQL + " < 0.005")
+	case domain.OrderPaymentPartial:
+		query = query.Where(balance + " >= 0.005 AND " + orderPaidSQL + " >= 0.005")
+	case domain.OrderPaymentPaid:
+		query = query.Where("ABS(" + balance + ") < 0.005 AND " + orderPaidSQL + " >= 0.005")
+	case domain.OrderPaymentRefundDue:
+		query = query.Where(balance + " <= -0.005")
+	}
+
+	var itemConditions []string
+	var itemArgs []interface{}
+	if filter.LotID != nil {
+		itemConditions = append(itemConditions, "oi.lot_id = ?")
+		itemArgs = append(itemArgs, *filter.LotID)
+	}
+	if filter.Brand != "" {
+		itemConditions = append(itemConditions, "oi.brand ILIKE ?")
+		itemArgs = append(itemArgs, "%"+filter.Brand+"%")
+	}
+	if filter.Width > 0 {
+		itemConditions = append(itemConditions, "l.params->>'width' = ?")
+		itemArgs = append(itemArgs, formatNumericParam(filter.Width))
+	}
+	if filter.Profile > 0 {
+		itemConditions = append(itemConditions, "l.params->>'profile' = ?")
+		itemArgs = append(itemArgs, formatNumericParam(filter.Profile))
+	}
+	if filter.Diameter > 0 {
+		itemConditions = append(itemConditions, "l.params->>'diameter' = ?")
+		itemArgs = append(itemArgs, formatNumericParam(filter.Diameter))
+	}
+	if filter.WarehouseID != nil {
+		itemConditions = append(itemConditions, "l.warehouse_id = ?")
+		itemArgs = append(itemArgs, *filter.WarehouseID)
+	}
+	if len(itemConditions) > 0 {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM order_items oi JOIN lots l ON l.id = oi.lot_id WHERE oi.order_id = orders.id AND oi.deleted_at IS NULL AND "+strings.Join(itemConditions, " AND ")+")",
+			itemArgs...,
+		)
+	}
+
+	return query
+}
+
+func applyOrderSorting(query *gorm.DB, filter domain.OrderFilter) *gorm.DB {
+	sortOrder := strings.ToLower(strings.TrimSpace(filter.SortOrder))
+	if sortOrder != "asc" {
+		sortOrder = "desc"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(filter.SortBy)) {
+	case "total_amount":
+		query = query.Order("orders.total_amount " + sortOrder)
+	case "status":
+		query = query.Order("orders.status " + sortOrder)
+	case "customer_name":
+		query = query.Order("orders.customer_name " + sortOrder)
+	default:
+		query = query.Order("orders.created_at " + sortOrder)
+	}
+
+	return query.Order("orders.id DESC")
+}
+
 // ListByUserID retrieves orders for a specific user.
 func (r *OrderRepo) ListByUserID(ctx context.Context, userID uuid.UUID, filter domain.OrderFilter) ([]domain.OrderResponse, int64, error) {
 	var dbOrders []models.Order
@@ -475,35 +653,322 @@ func (r *OrderRepo) ListByUserID(ctx context.Context, userID uuid.UUID, filter d
 		return nil, 0, fmt.Errorf("failed to fetch user orders: %w", err)
 	}
 
+	balances, err := loadOrderBalances(r.db.WithContext(ctx), dbOrders)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var responses []domain.OrderResponse
 	for _, order := range dbOrders {
-		var items []domain.OrderItemResponse
-		for _, item := range order.Items {
-			items = append(items, domain.OrderItemResponse{
-				ID:       item.ID,
-				LotID:    item.LotID,
-				Brand:    item.Brand,
-				Model:    item.Model,
-				Photo:    item.Photo,
-				Quantity: item.Quantity,
-				Price:    item.PriceAtMoment,
-				Total:    item.PriceAtMoment * float64(item.Quantity),
-			})
-		}
+		responses = append(responses, mapOrderModel(order, balances[order.ID]))
+	}
+
+	return responses, total, nil
+}
 
-		responses = append(responses, domain.OrderResponse{
-			ID:                 order.ID,
-			CustomerName:       order.CustomerName,
-			CustomerPhone:      order.CustomerPhone,
-			CustomerUsername:   order.CustomerUsername,
-			CustomerTelegramID: order.CustomerTelegramID,
-			Channel:            domain.OrderChannel(order.Channel),
-			Status:             order.Status,
-			TotalAmount:        order.TotalAmount,
-			CreatedAt:          order.CreatedAt.Format("2006-01-02 15:04:05"),
-			Items:              items,
+func mapOrderModel(order models.Order, balance domain.OrderBalance) domain.OrderResponse {
+	var items []domain.OrderItemResponse
+	for _, item := range order.Items {
+		items = append(items, domain.OrderItemResponse{
+			ID:       item.ID,
+			LotID:    item.LotID,
+			Brand:    item.Brand,
+			Model:    item.Model,
+			Photo:    item.Photo,
+			Quantity: item.Quantity,
+			Price:    item.PriceAtMoment,
+			Discount: item.DiscountAmount,
+			Total:    item.PriceAtMoment*float64(item.Quantity) - item.DiscountAmount,
 		})
 	}
 
-	return responses, total, nil
+	return domain.OrderResponse{
+		ID:                 order.ID,
+		CustomerName:       order.CustomerName,
+		CustomerPhone:      order.CustomerPhone,
+		CustomerUsername:   order.CustomerUsername,
+		CustomerTelegramID: order.CustomerTelegramID,
+		CustomerID:         order.CustomerID,
+		Channel:            domain.OrderChannel(order.Channel),
+		Status:             order.Status,
+		TotalAmount:        order.TotalAmount,
+		DiscountAmount:     order.DiscountAmount,
+		PromoCode:          order.PromoCode,
+		PaidAmount:         balance.PaidAmount,
+		Balance:            balance.Balance,
+		PaymentState:       balance.PaymentState(),
+		TrackingNumber:     order.TrackingNumber,
+		FiscalCode:         order.FiscalCode,
+		AssigneeID:         order.AssigneeID,
+		AssignedAt:         formatOptionalTime(order.AssignedAt),
+		SLA:                mapOrderSLA(order, time.Now()),
+		CreatedAt:          order.CreatedAt.Format("2006-01-02 15:04:05"),
+		Items:              items,
+	}
+}
+
+// orderItemAuditEntry is the item shape stored in ITEMS_CHANGED audit logs.
+type orderItemAuditEntry struct {
+	ID       uuid.UUID `json:"id"`
+	LotID    uuid.UUID `json:"lot_id"`
+	Brand    string    `json:"brand"`
+	Model    string    `json:"model"`
+	Quantity int       `json:"quantity"`
+	Price    float64   `json:"price"`
+	Discount float64   `json:"discount,omitempty"`
+}
+
+func orderItemsAudit(items []models.OrderItem) []orderItemAuditEntry {
+	entries := make([]orderItemAuditEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, orderItemAuditEntry{
+			ID:       item.ID,
+			LotID:    item.LotID,
+			Brand:    item.Brand,
+			Model:    item.Model,
+			Quantity: item.Quantity,
+			Price:    item.PriceAtMoment,
+			Discount: item.DiscountAmount,
+		})
+	}
+	return entries
+}
+
+// EditItems adds, changes or removes an item of an open order. The order row and every affected lot are
+// locked, stock moves in both directions, and the total and the audit log are written in the same transaction.
+func (r *OrderRepo) EditItems(ctx context.Context, orderID, userID uuid.UUID, edit domain.OrderItemEdit, comment string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Preload("Items").Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		if !domain.OrderStatus(order.Status).AllowsItemEdits() {
+			return fmt.Errorf("%w: %s", domain.ErrOrderItemsLocked, order.Status)
+		}
+
+		oldItems := orderItemsAudit(order.Items)
+		items := order.Items
+
+		findItem := func(match func(models.OrderItem) bool) int {
+			for i, item := range items {
+				if match(item) {
+					return i
+				}
+			}
+			return -1
+		}
+
+		switch edit.Op {
+		case domain.OrderItemEditAdd:
+			if edit.FinalPrice != nil && order.Channel != string(domain.OrderChannelOffline) {
+				return fmt.Errorf("final_price is allowed only for offline orders")
+			}
+
+			lot, err := deductLotStock(tx, edit.LotID, edit.Quantity)
+			if err != nil {
+				return err
+			}
+
+			if idx := findItem(func(item models.OrderItem) bool { return item.LotID == edit.LotID }); idx >= 0 {
+				items[idx].Quantity += edit.Quantity
+				if edit.FinalPrice != nil {
+					items[idx].PriceAtMoment = *edit.FinalPrice
+				}
+				refreshOrderItemSnapshot(&items[idx], lot)
+				if err := tx.Save(&items[idx]).Error; err != nil {
+					return fmt.Errorf("failed to update order item: %w", err)
+				}
+				break
+			}
+
+			price := lot.SellPrice
+			if edit.FinalPrice != nil {
+				price = *edit.FinalPrice
+			}
+			item := models.OrderItem{
+				OrderID:       order.ID,
+				LotID:         lot.ID,
+				Quantity:      edit.Quantity,
+				PriceAtMoment: price,
+			}
+			refreshOrderItemSnapshot(&item, lot)
+			if err := tx.Create(&item).Error; err != nil {
+				return fmt.Errorf("failed to add order item: %w", err)
+			}
+			items = append(items, item)
+
+		case domain.OrderItemEditUpdate:
+			idx := findItem(func(item models.OrderItem) bool { return item.ID == edit.ItemID })
+			if idx < 0 {
+				return fmt.Errorf("order item %s not found", edit.ItemID)
+			}
+			item := &items[idx]
+
+			if edit.LotID != uuid.Nil && edit.LotID != item.LotID {
+				// Swap: put the old lot back and take the new one at its current sell price.
+				if findItem(func(other models.OrderItem) bool { return other.LotID == edit.LotID }) >= 0 {
+					return fmt.Errorf("lot %s is already in the order, change its quantity instead", edit.LotID)
+				}
+				if _, err := restockLot(tx, item.LotID, item.Quantity); err != nil {
+					return err
+				}
+				lot, err := deductLotStock(tx, edit.LotID, edit.Quantity)
+				if err != nil {
+					return err
+				}
+				item.LotID = lot.ID
+				item.PriceAtMoment = lot.SellPrice
+				refreshOrderItemSnapshot(item, lot)
+			} else {
+				var lot *models.Lot
+				var err error
+				delta := edit.Quantity - item.Quantity
+				switch {
+				case delta > 0:
+					lot, err = deductLotStock(tx, item.LotID, delta)
+				case delta < 0:
+					lot, err = restockLot(tx, item.LotID, -delta)
+				default:
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				refreshOrderItemSnapshot(item, lot)
+			}
+
+			item.Quantity = edit.Quantity
+			if err := tx.Save(item).Error; err != nil {
+				return fmt.Errorf("failed to update order item: %w", err)
+			}
+
+		case domain.OrderItemEditRemove:
+			idx := findItem(func(item models.OrderItem) bool { return item.ID == edit.ItemID })
+			if idx < 0 {
+				return fmt.Errorf("order item %s not found", edit.ItemID)
+			}
+			if len(items) == 1 {
+				return fmt.Errorf("cannot remove the last item, cancel the order instead")
+			}
+
+			if _, err := restockLot(tx, items[idx].LotID, items[idx].Quantity); err != nil {
+				return err
+			}
+			if err := tx.Delete(&items[idx]).Error; err != nil {
+				return fmt.Errorf("failed to remove order item: %w", err)
+			}
+			items = append(items[:idx], items[idx+1:]...)
+
+		default:
+			return fmt.Errorf("unknown order item edit: %s", edit.Op)
+		}
+
+		// Spread the promo discount over the new item set. The code was redeemed when the order was placed,
+		// so only its rules are checked again: an order that no longer qualifies loses the discount.
+		discountAmount := order.DiscountAmount
+		if order.PromoCodeID != nil {
+			var promo models.PromoCode
+			if err := tx.Unscoped().First(&promo, "id = ?", *order.PromoCodeID).Error; err != nil {
+				return fmt.Errorf("failed to fetch order promo code: %w", err)
+			}
+
+			var err error
+			discountAmount, err = applyPromoDiscount(tx, &promo, items)
+			if errors.Is(err, domain.ErrPromoCodeInvalid) {
+				for i := range items {
+					items[i].DiscountAmount = 0
+				}
+				discountAmount = 0
+			} else if err != nil {
+				return err
+			}
+
+			for i := range items {
+				if err := tx.Model(&items[i]).Update("discount_amount", items[i].DiscountAmount).Error; err != nil {
+					return fmt.Errorf("failed to update order item discount: %w", err)
+				}
+			}
+		}
+
+		var totalAmount float64
+		for _, item := range items {
+			totalAmount += item.PriceAtMoment*float64(item.Quantity) - item.DiscountAmount
+		}
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"total_amount":    totalAmount,
+			"discount_amount": discountAmount,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update order total amount: %w", err)
+		}
+
+		oldVal, _ := json.Marshal(map[string]interface{}{"items": oldItems, "total_amount": order.TotalAmount, "discount_amount": order.DiscountAmount})
+		newVal, _ := json.Marshal(map[string]interface{}{"items": orderItemsAudit(items), "total_amount": totalAmount, "discount_amount": discountAmount})
+
+		auditLog := models.AuditLog{
+			Entity:   "ORDER",
+			EntityID: order.ID,
+			UserID:   userID,
+			Action:   "ITEMS_CHANGED",
+			OldValue: datatypes.JSON(oldVal),
+			NewValue: datatypes.JSON(newVal),
+			Comment:  comment,
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// deductLotStock locks the lot and takes quantity out of it, archiving the lot when it runs out.
+func deductLotStock(tx *gorm.DB, lotID uuid.UUID, quantity int) (*models.Lot, error) {
+	var lot models.Lot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, "id = ?", lotID).Error; err != nil {
+		return nil, fmt.Errorf("lot %s not found: %w", lotID, err)
+	}
+
+	if lot.CurrentQuantity < quantity {
+		return nil, fmt.Errorf("not enough stock for lot %s (requested: %d, available: %d)", lot.ID, quantity, lot.CurrentQuantity)
+	}
+
+	lot.CurrentQuantity -= quantity
+	if lot.CurrentQuantity == 0 {
+		lot.Status = "ARCHIVED"
+	}
+
+	if err := tx.Save(&lot).Error; err != nil {
+		return nil, fmt.Errorf("failed to update lot %s: %w", lot.ID, err)
+	}
+	return &lot, nil
+}
+
+// restockLot locks the lot and puts quantity back, reactivating it if it was archived at zero stock.
+func restockLot(tx *gorm.DB, lotID uuid.UUID, quantity int) (*models.Lot, error) {
+	var lot models.Lot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, "id = ?", lotID).Error; err != nil {
+		return nil, fmt.Errorf("lot %s not found during restocking: %w", lotID, err)
+	}
+
+	lot.CurrentQuantity += quantity
+	if lot.CurrentQuantity > 0 && lot.Status == "ARCHIVED" {
+		lot.Status = "ACTIVE"
+	}
+
+	if err := tx.Save(&lot).Error; err != nil {
+		return nil, fmt.Errorf("failed to restock lot %s: %w", lot.ID, err)
+	}
+	return &lot, nil
+}
+
+// refreshOrderItemSnapshot copies the current lot details into the item. The price is left to the caller.
+func refreshOrderItemSnapshot(item *models.OrderItem, lot *models.Lot) {
+	item.Brand = lot.Brand
+	item.Model = lot.Model
+	item.Photo = ""
+	if len(lot.Photos) > 0 {
+		item.Photo = lot.Photos[0]
+	}
+	item.CostAtMoment = lot.PurchasePrice
 }
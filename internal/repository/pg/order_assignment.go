@@ -0,0 +1,248 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+// orderRoundRobinLockKey serializes automatic assignment so two new orders do not go to the same person.
+const orderRoundRobinLockKey = 7041001
+
+type OrderAssignmentRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderAssignmentRepository(db *gorm.DB) domain.OrderAssignmentRepository {
+	return &OrderAssignmentRepo{db: db}
+}
+
+func (r *OrderAssignmentRepo) StartSLA(ctx context.Context, orderID uuid.UUID, start domain.StartOrderSLA) (*uuid.UUID, error) {
+	var assigneeID *uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		firstResponseDue := order.CreatedAt.Add(start.SLA.FirstResponse)
+		processingDue := order.CreatedAt.Add(start.SLA.Processing)
+		updates := map[string]interface{}{
+			"first_response_due_at": firstResponseDue,
+			"processing_due_at":     processingDue,
+		}
+		if start.Responded && order.FirstRespondedAt == nil {
+			updates["first_responded_at"] = order.CreatedAt
+		}
+
+		auditComment := "assigned on creation"
+		assigneeID = start.AssigneeID
+		if assigneeID == nil && start.AutoAssign && order.AssigneeID == nil {
+			next, err := nextOrderAssignee(tx)
+			if err != nil {
+				return err
+			}
+			assigneeID = next
+			auditComment = "assigned round-robin"
+		}
+		if assigneeID != nil {
+			updates["assignee_id"] = *assigneeID
+			updates["assigned_at"] = time.Now()
+		}
+
+		previous := order.AssigneeID
+		if err := tx.Model(&order).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to start order sla: %w", err)
+		}
+
+		if assigneeID == nil {
+			return nil
+		}
+		// Orders placed at the counter are assigned to the staff member who placed them.
+		actor := uuid.Nil
+		if start.AssigneeID != nil {
+			actor = *start.AssigneeID
+		}
+		return createAssignmentAuditLog(tx, order.ID, actor, previous, assigneeID, auditComment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assigneeID, nil
+}
+
+// nextOrderAssignee picks the staff member whose last assignment is the oldest, so new orders rotate
+// across the team. Returns nil when there is no staff.
+func nextOrderAssignee(tx *gorm.DB) (*uuid.UUID, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", orderRoundRobinLockKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock order assignment: %w", err)
+	}
+
+	var ids []uuid.UUID
+	if err := tx.Raw(`
+		SELECT users.id FROM users
+		LEFT JOIN (
+			SELECT assignee_id, MAX(assigned_at) AS last_assigned_at
+			FROM orders
+			WHERE assignee_id IS NOT NULL
+			GROUP BY assignee_id
+		) assigned ON assigned.assignee_id = users.id
+		WHERE users.role = ? AND users.deleted_at IS NULL
+		ORDER BY assigned.last_assigned_at ASC NULLS FIRST, users.created_at ASC
+		LIMIT 1`, string(domain.RoleStaff)).
+		Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to pick order assignee: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+func (r *OrderAssignmentRepo) Assign(ctx context.Context, orderID uuid.UUID, assigneeID *uuid.UUID, userID uuid.UUID, comment string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		if sameAssignee(order.AssigneeID, assigneeID) {
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"assignee_id": assigneeID,
+			"assigned_at": nil,
+		}
+		if assigneeID != nil {
+			updates["assigned_at"] = time.Now()
+		}
+		previous := order.AssigneeID
+		if err := tx.Model(&order).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to assign order: %w", err)
+		}
+
+		return createAssignmentAuditLog(tx, order.ID, userID, previous, assigneeID, comment)
+	})
+}
+
+func (r *OrderAssignmentRepo) MarkFirstResponse(ctx context.Context, orderID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ? AND first_responded_at IS NULL", orderID).
+		Update("first_responded_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark order first response: %w", err)
+	}
+	return nil
+}
+
+func (r *OrderAssignmentRepo) ListSLABreaches(ctx context.Context, now time.Time, limit int) ([]domain.OrderSLABreach, error) {
+	var dbOrders []models.Order
+	if err := r.db.WithContext(ctx).Preload("Items").
+		Where(`(first_responded_at IS NULL AND first_response_escalated_at IS NULL AND first_response_due_at <= ?)
+			OR (processed_at IS NULL AND processing_escalated_at IS NULL AND processing_due_at <= ?)`, now, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&dbOrders).Error; err != nil {
+		return nil, fmt.Errorf("failed to list order sla breaches: %w", err)
+	}
+
+	balances, err := loadOrderBalances(r.db.WithContext(ctx), dbOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	var breaches []domain.OrderSLABreach
+	for _, order := range dbOrders {
+		response := mapOrderModel(order, balances[order.ID])
+		if order.FirstRespondedAt == nil && order.FirstResponseEscalatedAt == nil && order.FirstResponseDueAt != nil && !order.FirstResponseDueAt.After(now) {
+			breaches = append(breaches, domain.OrderSLABreach{Order: response, Kind: domain.OrderSLAFirstResponse, Due: *order.FirstResponseDueAt})
+		}
+		if order.ProcessedAt == nil && order.ProcessingEscalatedAt == nil && order.ProcessingDueAt != nil && !order.ProcessingDueAt.After(now) {
+			breaches = append(breaches, domain.OrderSLABreach{Order: response, Kind: domain.OrderSLAProcessing, Due: *order.ProcessingDueAt})
+		}
+	}
+
+	return breaches, nil
+}
+
+func (r *OrderAssignmentRepo) MarkSLAEscalated(ctx context.Context, orderID uuid.UUID, kind domain.OrderSLAKind) error {
+	var column string
+	switch kind {
+	case domain.OrderSLAFirstResponse:
+		column = "first_response_escalated_at"
+	case domain.OrderSLAProcessing:
+		column = "processing_escalated_at"
+	default:
+		return errors.New("unknown order sla kind")
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ?", orderID).
+		Update(column, time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark order sla escalated: %w", err)
+	}
+	return nil
+}
+
+func createAssignmentAuditLog(tx *gorm.DB, orderID, userID uuid.UUID, oldAssignee, newAssignee *uuid.UUID, comment string) error {
+	oldVal, _ := json.Marshal(map[string]interface{}{"assignee_id": oldAssignee})
+	newVal, _ := json.Marshal(map[string]interface{}{"assignee_id": newAssignee})
+
+	auditLog := models.AuditLog{
+		Entity:   "ORDER",
+		EntityID: orderID,
+		UserID:   userID,
+		Action:   "ASSIGNED",
+		OldValue: datatypes.JSON(oldVal),
+		NewValue: datatypes.JSON(newVal),
+		Comment:  comment,
+	}
+	if err := tx.Create(&auditLog).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func sameAssignee(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// mapOrderSLA returns nil for orders placed before SLA tracking.
+func mapOrderSLA(order models.Order, now time.Time) *domain.OrderSLAStatus {
+	if order.FirstResponseDueAt == nil && order.ProcessingDueAt == nil {
+		return nil
+	}
+
+	firstResponseOverdue := order.FirstRespondedAt == nil && order.FirstResponseDueAt != nil && !order.FirstResponseDueAt.After(now)
+	processingOverdue := order.ProcessedAt == nil && order.ProcessingDueAt != nil && !order.ProcessingDueAt.After(now)
+
+	return &domain.OrderSLAStatus{
+		FirstResponseDue: formatOptionalTime(order.FirstResponseDueAt),
+		FirstRespondedAt: formatOptionalTime(order.FirstRespondedAt),
+		ProcessingDue:    formatOptionalTime(order.ProcessingDueAt),
+		ProcessedAt:      formatOptionalTime(order.ProcessedAt),
+		Overdue:          firstResponseOverdue || processingOverdue,
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,280 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type OrderLedgerRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderLedgerRepository(db *gorm.DB) domain.OrderLedgerRepository {
+	return &OrderLedgerRepo{db: db}
+}
+
+// AddEntry records a manual payment or refund. The order row is locked so the balance check and the
+// insert cannot interleave with another entry, a status change or a return. A fiscalized offline sale can
+// only be refunded for what its returns cover, since each return prints its own return receipt.
+func (r *OrderLedgerRepo) AddEntry(ctx context.Context, orderID, userID uuid.UUID, dto domain.CreateLedgerEntryDTO) (*domain.LedgerEntryResponse, error) {
+	receivedAt := time.Now()
+	if dto.ReceivedAt != "" {
+		parsed, err := time.ParseInLocation(domain.LedgerDateLayout, dto.ReceivedAt, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid received_at: %w", err)
+		}
+		if parsed.After(receivedAt) {
+			return nil, fmt.Errorf("received_at cannot be in the future")
+		}
+		receivedAt = parsed
+	}
+
+	var entry models.OrderLedgerEntry
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		balance, err := loadOrderBalance(tx, order)
+		if err != nil {
+			return err
+		}
+
+		amount := math.Round(dto.Amount*100) / 100
+		switch dto.Type {
+		case domain.LedgerEntryPayment:
+			if amount > balance.Balance+0.005 {
+				return fmt.Errorf("%w: payment %.2f exceeds the balance %.2f", domain.ErrLedgerEntryNotAllowed, amount, balance.Balance)
+			}
+		case domain.LedgerEntryRefund:
+			if amount > balance.PaidAmount+0.005 {
+				return fmt.Errorf("%w: refund %.2f exceeds the paid amount %.2f", domain.ErrLedgerEntryNotAllowed, amount, balance.PaidAmount)
+			}
+			if err := checkFiscalizedRefund(tx, order, amount); err != nil {
+				return err
+			}
+		}
+
+		entry = models.OrderLedgerEntry{
+			OrderID:      order.ID,
+			Type:         string(dto.Type),
+			Method:       string(dto.Method),
+			Amount:       amount,
+			ReceivedByID: &userID,
+			ReceivedAt:   receivedAt,
+			Comment:      dto.Comment,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to create ledger entry: %w", err)
+		}
+
+		newVal, _ := json.Marshal(map[string]interface{}{
+			"ledger_entry_id": entry.ID.String(),
+			"type":            entry.Type,
+			"method":          entry.Method,
+			"amount":          entry.Amount,
+		})
+		auditLog := models.AuditLog{
+			Entity:   "ORDER",
+			EntityID: order.ID,
+			UserID:   userID,
+			Action:   "LEDGER_" + entry.Type,
+			NewValue: datatypes.JSON(newVal),
+			Comment:  dto.Comment,
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapLedgerEntryModel(entry)
+	return &response, nil
+}
+
+func (r *OrderLedgerRepo) ListEntries(ctx context.Context, orderID uuid.UUID) ([]domain.LedgerEntryResponse, error) {
+	var entries []models.OrderLedgerEntry
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("received_at ASC, created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+
+	responses := make([]domain.LedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, mapLedgerEntryModel(entry))
+	}
+	return responses, nil
+}
+
+func (r *OrderLedgerRepo) GetBalance(ctx context.Context, orderID uuid.UUID) (*domain.OrderBalance, error) {
+	var order models.Order
+	if err := r.db.WithContext(ctx).First(&order, "id = ?", orderID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order: %w", err)
+	}
+
+	balance, err := loadOrderBalance(r.db.WithContext(ctx), order)
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// createOnlineLedgerEntry books a confirmed or reversed provider payment in the ledger.
+func createOnlineLedgerEntry(tx *gorm.DB, payment models.Payment, entryType domain.LedgerEntryType) error {
+	entry := models.OrderLedgerEntry{
+		OrderID:    payment.OrderID,
+		Type:       string(entryType),
+		Method:     string(domain.PaymentMethodOnline),
+		Amount:     payment.Amount,
+		PaymentID:  &payment.ID,
+		ReceivedAt: time.Now(),
+		Comment:    payment.Provider,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to create ledger entry: %w", err)
+	}
+	return nil
+}
+
+// checkFiscalizedRefund rejects a manual refund of an offline order with a sale receipt beyond the return
+// refunds that are not paid back yet. Such a refund would leave the fiscal register without a return receipt.
+func checkFiscalizedRefund(tx *gorm.DB, order models.Order, amount float64) error {
+	if order.Channel != string(domain.OrderChannelOffline) {
+		return nil
+	}
+
+	var sales int64
+	if err := tx.Model(&models.FiscalReceipt{}).
+		Where("order_id = ? AND type = ?", order.ID, string(domain.FiscalReceiptSale)).
+		Count(&sales).Error; err != nil {
+		return fmt.Errorf("failed to check sale receipt: %w", err)
+	}
+	if sales == 0 {
+		return nil
+	}
+
+	var returned, refunded float64
+	if err := tx.Model(&models.OrderReturn{}).
+		Select("COALESCE(SUM(refund_amount), 0)").
+		Where("order_id = ?", order.ID).
+		Scan(&returned).Error; err != nil {
+		return fmt.Errorf("failed to sum order returns: %w", err)
+	}
+	if err := tx.Model(&models.OrderLedgerEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("order_id = ? AND type = ?", order.ID, string(domain.LedgerEntryRefund)).
+		Scan(&refunded).Error; err != nil {
+		return fmt.Errorf("failed to sum ledger refunds: %w", err)
+	}
+
+	if covered := returned - refunded; amount > covered+0.005 {
+		return fmt.Errorf("%w: refund %.2f exceeds the return refunds still due %.2f, register a return first", domain.ErrLedgerEntryNotAllowed, amount, max(covered, 0))
+	}
+	return nil
+}
+
+func loadOrderBalance(tx *gorm.DB, order models.Order) (domain.OrderBalance, error) {
+	balances, err := loadOrderBalances(tx, []models.Order{order})
+	if err != nil {
+		return domain.OrderBalance{}, err
+	}
+	return balances[order.ID], nil
+}
+
+// loadOrderBalances computes the balances of the orders with two grouped queries.
+func loadOrderBalances(tx *gorm.DB, orders []models.Order) (map[uuid.UUID]domain.OrderBalance, error) {
+	balances := make(map[uuid.UUID]domain.OrderBalance, len(orders))
+	if len(orders) == 0 {
+		return balances, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+
+	var paidRows []struct {
+		OrderID uuid.UUID
+		Paid    float64
+	}
+	if err := tx.Model(&models.OrderLedgerEntry{}).
+		Select("order_id, COALESCE(SUM(CASE WHEN type = ? THEN -amount ELSE amount END), 0) AS paid", string(domain.LedgerEntryRefund)).
+		Where("order_id IN ?", ids).
+		Group("order_id").
+		Scan(&paidRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum ledger entries: %w", err)
+	}
+	paid := make(map[uuid.UUID]float64, len(paidRows))
+	for _, row := range paidRows {
+		paid[row.OrderID] = row.Paid
+	}
+
+	var refundRows []struct {
+		OrderID  uuid.UUID
+		Refunded float64
+	}
+	if err := tx.Model(&models.OrderReturn{}).
+		Select("order_id, COALESCE(SUM(refund_amount), 0) AS refunded").
+		Where("order_id IN ?", ids).
+		Group("order_id").
+		Scan(&refundRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum order returns: %w", err)
+	}
+	refunded := make(map[uuid.UUID]float64, len(refundRows))
+	for _, row := range refundRows {
+		refunded[row.OrderID] = row.Refunded
+	}
+
+	for _, order := range orders {
+		total := order.TotalAmount - refunded[order.ID]
+		// Nothing is owed for an order that was cancelled or sent back as a whole.
+		if order.Status == string(domain.OrderStatusCancelled) || order.Status == string(domain.OrderStatusReturned) {
+			total = 0
+		}
+		balances[order.ID] = domain.OrderBalance{
+			TotalAmount: roundKopecks(total),
+			PaidAmount:  roundKopecks(paid[order.ID]),
+			Balance:     roundKopecks(total - paid[order.ID]),
+		}
+	}
+
+	return balances, nil
+}
+
+func roundKopecks(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func mapLedgerEntryModel(entry models.OrderLedgerEntry) domain.LedgerEntryResponse {
+	return domain.LedgerEntryResponse{
+		ID:           entry.ID,
+		OrderID:      entry.OrderID,
+		Type:         domain.LedgerEntryType(entry.Type),
+		Method:       domain.PaymentMethod(entry.Method),
+		Amount:       entry.Amount,
+		PaymentID:    entry.PaymentID,
+		ReceivedByID: entry.ReceivedByID,
+		ReceivedAt:   entry.ReceivedAt.Format(domain.LedgerDateLayout),
+		Comment:      entry.Comment,
+		CreatedAt:    entry.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
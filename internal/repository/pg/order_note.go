@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type OrderNoteRepo struct {
+	db *gorm.DB
+}
+
+type orderNoteRow struct {
+	models.OrderNote
+	Username    string
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+}
+
+type orderStatusChangeRow struct {
+	models.AuditLog
+	Username    string
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+}
+
+func NewOrderNoteRepository(db *gorm.DB) domain.OrderNoteRepository {
+	return &OrderNoteRepo{db: db}
+}
+
+func (r *OrderNoteRepo) Create(ctx context.Context, record domain.CreateOrderNoteRecord) (*domain.OrderNote, error) {
+	mentions, err := json.Marshal(record.MentionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode note mentions: %w", err)
+	}
+
+	note := models.OrderNote{
+		OrderID:    record.OrderID,
+		AuthorID:   record.AuthorID,
+		Text:       record.Text,
+		MentionIDs: datatypes.JSON(mentions),
+	}
+	if err := r.db.WithContext(ctx).Create(&note).Error; err != nil {
+		return nil, fmt.Errorf("failed to create order note: %w", err)
+	}
+
+	var row orderNoteRow
+	if err := r.notesQuery(ctx).Where("order_notes.id = ?", note.ID).Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order note: %w", err)
+	}
+	return mapOrderNoteRow(row), nil
+}
+
+func (r *OrderNoteRepo) List(ctx context.Context, orderID uuid.UUID) ([]domain.OrderNote, error) {
+	var rows []orderNoteRow
+	if err := r.notesQuery(ctx).
+		Where("order_notes.order_id = ?", orderID).
+		Order("order_notes.created_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list order notes: %w", err)
+	}
+
+	notes := make([]domain.OrderNote, 0, len(rows))
+	for _, row := range rows {
+		notes = append(notes, *mapOrderNoteRow(row))
+	}
+	return notes, nil
+}
+
+func (r *OrderNoteRepo) ListStatusChanges(ctx context.Context, orderID uuid.UUID) ([]domain.OrderStatusChange, error) {
+	var rows []orderStatusChangeRow
+	if err := r.db.WithContext(ctx).
+		Table("audit_logs").
+		Select("audit_logs.*, users.username, users.first_name, users.last_name, users.phone_number").
+		Joins("LEFT JOIN users ON users.id = audit_logs.user_id").
+		Where("audit_logs.entity = ? AND audit_logs.entity_id = ? AND audit_logs.action = ?", "ORDER", orderID, "STATUS_CHANGED").
+		Order("audit_logs.created_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list order status changes: %w", err)
+	}
+
+	changes := make([]domain.OrderStatusChange, 0, len(rows))
+	for _, row := range rows {
+		var oldValue, newValue struct {
+			Status domain.OrderStatus `json:"status"`
+		}
+		_ = json.Unmarshal(row.OldValue, &oldValue)
+		_ = json.Unmarshal(row.NewValue, &newValue)
+
+		change := domain.OrderStatusChange{
+			From:      oldValue.Status,
+			To:        newValue.Status,
+			UserID:    row.UserID,
+			Comment:   row.Comment,
+			CreatedAt: row.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if row.UserID != uuid.Nil {
+			change.UserLabel = formatUserLabel(row.UserID, row.Username, row.FirstName, row.LastName, row.PhoneNumber)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (r *OrderNoteRepo) notesQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("order_notes").
+		Select("order_notes.*, users.username, users.first_name, users.last_name, users.phone_number").
+		Joins("LEFT JOIN users ON users.id = order_notes.author_id").
+		Where("order_notes.deleted_at IS NULL")
+}
+
+func mapOrderNoteRow(row orderNoteRow) *domain.OrderNote {
+	var mentions []uuid.UUID
+	_ = json.Unmarshal(row.MentionIDs, &mentions)
+
+	return &domain.OrderNote{
+		ID:          row.ID,
+		OrderID:     row.OrderID,
+		AuthorID:    row.AuthorID,
+		AuthorLabel: formatUserLabel(row.AuthorID, row.Username, row.FirstName, row.LastName, row.PhoneNumber),
+		Text:        row.Text,
+		MentionIDs:  mentions,
+		CreatedAt:   row.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
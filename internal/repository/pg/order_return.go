@@ -0,0 +1,256 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+// CreateReturnTx registers a return on a completed order. Units marked RESTOCK go back to their lots,
+// written-off units do not. The refund is split across the returned lines by their sale value so that
+// P&L can subtract it per warehouse and channel.
+func (r *OrderRepo) CreateReturnTx(ctx context.Context, orderID, userID uuid.UUID, dto domain.CreateOrderReturnDTO) (uuid.UUID, error) {
+	var returnID uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Preload("Items").Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return fmt.Errorf("order not found or locked: %w", err)
+		}
+
+		if order.Status != string(domain.OrderStatusDone) {
+			return fmt.Errorf("%w: order is %s", domain.ErrReturnNotAllowed, order.Status)
+		}
+
+		// 1. Count what was already returned per order item
+		var returned []struct {
+			OrderItemID uuid.UUID
+			Quantity    int
+		}
+		if err := tx.Raw(`
+			SELECT ri.order_item_id, COALESCE(SUM(ri.quantity), 0) AS quantity
+			FROM order_return_items ri
+			JOIN order_returns r ON r.id = ri.return_id
+			WHERE r.order_id = ? AND r.deleted_at IS NULL AND ri.deleted_at IS NULL
+			GROUP BY ri.order_item_id
+		`, order.ID).Scan(&returned).Error; err != nil {
+			return fmt.Errorf("failed to load previous returns: %w", err)
+		}
+		returnedQty := make(map[uuid.UUID]int, len(returned))
+		for _, row := range returned {
+			returnedQty[row.OrderItemID] = row.Quantity
+		}
+
+		var refunded float64
+		if err := tx.Model(&models.OrderReturn{}).
+			Where("order_id = ?", order.ID).
+			Select("COALESCE(SUM(refund_amount), 0)").
+			Scan(&refunded).Error; err != nil {
+			return fmt.Errorf("failed to load previous refunds: %w", err)
+		}
+
+		orderItems := make(map[uuid.UUID]models.OrderItem, len(order.Items))
+		for _, item := range order.Items {
+			orderItems[item.ID] = item
+		}
+
+		// 2. Validate the selection and build the return lines
+		lines := make([]models.OrderReturnItem, 0, len(dto.Items))
+		lineValues := make([]float64, 0, len(dto.Items))
+		var itemsValue float64
+		seen := make(map[uuid.UUID]bool, len(dto.Items))
+
+		for _, selected := range dto.Items {
+			item, ok := orderItems[selected.OrderItemID]
+			if !ok {
+				return fmt.Errorf("order item %s not found in order", selected.OrderItemID)
+			}
+			if seen[item.ID] {
+				return fmt.Errorf("order item %s is listed twice", item.ID)
+			}
+			seen[item.ID] = true
+
+			available := item.Quantity - returnedQty[item.ID]
+			if selected.Quantity > available {
+				return fmt.Errorf("cannot return %d of order item %s, only %d left to return", selected.Quantity, item.ID, available)
+			}
+
+			// Returned units are worth what the buyer paid for them, after their share of the discount.
+			value := item.PriceAtMoment*float64(selected.Quantity) - item.DiscountAmount*float64(selected.Quantity)/float64(item.Quantity)
+			itemsValue += value
+			lineValues = append(lineValues, value)
+			lines = append(lines, models.OrderReturnItem{
+				OrderItemID: item.ID,
+				LotID:       item.LotID,
+				Quantity:    selected.Quantity,
+				Disposition: string(selected.Disposition),
+				CostAmount:  item.CostAtMoment * float64(selected.Quantity),
+			})
+		}
+
+		// 3. Settle the refund amount
+		refund := itemsValue
+		if dto.RefundAmount != nil {
+			refund = *dto.RefundAmount
+		}
+		// A custom refund can only lower the value of the returned lines, or their line refunds would exceed
+		// what they were sold for in P&L.
+		if refund > itemsValue+0.005 {
+			return fmt.Errorf("refund %.2f exceeds the value of the returned items %.2f", refund, itemsValue)
+		}
+		if remaining := order.TotalAmount - refunded; refund > remaining+0.005 {
+			return fmt.Errorf("refund %.2f exceeds the amount left to refund %.2f", refund, remaining)
+		}
+		allocateReturnRefund(lines, lineValues, itemsValue, refund)
+
+		// 4. Book the money paid back, if it already left the till
+		if dto.RefundMethod != "" && refund > 0 {
+			balance, err := loadOrderBalance(tx, order)
+			if err != nil {
+				return err
+			}
+			if refund > balance.PaidAmount+0.005 {
+				return fmt.Errorf("%w: refund %.2f exceeds the paid amount %.2f", domain.ErrLedgerEntryNotAllowed, refund, balance.PaidAmount)
+			}
+			entry := models.OrderLedgerEntry{
+				OrderID:      order.ID,
+				Type:         string(domain.LedgerEntryRefund),
+				Method:       string(dto.RefundMethod),
+				Amount:       refund,
+				ReceivedByID: &userID,
+				ReceivedAt:   time.Now(),
+				Comment:      dto.Reason,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return fmt.Errorf("failed to create ledger entry: %w", err)
+			}
+		}
+
+		// 5. Restock what can be sold again
+		for _, line := range lines {
+			if line.Disposition != string(domain.ReturnDispositionRestock) {
+				continue
+			}
+			if _, err := restockLot(tx, line.LotID, line.Quantity); err != nil {
+				return err
+			}
+		}
+
+		// 6. Save the return with its lines
+		orderReturn := models.OrderReturn{
+			OrderID:      order.ID,
+			CreatedByID:  userID,
+			Reason:       dto.Reason,
+			RefundAmount: refund,
+			Items:        lines,
+		}
+		if err := tx.Create(&orderReturn).Error; err != nil {
+			return fmt.Errorf("failed to create order return: %w", err)
+		}
+
+		newVal, _ := json.Marshal(map[string]interface{}{
+			"return_id":     orderReturn.ID.String(),
+			"refund_amount": refund,
+			"items":         dto.Items,
+		})
+
+		auditLog := models.AuditLog{
+			Entity:   "ORDER",
+			EntityID: order.ID,
+			UserID:   userID,
+			Action:   "RETURN_CREATED",
+			NewValue: datatypes.JSON(newVal),
+			Comment:  dto.Reason,
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+
+		returnID = orderReturn.ID
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return returnID, nil
+}
+
+// allocateReturnRefund splits the refund across lines proportionally to their sale value, in kopecks,
+// with the rounding remainder on the last line so the shares add up exactly.
+func allocateReturnRefund(lines []models.OrderReturnItem, values []float64, total, refund float64) {
+	var allocated float64
+	for i := range lines {
+		if i == len(lines)-1 {
+			lines[i].RefundAmount = math.Round((refund-allocated)*100) / 100
+			return
+		}
+
+		share := refund / float64(len(lines))
+		if total > 0 {
+			share = refund * values[i] / total
+		}
+		lines[i].RefundAmount = math.Round(share*100) / 100
+		allocated += lines[i].RefundAmount
+	}
+}
+
+func (r *OrderRepo) ListReturns(ctx context.Context, orderID uuid.UUID) ([]domain.OrderReturnResponse, error) {
+	var dbReturns []models.OrderReturn
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&dbReturns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list order returns: %w", err)
+	}
+
+	var orderItems []models.OrderItem
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&orderItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order items: %w", err)
+	}
+	itemsByID := make(map[uuid.UUID]models.OrderItem, len(orderItems))
+	for _, item := range orderItems {
+		itemsByID[item.ID] = item
+	}
+
+	responses := make([]domain.OrderReturnResponse, 0, len(dbReturns))
+	for _, ret := range dbReturns {
+		items := make([]domain.OrderReturnItemResponse, 0, len(ret.Items))
+		for _, line := range ret.Items {
+			orderItem := itemsByID[line.OrderItemID]
+			items = append(items, domain.OrderReturnItemResponse{
+				OrderItemID:  line.OrderItemID,
+				LotID:        line.LotID,
+				Brand:        orderItem.Brand,
+				Model:        orderItem.Model,
+				Quantity:     line.Quantity,
+				Disposition:  domain.ReturnDisposition(line.Disposition),
+				RefundAmount: line.RefundAmount,
+			})
+		}
+
+		responses = append(responses, domain.OrderReturnResponse{
+			ID:           ret.ID,
+			OrderID:      ret.OrderID,
+			CreatedByID:  ret.CreatedByID,
+			Reason:       ret.Reason,
+			RefundAmount: ret.RefundAmount,
+			FiscalCode:   ret.FiscalCode,
+			CreatedAt:    ret.CreatedAt.Format("2006-01-02 15:04:05"),
+			Items:        items,
+		})
+	}
+
+	return responses, nil
+}
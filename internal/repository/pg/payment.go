@@ -0,0 +1,176 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type PaymentRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) domain.PaymentRepository {
+	return &PaymentRepo{db: db}
+}
+
+func (r *PaymentRepo) Create(ctx context.Context, record domain.CreatePaymentRecord) (*domain.PaymentResponse, error) {
+	expiresAt := record.ExpiresAt
+	payment := models.Payment{
+		OrderID:     record.OrderID,
+		Provider:    record.Provider,
+		Purpose:     string(record.Purpose),
+		Amount:      record.Amount,
+		Currency:    domain.PaymentCurrency,
+		Status:      string(domain.PaymentStatusPending),
+		CreatedByID: record.CreatedByID,
+		ExpiresAt:   &expiresAt,
+	}
+	if err := r.db.WithContext(ctx).Create(&payment).Error; err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+	return mapToDomainPayment(payment), nil
+}
+
+// AttachInvoice stores the payment link returned by the provider.
+func (r *PaymentRepo) AttachInvoice(ctx context.Context, id uuid.UUID, invoice domain.Invoice) (*domain.PaymentResponse, error) {
+	var payment models.Payment
+	if err := r.db.WithContext(ctx).First(&payment, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("payment not found: %w", err)
+	}
+
+	payment.PaymentURL = invoice.URL
+	if invoice.ProviderRef != "" {
+		payment.ProviderRef = invoice.ProviderRef
+	}
+	if err := r.db.WithContext(ctx).Save(&payment).Error; err != nil {
+		return nil, fmt.Errorf("failed to store payment link: %w", err)
+	}
+	return mapToDomainPayment(payment), nil
+}
+
+func (r *PaymentRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.PaymentResponse, error) {
+	var payment models.Payment
+	if err := r.db.WithContext(ctx).First(&payment, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("payment not found: %w", err)
+	}
+	return mapToDomainPayment(payment), nil
+}
+
+func (r *PaymentRepo) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]domain.PaymentResponse, error) {
+	var payments []models.Payment
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	result := make([]domain.PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		result = append(result, *mapToDomainPayment(payment))
+	}
+	return result, nil
+}
+
+// ApplyEvent moves the payment to the notified status. Providers retry callbacks and may deliver them out
+// of order, so repeated events and events for closed payments are ignored and report false.
+func (r *PaymentRepo) ApplyEvent(ctx context.Context, event domain.PaymentEvent) (*domain.PaymentResponse, bool, error) {
+	var (
+		payment models.Payment
+		changed bool
+	)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, "id = ?", event.PaymentID).Error; err != nil {
+			return fmt.Errorf("payment not found or locked: %w", err)
+		}
+
+		// A success always counts, even on a link we already expired or failed locally: the buyer was charged
+		// and the money has to reach the ledger. A reversed payment was a success before, so its late
+		// success is a duplicate.
+		current := domain.PaymentStatus(payment.Status)
+		switch {
+		case current == event.Status:
+			return nil
+		case current == domain.PaymentStatusSuccess && event.Status != domain.PaymentStatusReversed:
+			return nil
+		case current.IsFinal() && (event.Status != domain.PaymentStatusSuccess || current == domain.PaymentStatusReversed):
+			return nil
+		}
+
+		if event.ProviderRef != "" {
+			payment.ProviderRef = event.ProviderRef
+		}
+		if len(event.Raw) > 0 {
+			payment.Callback = datatypes.JSON(event.Raw)
+		}
+		payment.Status = string(event.Status)
+		payment.FailureReason = event.FailureReason
+		if event.Status == domain.PaymentStatusSuccess {
+			if event.Amount > 0 {
+				payment.Amount = event.Amount
+			}
+			paidAt := time.Now()
+			payment.PaidAt = &paidAt
+		}
+
+		if err := tx.Save(&payment).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		// Money that actually moved goes into the order ledger in the same transaction.
+		switch {
+		case event.Status == domain.PaymentStatusSuccess:
+			if err := createOnlineLedgerEntry(tx, payment, domain.LedgerEntryPayment); err != nil {
+				return err
+			}
+		case event.Status == domain.PaymentStatusReversed && current == domain.PaymentStatusSuccess:
+			if err := createOnlineLedgerEntry(tx, payment, domain.LedgerEntryRefund); err != nil {
+				return err
+			}
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return mapToDomainPayment(payment), changed, nil
+}
+
+func mapToDomainPayment(m models.Payment) *domain.PaymentResponse {
+	expiresAt := ""
+	if m.ExpiresAt != nil {
+		expiresAt = m.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	paidAt := ""
+	if m.PaidAt != nil {
+		paidAt = m.PaidAt.Format("2006-01-02 15:04:05")
+	}
+
+	return &domain.PaymentResponse{
+		ID:            m.ID,
+		OrderID:       m.OrderID,
+		Provider:      m.Provider,
+		ProviderRef:   m.ProviderRef,
+		Purpose:       domain.PaymentPurpose(m.Purpose),
+		Amount:        m.Amount,
+		Currency:      m.Currency,
+		Status:        domain.PaymentStatus(m.Status),
+		PaymentURL:    m.PaymentURL,
+		FailureReason: m.FailureReason,
+		ExpiresAt:     expiresAt,
+		PaidAt:        paidAt,
+		CreatedAt:     m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,266 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PhotoAssetRepo struct {
+	db *gorm.DB
+}
+
+func NewPhotoAssetRepository(db *gorm.DB) domain.PhotoAssetRepository {
+	return &PhotoAssetRepo{db: db}
+}
+
+func (r *PhotoAssetRepo) Create(ctx context.Context, asset *domain.PhotoAsset, uploadedByID uuid.UUID) error {
+	renditions, err := json.Marshal(asset.Renditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal photo renditions: %w", err)
+	}
+
+	dbModel := models.PhotoAsset{
+		URL:        asset.URL,
+		Width:      asset.Width,
+		Height:     asset.Height,
+		Renditions: renditions,
+	}
+	if uploadedByID != uuid.Nil {
+		dbModel.UploadedByID = &uploadedByID
+	}
+
+	if err := r.db.WithContext(ctx).Create(&dbModel).Error; err != nil {
+		return fmt.Errorf("failed to create photo asset: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PhotoAssetRepo) GetByURL(ctx context.Context, url string) (*domain.PhotoAsset, error) {
+	var dbModel models.PhotoAsset
+	if err := r.db.WithContext(ctx).First(&dbModel, "url = ?", url).Error; err != nil {
+		return nil, fmt.Errorf("photo not found: %w", err)
+	}
+
+	asset := mapToDomainPhotoAsset(dbModel)
+	return &asset, nil
+}
+
+func (r *PhotoAssetRepo) ListURLs(ctx context.Context, afterURL string, limit int) ([]string, error) {
+	var urls []string
+	if err := r.db.WithContext(ctx).Model(&models.PhotoAsset{}).
+		Where("url > ?", afterURL).
+		Order("url ASC").
+		Limit(limit).
+		Pluck("url", &urls).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch photo urls: %w", err)
+	}
+
+	return urls, nil
+}
+
+func (r *PhotoAssetRepo) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.PhotoAsset{}).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count photos: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *PhotoAssetRepo) UpdateRenditions(ctx context.Context, asset *domain.PhotoAsset) error {
+	renditions, err := json.Marshal(asset.Renditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal photo renditions: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.PhotoAsset{}).
+		Where("url = ?", asset.URL).
+		Updates(map[string]interface{}{
+			"width":      asset.Width,
+			"height":     asset.Height,
+			"renditions": renditions,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update photo renditions: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceLegacyPhoto rewrites every reference, soft-deleted rows included, so a restored lot does not point at
+// the removed file. Registering is an upsert, the job may run again after a partial failure.
+func (r *PhotoAssetRepo) ReplaceLegacyPhoto(ctx context.Context, oldURL string, asset *domain.PhotoAsset) error {
+	renditions, err := json.Marshal(asset.Renditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal photo renditions: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE lots SET photos = array_replace(photos, ?, ?) WHERE ? = ANY(photos)", oldURL, asset.URL, oldURL).Error; err != nil {
+			return fmt.Errorf("failed to update lot photos: %w", err)
+		}
+		if err := tx.Exec("UPDATE storage_contracts SET photos = array_replace(photos, ?, ?) WHERE ? = ANY(photos)", oldURL, asset.URL, oldURL).Error; err != nil {
+			return fmt.Errorf("failed to update storage contract photos: %w", err)
+		}
+		if err := tx.Exec("UPDATE order_items SET photo = ? WHERE photo = ?", asset.URL, oldURL).Error; err != nil {
+			return fmt.Errorf("failed to update order item photos: %w", err)
+		}
+
+		dbModel := models.PhotoAsset{
+			URL:        asset.URL,
+			Width:      asset.Width,
+			Height:     asset.Height,
+			Renditions: renditions,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "url"}},
+			DoUpdates: clause.AssignmentColumns([]string{"width", "height", "renditions", "updated_at"}),
+		}).Create(&dbModel).Error; err != nil {
+			return fmt.Errorf("failed to register converted photo: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *PhotoAssetRepo) AttachToLot(ctx context.Context, lotID uuid.UUID, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Model(&models.PhotoAsset{}).
+		Where("url IN ?", urls).
+		Updates(map[string]interface{}{"lot_id": lotID, "attached_at": time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to attach photos to lot: %w", err)
+	}
+
+	return nil
+}
+
+// photoReferencedSQL matches when anything still shows the photo at urlExpr. Order items keep their
+// snapshot forever, so photos of sold lots survive lot deletion.
+func photoReferencedSQL(urlExpr string) string {
+	return fmt.Sprintf(`(
+		EXISTS (SELECT 1 FROM lots WHERE lots.deleted_at IS NULL AND %[1]s = ANY(lots.photos))
+		OR EXISTS (SELECT 1 FROM storage_contracts sc WHERE sc.deleted_at IS NULL AND %[1]s = ANY(sc.photos))
+		OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.photo = %[1]s)
+	)`, urlExpr)
+}
+
+func (r *PhotoAssetRepo) IsReferenced(ctx context.Context, url string) (bool, error) {
+	var referenced bool
+	query := "SELECT " + photoReferencedSQL("p.url") + " FROM (SELECT CAST(? AS text) AS url) p"
+	if err := r.db.WithContext(ctx).Raw(query, url).Scan(&referenced).Error; err != nil {
+		return false, fmt.Errorf("failed to check photo references: %w", err)
+	}
+
+	return referenced, nil
+}
+
+func (r *PhotoAssetRepo) ListOrphans(ctx context.Context, uploadedBefore time.Time, limit int) ([]domain.PhotoUpload, error) {
+	var dbModels []models.PhotoAsset
+	err := r.db.WithContext(ctx).
+		Where("photo_assets.created_at < ?", uploadedBefore).
+		Where("NOT " + photoReferencedSQL("photo_assets.url")).
+		Order("photo_assets.created_at ASC").
+		Limit(limit).
+		Find(&dbModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orphaned photos: %w", err)
+	}
+
+	uploads := make([]domain.PhotoUpload, 0, len(dbModels))
+	for _, m := range dbModels {
+		uploads = append(uploads, domain.PhotoUpload{
+			URL:          m.URL,
+			UploadedByID: m.UploadedByID,
+			LotID:        m.LotID,
+			UploadedAt:   m.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return uploads, nil
+}
+
+func (r *PhotoAssetRepo) FilterUnregisteredOrphans(ctx context.Context, urls []string) ([]string, error) {
+	orphans := make([]string, 0)
+	if len(urls) == 0 {
+		return orphans, nil
+	}
+
+	query := "SELECT p.url FROM unnest(CAST(? AS text[])) AS p(url)" +
+		" WHERE NOT EXISTS (SELECT 1 FROM photo_assets pa WHERE pa.url = p.url)" +
+		" AND NOT " + photoReferencedSQL("p.url") +
+		" ORDER BY p.url"
+	if err := r.db.WithContext(ctx).Raw(query, pq.StringArray(urls)).Scan(&orphans).Error; err != nil {
+		return nil, fmt.Errorf("failed to filter unregistered photos: %w", err)
+	}
+
+	return orphans, nil
+}
+
+func (r *PhotoAssetRepo) DeleteByURL(ctx context.Context, url string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Where("url = ?", url).Delete(&models.PhotoAsset{}).Error; err != nil {
+		return fmt.Errorf("failed to delete photo asset: %w", err)
+	}
+
+	return nil
+}
+
+// loadPhotoAssets returns the rendition sets of the given photo URLs. Photos uploaded before renditions
+// existed get a bare asset with just the original URL, so clients can always rely on the structured list.
+func loadPhotoAssets(ctx context.Context, db *gorm.DB, urls []string) (map[string]domain.PhotoAsset, error) {
+	result := make(map[string]domain.PhotoAsset, len(urls))
+	if len(urls) == 0 {
+		return result, nil
+	}
+
+	var dbModels []models.PhotoAsset
+	if err := db.WithContext(ctx).Where("url IN ?", urls).Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch photo assets: %w", err)
+	}
+
+	for _, m := range dbModels {
+		result[m.URL] = mapToDomainPhotoAsset(m)
+	}
+
+	return result, nil
+}
+
+func photoImages(urls []string, assets map[string]domain.PhotoAsset) []domain.PhotoAsset {
+	images := make([]domain.PhotoAsset, 0, len(urls))
+	for _, url := range urls {
+		if asset, ok := assets[url]; ok {
+			images = append(images, asset)
+			continue
+		}
+		images = append(images, domain.PhotoAsset{URL: url, Renditions: []domain.PhotoRendition{}})
+	}
+
+	return images
+}
+
+func mapToDomainPhotoAsset(m models.PhotoAsset) domain.PhotoAsset {
+	renditions := []domain.PhotoRendition{}
+	if len(m.Renditions) > 0 {
+		_ = json.Unmarshal(m.Renditions, &renditions)
+	}
+
+	return domain.PhotoAsset{
+		URL:        m.URL,
+		Width:      m.Width,
+		Height:     m.Height,
+		Renditions: renditions,
+	}
+}
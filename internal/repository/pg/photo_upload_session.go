@@ -0,0 +1,109 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+	"gorm.io/gorm"
+)
+
+type PhotoUploadSessionRepo struct {
+	db *gorm.DB
+}
+
+func NewPhotoUploadSessionRepository(db *gorm.DB) domain.PhotoUploadSessionRepository {
+	return &PhotoUploadSessionRepo{db: db}
+}
+
+func (r *PhotoUploadSessionRepo) Create(ctx context.Context, session *domain.PhotoUploadSession, expiresAt time.Time) error {
+	dbModel := models.PhotoUploadSession{
+		ObjectKey:    session.ObjectKey,
+		ContentType:  session.ContentType,
+		Status:       string(session.Status),
+		UploadedByID: session.UploadedByID,
+		ExpiresAt:    expiresAt,
+	}
+	dbModel.ID = session.ID
+
+	if err := r.db.WithContext(ctx).Create(&dbModel).Error; err != nil {
+		return fmt.Errorf("failed to create photo upload session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PhotoUploadSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.PhotoUploadSession, error) {
+	var dbModel models.PhotoUploadSession
+	if err := r.db.WithContext(ctx).First(&dbModel, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("photo upload not found: %w", err)
+	}
+
+	session := mapToDomainPhotoUploadSession(dbModel)
+	return &session, nil
+}
+
+func (r *PhotoUploadSessionRepo) UpdateStatus(ctx context.Context, id uuid.UUID, from []domain.PhotoUploadStatus, status domain.PhotoUploadStatus, url string, errMsg string) error {
+	result := r.db.WithContext(ctx).Model(&models.PhotoUploadSession{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{
+			"status": status,
+			"url":    url,
+			"error":  errMsg,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update photo upload status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("photo upload cannot move to %s", status)
+	}
+
+	return nil
+}
+
+func (r *PhotoUploadSessionRepo) ListByStatus(ctx context.Context, status domain.PhotoUploadStatus) ([]domain.PhotoUploadSession, error) {
+	var dbModels []models.PhotoUploadSession
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at ASC").Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch photo uploads: %w", err)
+	}
+
+	return mapToDomainPhotoUploadSessions(dbModels), nil
+}
+
+// ListExpired returns pending sessions whose presigned URL has expired without a finalize call.
+func (r *PhotoUploadSessionRepo) ListExpired(ctx context.Context, now time.Time) ([]domain.PhotoUploadSession, error) {
+	var dbModels []models.PhotoUploadSession
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", domain.PhotoUploadStatusPending, now).
+		Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch expired photo uploads: %w", err)
+	}
+
+	return mapToDomainPhotoUploadSessions(dbModels), nil
+}
+
+func mapToDomainPhotoUploadSessions(dbModels []models.PhotoUploadSession) []domain.PhotoUploadSession {
+	sessions := make([]domain.PhotoUploadSession, 0, len(dbModels))
+	for _, m := range dbModels {
+		sessions = append(sessions, mapToDomainPhotoUploadSession(m))
+	}
+	return sessions
+}
+
+func mapToDomainPhotoUploadSession(m models.PhotoUploadSession) domain.PhotoUploadSession {
+	return domain.PhotoUploadSession{
+		ID:           m.ID,
+		Status:       domain.PhotoUploadStatus(m.Status),
+		ObjectKey:    m.ObjectKey,
+		ContentType:  m.ContentType,
+		UploadedByID: m.UploadedByID,
+		URL:          m.URL,
+		Error:        m.Error,
+		ExpiresAt:    m.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:    m.CreatedAt.Format("2006-01-02 15:04:05"),
+		Deadline:     m.ExpiresAt,
+	}
+}
@@ -0,0 +1,423 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type PromoCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewPromoCodeRepository(db *gorm.DB) domain.PromoCodeRepository {
+	return &PromoCodeRepo{db: db}
+}
+
+// promoCodeUsage is the usage of a promo code counted from its orders.
+type promoCodeUsage struct {
+	PromoCodeID   uuid.UUID
+	TimesUsed     int
+	TotalDiscount float64
+}
+
+func (r *PromoCodeRepo) Create(ctx context.Context, dto domain.CreatePromoCodeDTO, userID uuid.UUID) (*domain.PromoCodeResponse, error) {
+	startsAt, endsAt, err := parsePromoCodeDates(dto.StartsAt, dto.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	active := true
+	if dto.Active != nil {
+		active = *dto.Active
+	}
+
+	promo := models.PromoCode{
+		Code:          normalizePromoCode(dto.Code),
+		Description:   dto.Description,
+		Type:          string(dto.Type),
+		Value:         dto.Value,
+		MaxDiscount:   dto.MaxDiscount,
+		MinOrderTotal: dto.MinOrderTotal,
+		LotTypes:      pq.StringArray(dto.LotTypes),
+		Brands:        pq.StringArray(dto.Brands),
+		Conditions:    pq.StringArray(dto.Conditions),
+		StartsAt:      startsAt,
+		EndsAt:        endsAt,
+		UsageLimit:    dto.UsageLimit,
+		PerUserLimit:  dto.PerUserLimit,
+		Active:        active,
+		CreatedByID:   userID,
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleted codes keep their row, so the unique index covers them too.
+		var taken int64
+		if err := tx.Unscoped().Model(&models.PromoCode{}).Where("code = ?", promo.Code).Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check promo code: %w", err)
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %s", domain.ErrPromoCodeExists, promo.Code)
+		}
+
+		if err := tx.Create(&promo).Error; err != nil {
+			return fmt.Errorf("failed to create promo code: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapPromoCodeModel(promo, promoCodeUsage{})
+	return &response, nil
+}
+
+func (r *PromoCodeRepo) Update(ctx context.Context, id uuid.UUID, dto domain.UpdatePromoCodeDTO) (*domain.PromoCodeResponse, error) {
+	startsAt, endsAt, err := parsePromoCodeDates(dto.StartsAt, dto.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var promo models.PromoCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promo, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("promo code not found: %w", err)
+		}
+
+		updates := map[string]interface{}{
+			"description":     dto.Description,
+			"type":            string(dto.Type),
+			"value":           dto.Value,
+			"max_discount":    dto.MaxDiscount,
+			"min_order_total": dto.MinOrderTotal,
+			"lot_types":       pq.StringArray(dto.LotTypes),
+			"brands":          pq.StringArray(dto.Brands),
+			"conditions":      pq.StringArray(dto.Conditions),
+			"starts_at":       startsAt,
+			"ends_at":         endsAt,
+			"usage_limit":     dto.UsageLimit,
+			"per_user_limit":  dto.PerUserLimit,
+			"active":          dto.Active,
+		}
+		if err := tx.Model(&promo).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update promo code: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *PromoCodeRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.PromoCodeResponse, error) {
+	var promo models.PromoCode
+	if err := r.db.WithContext(ctx).First(&promo, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch promo code: %w", err)
+	}
+
+	usage, err := r.loadUsage(ctx, []uuid.UUID{promo.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapPromoCodeModel(promo, usage[promo.ID])
+	return &response, nil
+}
+
+func (r *PromoCodeRepo) List(ctx context.Context, filter domain.PromoCodeFilter) ([]domain.PromoCodeResponse, int64, error) {
+	var dbPromos []models.PromoCode
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.PromoCode{})
+	if filter.Search != "" {
+		query = query.Where("code LIKE ?", normalizePromoCode(filter.Search)+"%")
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count promo codes: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(filter.PageSize).Find(&dbPromos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch promo codes: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(dbPromos))
+	for _, promo := range dbPromos {
+		ids = append(ids, promo.ID)
+	}
+	usage, err := r.loadUsage(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]domain.PromoCodeResponse, 0, len(dbPromos))
+	for _, promo := range dbPromos {
+		responses = append(responses, mapPromoCodeModel(promo, usage[promo.ID]))
+	}
+
+	return responses, total, nil
+}
+
+// GetUsage lists every order placed with the promo code, cancelled ones included so staff can see them,
+// while the totals only count orders that were not cancelled.
+func (r *PromoCodeRepo) GetUsage(ctx context.Context, id uuid.UUID) (*domain.PromoCodeUsageReport, error) {
+	promo, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []models.Order
+	if err := r.db.WithContext(ctx).
+		Where("promo_code_id = ?", id).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch promo code orders: %w", err)
+	}
+
+	report := &domain.PromoCodeUsageReport{
+		PromoCode: *promo,
+		Usage:     make([]domain.PromoCodeUsageOrder, 0, len(orders)),
+	}
+	buyers := make(map[string]struct{})
+
+	for _, order := range orders {
+		report.Usage = append(report.Usage, domain.PromoCodeUsageOrder{
+			OrderID:        order.ID,
+			CustomerName:   order.CustomerName,
+			CustomerPhone:  order.CustomerPhone,
+			Status:         order.Status,
+			DiscountAmount: order.DiscountAmount,
+			TotalAmount:    order.TotalAmount,
+			CreatedAt:      order.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+
+		if order.Status == string(domain.OrderStatusCancelled) {
+			continue
+		}
+		report.Orders++
+		if order.Status == string(domain.OrderStatusDone) {
+			report.CompletedOrders++
+		}
+		report.Revenue += order.TotalAmount
+		report.TotalDiscount += order.DiscountAmount
+
+		buyer := order.CustomerPhone
+		if order.UserID != nil {
+			buyer = order.UserID.String()
+		}
+		buyers[buyer] = struct{}{}
+	}
+	report.UniqueBuyers = len(buyers)
+
+	return report, nil
+}
+
+func (r *PromoCodeRepo) loadUsage(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]promoCodeUsage, error) {
+	usage := make(map[uuid.UUID]promoCodeUsage, len(ids))
+	if len(ids) == 0 {
+		return usage, nil
+	}
+
+	var rows []promoCodeUsage
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Select("promo_code_id, COUNT(*) AS times_used, COALESCE(SUM(discount_amount), 0) AS total_discount").
+		Where("promo_code_id IN ? AND status <> ?", ids, string(domain.OrderStatusCancelled)).
+		Group("promo_code_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count promo code usage: %w", err)
+	}
+
+	for _, row := range rows {
+		usage[row.PromoCodeID] = row
+	}
+	return usage, nil
+}
+
+// redeemPromoCode locks the promo code and checks that it can be used for a new order right now. Locking
+// the code row serializes concurrent redemptions, so usage limits cannot be overrun.
+func redeemPromoCode(tx *gorm.DB, code string, userID *uuid.UUID, customerPhone string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promo, "code = ?", normalizePromoCode(code)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: code %s does not exist", domain.ErrPromoCodeInvalid, code)
+		}
+		return nil, fmt.Errorf("failed to fetch promo code: %w", err)
+	}
+
+	if !promo.Active {
+		return nil, fmt.Errorf("%w: code %s is not active", domain.ErrPromoCodeInvalid, promo.Code)
+	}
+	day := today()
+	if promo.StartsAt != nil && day.Before(*promo.StartsAt) {
+		return nil, fmt.Errorf("%w: code %s is valid from %s", domain.ErrPromoCodeInvalid, promo.Code, promo.StartsAt.Format(domain.PromoCodeDateLayout))
+	}
+	if promo.EndsAt != nil && day.After(*promo.EndsAt) {
+		return nil, fmt.Errorf("%w: code %s expired on %s", domain.ErrPromoCodeInvalid, promo.Code, promo.EndsAt.Format(domain.PromoCodeDateLayout))
+	}
+
+	used := tx.Model(&models.Order{}).Where("promo_code_id = ? AND status <> ?", promo.ID, string(domain.OrderStatusCancelled))
+
+	if promo.UsageLimit > 0 {
+		var count int64
+		if err := used.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count promo code usage: %w", err)
+		}
+		if count >= int64(promo.UsageLimit) {
+			return nil, fmt.Errorf("%w: code %s has been used up", domain.ErrPromoCodeInvalid, promo.Code)
+		}
+	}
+
+	if promo.PerUserLimit > 0 {
+		byBuyer := used.Session(&gorm.Session{})
+		switch {
+		case userID != nil:
+			byBuyer = byBuyer.Where("user_id = ?", *userID)
+		case customerPhone != "":
+			byBuyer = byBuyer.Where("customer_phone = ?", customerPhone)
+		default:
+			return nil, fmt.Errorf("%w: code %s needs a known buyer", domain.ErrPromoCodeInvalid, promo.Code)
+		}
+
+		var count int64
+		if err := byBuyer.Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count promo code usage: %w", err)
+		}
+		if count >= int64(promo.PerUserLimit) {
+			return nil, fmt.Errorf("%w: code %s was already used by this buyer", domain.ErrPromoCodeInvalid, promo.Code)
+		}
+	}
+
+	return &promo, nil
+}
+
+// applyPromoDiscount sets the discount of every item from the promo rules and returns the order discount.
+// Lot type and condition are not snapshotted on the item, so they are read from the lots.
+func applyPromoDiscount(tx *gorm.DB, promo *models.PromoCode, items []models.OrderItem) (float64, error) {
+	lotIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		lotIDs = append(lotIDs, item.LotID)
+	}
+
+	var lots []models.Lot
+	if err := tx.Unscoped().Select("id", "type", "condition").Where("id IN ?", lotIDs).Find(&lots).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch lots for promo code: %w", err)
+	}
+	lotsByID := make(map[uuid.UUID]models.Lot, len(lots))
+	for _, lot := range lots {
+		lotsByID[lot.ID] = lot
+	}
+
+	lines := make([]domain.PromoLine, 0, len(items))
+	for _, item := range items {
+		lot := lotsByID[item.LotID]
+		lines = append(lines, domain.PromoLine{
+			LotType:   string(lot.Type),
+			Brand:     item.Brand,
+			Condition: string(lot.Condition),
+			Amount:    item.PriceAtMoment * float64(item.Quantity),
+		})
+	}
+
+	shares, total, err := promoRules(promo).Discount(lines)
+	if err != nil {
+		return 0, err
+	}
+	for i := range items {
+		items[i].DiscountAmount = shares[i]
+	}
+	return total, nil
+}
+
+func promoRules(promo *models.PromoCode) domain.PromoRules {
+	return domain.PromoRules{
+		Type:          domain.PromoDiscountType(promo.Type),
+		Value:         promo.Value,
+		MaxDiscount:   promo.MaxDiscount,
+		MinOrderTotal: promo.MinOrderTotal,
+		LotTypes:      promo.LotTypes,
+		Brands:        promo.Brands,
+		Conditions:    promo.Conditions,
+	}
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func parsePromoCodeDates(startsAt, endsAt string) (*time.Time, *time.Time, error) {
+	parse := func(field, value string) (*time.Time, error) {
+		if value == "" {
+			return nil, nil
+		}
+		date, err := time.Parse(domain.PromoCodeDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field, err)
+		}
+		return &date, nil
+	}
+
+	starts, err := parse("starts_at", startsAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	ends, err := parse("ends_at", endsAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if starts != nil && ends != nil && ends.Before(*starts) {
+		return nil, nil, fmt.Errorf("ends_at must not be before starts_at")
+	}
+	return starts, ends, nil
+}
+
+func mapPromoCodeModel(promo models.PromoCode, usage promoCodeUsage) domain.PromoCodeResponse {
+	response := domain.PromoCodeResponse{
+		ID:            promo.ID,
+		Code:          promo.Code,
+		Description:   promo.Description,
+		Type:          domain.PromoDiscountType(promo.Type),
+		Value:         promo.Value,
+		MaxDiscount:   promo.MaxDiscount,
+		MinOrderTotal: promo.MinOrderTotal,
+		LotTypes:      nonNilStrings(promo.LotTypes),
+		Brands:        nonNilStrings(promo.Brands),
+		Conditions:    nonNilStrings(promo.Conditions),
+		UsageLimit:    promo.UsageLimit,
+		PerUserLimit:  promo.PerUserLimit,
+		Active:        promo.Active,
+		TimesUsed:     usage.TimesUsed,
+		TotalDiscount: usage.TotalDiscount,
+		CreatedAt:     promo.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if promo.StartsAt != nil {
+		response.StartsAt = promo.StartsAt.Format(domain.PromoCodeDateLayout)
+	}
+	if promo.EndsAt != nil {
+		response.EndsAt = promo.EndsAt.Format(domain.PromoCodeDateLayout)
+	}
+	return response
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/horoshi10v/tires-shop/internal/domain"
@@ -76,11 +77,13 @@ func buildAnalyticsGrouping(groupBy domain.LotAnalyticsGroupBy) (string, string)
 func (r *ReportRepo) GetPnL(ctx context.Context, filter domain.ReportFilter) (*domain.PnLReport, error) {
 	query := `
 		SELECT 
+			w.id as warehouse_id,
 			w.name as warehouse_name,
 			COALESCE(SUM(oi.quantity), 0) as items_sold,
-			COALESCE(SUM(oi.quantity * oi.price_at_moment), 0) as revenue,
+			COALESCE(SUM(oi.quantity * oi.price_at_moment - oi.discount_amount), 0) as revenue,
 			COALESCE(SUM(oi.quantity * oi.cost_at_moment), 0) as cogs,
-			COALESCE(SUM(oi.quantity * (oi.price_at_moment - oi.cost_at_moment)), 0) as profit
+			COALESCE(SUM(oi.quantity * (oi.price_at_moment - oi.cost_at_moment) - oi.discount_amount), 0) as profit,
+			COALESCE(SUM(oi.discount_amount), 0) as discounts
 		FROM order_items oi
 		JOIN orders o ON o.id = oi.order_id
 		JOIN lots l ON oi.lot_id = l.id
@@ -124,9 +127,10 @@ func (r *ReportRepo) GetPnL(ctx context.Context, filter domain.ReportFilter) (*d
 		SELECT
 			o.channel as channel,
 			COALESCE(SUM(oi.quantity), 0) as items_sold,
-			COALESCE(SUM(oi.quantity * oi.price_at_moment), 0) as revenue,
+			COALESCE(SUM(oi.quantity * oi.price_at_moment - oi.discount_amount), 0) as revenue,
 			COALESCE(SUM(oi.quantity * oi.cost_at_moment), 0) as cogs,
-			COALESCE(SUM(oi.quantity * (oi.price_at_moment - oi.cost_at_moment)), 0) as profit
+			COALESCE(SUM(oi.quantity * (oi.price_at_moment - oi.cost_at_moment) - oi.discount_amount), 0) as profit,
+			COALESCE(SUM(oi.discount_amount), 0) as discounts
 		FROM order_items oi
 		JOIN orders o ON o.id = oi.order_id
 		JOIN lots l ON oi.lot_id = l.id
@@ -158,6 +162,56 @@ func (r *ReportRepo) GetPnL(ctx context.Context, filter domain.ReportFilter) (*d
 		return nil, err
 	}
 
+	// Returns are booked in the period they happened, not in the period of the original sale.
+	// Warehouses are matched by ID, names are not unique.
+	warehouseReturns, err := r.getPnLReturns(ctx, filter, "w.id::text", "w.name")
+	if err != nil {
+		return nil, err
+	}
+	channelReturns, err := r.getPnLReturns(ctx, filter, "o.channel", "o.channel")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ret := range warehouseReturns {
+		idx := -1
+		for i := range warehousePnLs {
+			if warehousePnLs[i].WarehouseID.String() == ret.GroupKey {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			warehouseID, _ := uuid.Parse(ret.GroupKey)
+			warehousePnLs = append(warehousePnLs, domain.WarehousePnL{WarehouseID: warehouseID, WarehouseName: ret.GroupLabel})
+			idx = len(warehousePnLs) - 1
+		}
+		row := &warehousePnLs[idx]
+		row.ItemsSold -= ret.ItemsReturned
+		row.Revenue -= ret.Refunds
+		row.COGS -= ret.RestockedCOGS
+		row.Profit = row.Revenue - row.COGS
+	}
+
+	for _, ret := range channelReturns {
+		idx := -1
+		for i := range channelPnLs {
+			if string(channelPnLs[i].Channel) == ret.GroupKey {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			channelPnLs = append(channelPnLs, domain.ChannelPnL{Channel: domain.OrderChannel(ret.GroupKey)})
+			idx = len(channelPnLs) - 1
+		}
+		row := &channelPnLs[idx]
+		row.ItemsSold -= ret.ItemsReturned
+		row.Revenue -= ret.Refunds
+		row.COGS -= ret.RestockedCOGS
+		row.Profit = row.Revenue - row.COGS
+	}
+
 	report := &domain.PnLReport{
 		ByWarehouse:    warehousePnLs,
 		ByChannel:      channelPnLs,
@@ -167,16 +221,74 @@ func (r *ReportRepo) GetPnL(ctx context.Context, filter domain.ReportFilter) (*d
 		TotalProfit:    0,
 	}
 
+	for _, ret := range warehouseReturns {
+		report.TotalItemsReturned += ret.ItemsReturned
+		report.TotalRefunds += ret.Refunds
+	}
+
 	for _, wpnl := range warehousePnLs {
 		report.TotalItemsSold += wpnl.ItemsSold
 		report.TotalRevenue += wpnl.Revenue
 		report.TotalCOGS += wpnl.COGS
 		report.TotalProfit += wpnl.Profit
+		report.TotalDiscounts += wpnl.Discounts
 	}
 
 	return report, nil
 }
 
+// pnlReturnsRow is the P&L correction from order returns for one warehouse or channel.
+type pnlReturnsRow struct {
+	GroupKey      string
+	GroupLabel    string
+	ItemsReturned int
+	Refunds       float64
+	RestockedCOGS float64 // Written-off units stay in COGS as a loss
+}
+
+// getPnLReturns sums returns grouped by groupExpr, filtered by the return date. labelExpr names the group.
+func (r *ReportRepo) getPnLReturns(ctx context.Context, filter domain.ReportFilter, groupExpr, labelExpr string) ([]pnlReturnsRow, error) {
+	query := `
+		SELECT
+			` + groupExpr + ` as group_key,
+			` + labelExpr + ` as group_label,
+			COALESCE(SUM(ri.quantity), 0) as items_returned,
+			COALESCE(SUM(ri.refund_amount), 0) as refunds,
+			COALESCE(SUM(CASE WHEN ri.disposition = 'RESTOCK' THEN ri.cost_amount ELSE 0 END), 0) as restocked_cogs
+		FROM order_return_items ri
+		JOIN order_returns r ON r.id = ri.return_id
+		JOIN orders o ON o.id = r.order_id
+		JOIN lots l ON ri.lot_id = l.id
+		JOIN warehouses w ON l.warehouse_id = w.id
+		WHERE ri.deleted_at IS NULL AND r.deleted_at IS NULL AND o.deleted_at IS NULL
+	`
+
+	var args []interface{}
+	if filter.StartDate != nil {
+		query += " AND r.created_at >= ?"
+		args = append(args, *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query += " AND r.created_at <= ?"
+		args = append(args, *filter.EndDate)
+	}
+	if filter.WarehouseID != nil {
+		query += " AND w.id = ?"
+		args = append(args, *filter.WarehouseID)
+	}
+	if filter.Channel != nil {
+		query += " AND o.channel = ?"
+		args = append(args, string(*filter.Channel))
+	}
+	query += " GROUP BY " + groupExpr + ", " + labelExpr
+
+	var rows []pnlReturnsRow
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
 func (r *ReportRepo) GetLotAnalytics(ctx context.Context, filter domain.ReportFilter) (*domain.LotAnalyticsReport, error) {
 	analyticsConditions, analyticsArgs := buildAnalyticsConditions(filter)
 	groupExpr, groupLabelExpr := buildAnalyticsGrouping(filter.GroupBy)
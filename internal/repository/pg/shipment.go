@@ -0,0 +1,157 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type ShipmentRepo struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) domain.ShipmentRepository {
+	return &ShipmentRepo{db: db}
+}
+
+// Create stores the waybill and copies its tracking number onto the order in one transaction.
+func (r *ShipmentRepo) Create(ctx context.Context, record domain.CreateShipmentRecord) (*domain.ShipmentResponse, error) {
+	parcels, err := json.Marshal(record.Parcels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parcels: %w", err)
+	}
+
+	shipment := models.Shipment{
+		OrderID:           record.OrderID,
+		Provider:          record.Provider,
+		ProviderRef:       record.Waybill.Ref,
+		TrackingNumber:    record.Waybill.TrackingNumber,
+		RecipientName:     record.RecipientName,
+		RecipientPhone:    record.RecipientPhone,
+		CityRef:           record.CityRef,
+		CityName:          record.CityName,
+		BranchRef:         record.BranchRef,
+		BranchName:        record.BranchName,
+		Parcels:           datatypes.JSON(parcels),
+		Cost:              record.Waybill.Cost,
+		CashOnDelivery:    record.CashOnDelivery,
+		EstimatedDelivery: record.Waybill.EstimatedDelivery,
+		State:             string(domain.ShipmentStateCreated),
+		CreatedByID:       record.CreatedByID,
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&shipment).Error; err != nil {
+			return fmt.Errorf("failed to create shipment: %w", err)
+		}
+		if err := tx.Model(&models.Order{}).
+			Where("id = ?", record.OrderID).
+			Update("tracking_number", shipment.TrackingNumber).Error; err != nil {
+			return fmt.Errorf("failed to store tracking number on order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapToDomainShipment(shipment), nil
+}
+
+func (r *ShipmentRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.ShipmentResponse, error) {
+	var shipment models.Shipment
+	if err := r.db.WithContext(ctx).First(&shipment, "order_id = ?", orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch shipment: %w", err)
+	}
+	return mapToDomainShipment(shipment), nil
+}
+
+// ListActive returns shipments that are still moving, least recently checked first.
+func (r *ShipmentRepo) ListActive(ctx context.Context, provider string, limit int) ([]domain.ShipmentResponse, error) {
+	finalStates := []string{
+		string(domain.ShipmentStateDelivered),
+		string(domain.ShipmentStateReturned),
+		string(domain.ShipmentStateCancelled),
+	}
+
+	var shipments []models.Shipment
+	if err := r.db.WithContext(ctx).
+		Where("provider = ? AND state NOT IN ?", provider, finalStates).
+		Order("last_checked_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&shipments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list active shipments: %w", err)
+	}
+
+	result := make([]domain.ShipmentResponse, 0, len(shipments))
+	for _, shipment := range shipments {
+		result = append(result, *mapToDomainShipment(shipment))
+	}
+	return result, nil
+}
+
+func (r *ShipmentRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.TrackingStatus) error {
+	if err := r.db.WithContext(ctx).Model(&models.Shipment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"state":           string(status.State),
+		"status_code":     status.StatusCode,
+		"status":          status.Status,
+		"last_checked_at": time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update shipment status: %w", err)
+	}
+	return nil
+}
+
+func (r *ShipmentRepo) MarkChecked(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Shipment{}).
+		Where("id IN ?", ids).
+		Update("last_checked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark shipments checked: %w", err)
+	}
+	return nil
+}
+
+func mapToDomainShipment(m models.Shipment) *domain.ShipmentResponse {
+	var parcels []domain.ShippingParcel
+	_ = json.Unmarshal(m.Parcels, &parcels)
+
+	lastCheckedAt := ""
+	if m.LastCheckedAt != nil {
+		lastCheckedAt = m.LastCheckedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return &domain.ShipmentResponse{
+		ID:                m.ID,
+		OrderID:           m.OrderID,
+		Provider:          m.Provider,
+		TrackingNumber:    m.TrackingNumber,
+		RecipientName:     m.RecipientName,
+		RecipientPhone:    m.RecipientPhone,
+		CityName:          m.CityName,
+		BranchName:        m.BranchName,
+		Parcels:           parcels,
+		Cost:              m.Cost,
+		CashOnDelivery:    m.CashOnDelivery,
+		EstimatedDelivery: m.EstimatedDelivery,
+		State:             domain.ShipmentState(m.State),
+		StatusCode:        m.StatusCode,
+		Status:            m.Status,
+		LastCheckedAt:     lastCheckedAt,
+		CreatedAt:         m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,327 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+const storageContractDateLayout = "2006-01-02"
+
+type StorageContractRepo struct {
+	db *gorm.DB
+}
+
+func NewStorageContractRepository(db *gorm.DB) domain.StorageContractRepository {
+	return &StorageContractRepo{db: db}
+}
+
+func (r *StorageContractRepo) Create(ctx context.Context, dto domain.CreateStorageContractDTO, createdByID uuid.UUID) (uuid.UUID, error) {
+	startDate, err := time.Parse(storageContractDateLayout, dto.StartDate)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid start_date: %w", err)
+	}
+	endDate, err := time.Parse(storageContractDateLayout, dto.EndDate)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid end_date: %w", err)
+	}
+
+	if dto.CustomerTelegramID != nil && *dto.CustomerTelegramID == 0 {
+		dto.CustomerTelegramID = nil
+	}
+
+	contract := models.StorageContract{
+		CustomerName:       dto.CustomerName,
+		CustomerPhone:      dto.CustomerPhone,
+		CustomerTelegramID: dto.CustomerTelegramID,
+		VehiclePlate:       dto.VehiclePlate,
+		TireDescription:    dto.TireDescription,
+		Quantity:           dto.Quantity,
+		Photos:             dto.Photos,
+		WarehouseID:        dto.WarehouseID,
+		BinLocation:        dto.BinLocation,
+		StartDate:          startDate,
+		EndDate:            endDate,
+		Price:              dto.Price,
+		Status:             string(domain.StorageContractStatusStored),
+		Comment:            dto.Comment,
+		CreatedByID:        createdByID,
+	}
+
+	if err := r.db.WithContext(ctx).Create(&contract).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create storage contract: %w", err)
+	}
+
+	return contract.ID, nil
+}
+
+func (r *StorageContractRepo) Update(ctx context.Context, id uuid.UUID, dto domain.UpdateStorageContractDTO) error {
+	updates := map[string]interface{}{}
+
+	if dto.CustomerName != nil {
+		updates["customer_name"] = *dto.CustomerName
+	}
+	if dto.CustomerPhone != nil {
+		updates["customer_phone"] = *dto.CustomerPhone
+	}
+	if dto.CustomerTelegramID != nil {
+		if *dto.CustomerTelegramID == 0 {
+			updates["customer_telegram_id"] = nil
+		} else {
+			updates["customer_telegram_id"] = *dto.CustomerTelegramID
+		}
+	}
+	if dto.VehiclePlate != nil {
+		updates["vehicle_plate"] = *dto.VehiclePlate
+	}
+	if dto.TireDescription != nil {
+		updates["tire_description"] = *dto.TireDescription
+	}
+	if dto.Quantity != nil {
+		updates["quantity"] = *dto.Quantity
+	}
+	if dto.Photos != nil {
+		updates["photos"] = pq.StringArray(dto.Photos)
+	}
+	if dto.WarehouseID != nil {
+		updates["warehouse_id"] = *dto.WarehouseID
+	}
+	if dto.BinLocation != nil {
+		updates["bin_location"] = *dto.BinLocation
+	}
+	if dto.EndDate != nil {
+		endDate, err := time.Parse(storageContractDateLayout, *dto.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid end_date: %w", err)
+		}
+		updates["end_date"] = endDate
+		// A new end date means a new season, so the customer should be reminded again.
+		updates["reminder_sent_at"] = nil
+		updates["overdue_reminder_sent_at"] = nil
+	}
+	if dto.Price != nil {
+		updates["price"] = *dto.Price
+	}
+	if dto.Comment != nil {
+		updates["comment"] = *dto.Comment
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var contract models.StorageContract
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&contract, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("storage contract not found: %w", err)
+		}
+
+		if contract.Status == string(domain.StorageContractStatusReturned) {
+			return fmt.Errorf("cannot update a returned storage contract")
+		}
+
+		// Extending an overdue contract puts the tires back into regular storage.
+		if endDate, ok := updates["end_date"].(time.Time); ok && contract.Status == string(domain.StorageContractStatusOverdue) && !endDate.Before(today()) {
+			updates["status"] = string(domain.StorageContractStatusStored)
+		}
+
+		if err := tx.Model(&contract).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update storage contract: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *StorageContractRepo) MarkReturned(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var contract models.StorageContract
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&contract, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("storage contract not found: %w", err)
+		}
+
+		if contract.Status == string(domain.StorageContractStatusReturned) {
+			return fmt.Errorf("storage contract is already returned")
+		}
+
+		now := time.Now().UTC()
+		contract.Status = string(domain.StorageContractStatusReturned)
+		contract.ReturnedAt = &now
+		contract.ReturnedByID = &userID
+		if comment != "" {
+			contract.Comment = comment
+		}
+
+		if err := tx.Save(&contract).Error; err != nil {
+			return fmt.Errorf("failed to return storage contract: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *StorageContractRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&models.StorageContract{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete storage contract: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("storage contract not found")
+	}
+	return nil
+}
+
+func (r *StorageContractRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.StorageContractResponse, error) {
+	var contract models.StorageContract
+	if err := r.db.WithContext(ctx).First(&contract, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("storage contract not found: %w", err)
+	}
+
+	response := mapStorageContractModel(contract)
+	return &response, nil
+}
+
+func (r *StorageContractRepo) List(ctx context.Context, filter domain.StorageContractFilter) ([]domain.StorageContractResponse, int64, error) {
+	var contracts []models.StorageContract
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.StorageContract{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Search != "" {
+		searchTerm := "%" + filter.Search + "%"
+		query = query.Where("customer_name ILIKE ? OR customer_phone ILIKE ? OR vehicle_plate ILIKE ?", searchTerm, searchTerm, searchTerm)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count storage contracts: %w", err)
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if err := query.Order("end_date ASC").Order("created_at DESC").Offset(offset).Limit(filter.PageSize).Find(&contracts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch storage contracts: %w", err)
+	}
+
+	responses := make([]domain.StorageContractResponse, 0, len(contracts))
+	for _, contract := range contracts {
+		responses = append(responses, mapStorageContractModel(contract))
+	}
+
+	return responses, total, nil
+}
+
+// MarkOverdue flags every stored contract whose end date has already passed.
+func (r *StorageContractRepo) MarkOverdue(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.StorageContract{}).
+		Where("status = ? AND end_date < ?", domain.StorageContractStatusStored, today()).
+		Update("status", string(domain.StorageContractStatusOverdue))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark overdue storage contracts: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListDueForReminder returns active contracts that still need a bot reminder. The pre-end and the overdue
+// reminders are tracked apart, so a customer reminded before the end date is still told once it has passed.
+func (r *StorageContractRepo) ListDueForReminder(ctx context.Context, endDateBefore string) ([]domain.StorageContractResponse, error) {
+	var contracts []models.StorageContract
+
+	if err := r.db.WithContext(ctx).
+		Where(
+			"(status = ? AND end_date <= ? AND reminder_sent_at IS NULL) OR (status = ? AND overdue_reminder_sent_at IS NULL)",
+			domain.StorageContractStatusStored, endDateBefore, domain.StorageContractStatusOverdue,
+		).
+		Where("customer_telegram_id IS NOT NULL AND customer_telegram_id <> 0").
+		Order("end_date ASC").
+		Find(&contracts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch storage contracts for reminders: %w", err)
+	}
+
+	responses := make([]domain.StorageContractResponse, 0, len(contracts))
+	for _, contract := range contracts {
+		responses = append(responses, mapStorageContractModel(contract))
+	}
+
+	return responses, nil
+}
+
+func (r *StorageContractRepo) MarkReminderSent(ctx context.Context, id uuid.UUID, overdue bool) error {
+	column := "reminder_sent_at"
+	if overdue {
+		column = "overdue_reminder_sent_at"
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.StorageContract{}).
+		Where("id = ?", id).
+		Update(column, time.Now().UTC()).Error; err != nil {
+		return fmt.Errorf("failed to mark storage contract reminder as sent: %w", err)
+	}
+	return nil
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func mapStorageContractModel(contract models.StorageContract) domain.StorageContractResponse {
+	var returnedAt *string
+	if contract.ReturnedAt != nil {
+		formatted := contract.ReturnedAt.Format("2006-01-02 15:04:05")
+		returnedAt = &formatted
+	}
+
+	var reminderSentAt *string
+	if contract.ReminderSentAt != nil {
+		formatted := contract.ReminderSentAt.Format("2006-01-02 15:04:05")
+		reminderSentAt = &formatted
+	}
+
+	var overdueReminderSentAt *string
+	if contract.OverdueReminderSentAt != nil {
+		formatted := contract.OverdueReminderSentAt.Format("2006-01-02 15:04:05")
+		overdueReminderSentAt = &formatted
+	}
+
+	photos := []string(contract.Photos)
+	if photos == nil {
+		photos = []string{}
+	}
+
+	return domain.StorageContractResponse{
+		ID:                    contract.ID,
+		CustomerName:          contract.CustomerName,
+		CustomerPhone:         contract.CustomerPhone,
+		CustomerTelegramID:    contract.CustomerTelegramID,
+		VehiclePlate:          contract.VehiclePlate,
+		TireDescription:       contract.TireDescription,
+		Quantity:              contract.Quantity,
+		Photos:                photos,
+		WarehouseID:           contract.WarehouseID,
+		BinLocation:           contract.BinLocation,
+		StartDate:             contract.StartDate.Format(storageContractDateLayout),
+		EndDate:               contract.EndDate.Format(storageContractDateLayout),
+		Price:                 contract.Price,
+		Status:                domain.StorageContractStatus(contract.Status),
+		Comment:               contract.Comment,
+		CreatedBy:             contract.CreatedByID,
+		ReturnedAt:            returnedAt,
+		ReminderSentAt:        reminderSentAt,
+		OverdueReminderSentAt: overdueReminderSentAt,
+		CreatedAt:             contract.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -110,6 +110,7 @@ func (r *TransferRepo) AcceptTransferTx(ctx context.Context, transferID uuid.UUI
 				Condition:       sourceLot.Condition,
 				Brand:           sourceLot.Brand,
 				Model:           sourceLot.Model,
+				Barcode:         sourceLot.Barcode,
 				Params:          sourceLot.Params,
 				Defects:         sourceLot.Defects,
 				Photos:          sourceLot.Photos,
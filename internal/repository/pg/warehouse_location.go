@@ -0,0 +1,296 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/repository/models"
+)
+
+type WarehouseLocationRepo struct {
+	db *gorm.DB
+}
+
+func NewWarehouseLocationRepository(db *gorm.DB) domain.WarehouseLocationRepository {
+	return &WarehouseLocationRepo{db: db}
+}
+
+func (r *WarehouseLocationRepo) Create(ctx context.Context, warehouseID uuid.UUID, dto domain.CreateWarehouseLocationDTO) (uuid.UUID, error) {
+	var locationID uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var warehouse models.Warehouse
+		if err := tx.First(&warehouse, "id = ?", warehouseID).Error; err != nil {
+			return fmt.Errorf("warehouse not found: %w", err)
+		}
+
+		path := dto.Code
+		if dto.ParentID != nil {
+			var parent models.WarehouseLocation
+			if err := tx.First(&parent, "id = ?", *dto.ParentID).Error; err != nil {
+				return fmt.Errorf("parent location not found: %w", err)
+			}
+			if parent.WarehouseID != warehouseID {
+				return fmt.Errorf("parent location belongs to another warehouse")
+			}
+			path = parent.Path + "/" + dto.Code
+		}
+
+		if err := ensureLocationCodeIsFree(tx, warehouseID, dto.Code, uuid.Nil); err != nil {
+			return err
+		}
+
+		location := models.WarehouseLocation{
+			WarehouseID: warehouseID,
+			ParentID:    dto.ParentID,
+			Type:        dto.Type,
+			Code:        dto.Code,
+			Name:        dto.Name,
+			Path:        path,
+		}
+		if err := tx.Create(&location).Error; err != nil {
+			return fmt.Errorf("failed to create warehouse location: %w", err)
+		}
+
+		locationID = location.ID
+		return nil
+	})
+
+	return locationID, err
+}
+
+func (r *WarehouseLocationRepo) Update(ctx context.Context, id uuid.UUID, dto domain.UpdateWarehouseLocationDTO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var location models.WarehouseLocation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&location, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("warehouse location not found: %w", err)
+		}
+
+		updates := map[string]interface{}{}
+		if dto.Name != nil {
+			updates["name"] = *dto.Name
+		}
+
+		if dto.Code != nil && *dto.Code != location.Code {
+			if err := ensureLocationCodeIsFree(tx, location.WarehouseID, *dto.Code, location.ID); err != nil {
+				return err
+			}
+
+			oldPath := location.Path
+			newPath := *dto.Code
+			if location.ParentID != nil {
+				var parent models.WarehouseLocation
+				if err := tx.First(&parent, "id = ?", *location.ParentID).Error; err != nil {
+					return fmt.Errorf("parent location not found: %w", err)
+				}
+				newPath = parent.Path + "/" + *dto.Code
+			}
+
+			updates["code"] = *dto.Code
+			updates["path"] = newPath
+
+			// Rewrite the path prefix of every nested location.
+			prefix := oldPath + "/"
+			if err := tx.Model(&models.WarehouseLocation{}).
+				Where("warehouse_id = ? AND left(path, ?) = ?", location.WarehouseID, utf8.RuneCountInString(prefix), prefix).
+				Update("path", gorm.Expr("? || substr(path, ?)", newPath+"/", utf8.RuneCountInString(prefix)+1)).Error; err != nil {
+				return fmt.Errorf("failed to update nested location paths: %w", err)
+			}
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&location).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update warehouse location: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *WarehouseLocationRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var location models.WarehouseLocation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&location, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("warehouse location not found: %w", err)
+		}
+
+		var childrenCount int64
+		if err := tx.Model(&models.WarehouseLocation{}).Where("parent_id = ?", id).Count(&childrenCount).Error; err != nil {
+			return fmt.Errorf("failed to count nested locations: %w", err)
+		}
+		if childrenCount > 0 {
+			return fmt.Errorf("cannot delete location: it still contains %d nested locations", childrenCount)
+		}
+
+		var activeLotsCount int64
+		if err := tx.Model(&models.Lot{}).
+			Where("location_id = ? AND current_quantity > 0", id).
+			Count(&activeLotsCount).Error; err != nil {
+			return fmt.Errorf("failed to count lots in location: %w", err)
+		}
+		if activeLotsCount > 0 {
+			return fmt.Errorf("cannot delete location: %d active lots are still stored here", activeLotsCount)
+		}
+
+		// Sold out lots keep no reference to a location that no longer exists.
+		if err := tx.Model(&models.Lot{}).Where("location_id = ?", id).Update("location_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach lots from location: %w", err)
+		}
+
+		if err := tx.Delete(&location).Error; err != nil {
+			return fmt.Errorf("failed to delete warehouse location: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *WarehouseLocationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.WarehouseLocation, error) {
+	var location models.WarehouseLocation
+	if err := r.db.WithContext(ctx).First(&location, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("warehouse location not found: %w", err)
+	}
+
+	return mapToDomainWarehouseLocation(location), nil
+}
+
+func (r *WarehouseLocationRepo) ListByWarehouse(ctx context.Context, warehouseID uuid.UUID) ([]domain.WarehouseLocation, error) {
+	var dbModels []models.WarehouseLocation
+	if err := r.db.WithContext(ctx).
+		Where("warehouse_id = ?", warehouseID).
+		Order("path ASC").
+		Find(&dbModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch warehouse locations: %w", err)
+	}
+
+	locations := make([]domain.WarehouseLocation, 0, len(dbModels))
+	for _, m := range dbModels {
+		locations = append(locations, *mapToDomainWarehouseLocation(m))
+	}
+
+	return locations, nil
+}
+
+func (r *WarehouseLocationRepo) MoveLot(ctx context.Context, lotID uuid.UUID, dto domain.MoveLotDTO, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lot models.Lot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, "id = ?", lotID).Error; err != nil {
+			return fmt.Errorf("lot not found: %w", err)
+		}
+
+		if sameLocation(lot.LocationID, dto.LocationID) {
+			return fmt.Errorf("lot is already in this location")
+		}
+
+		move := models.LotLocationMove{
+			LotID:          lot.ID,
+			FromLocationID: lot.LocationID,
+			ToLocationID:   dto.LocationID,
+			MovedByID:      userID,
+			Comment:        dto.Comment,
+		}
+
+		if lot.LocationID != nil {
+			var from models.WarehouseLocation
+			if err := tx.Unscoped().First(&from, "id = ?", *lot.LocationID).Error; err == nil {
+				move.FromPath = from.Path
+			}
+		}
+
+		if dto.LocationID != nil {
+			var to models.WarehouseLocation
+			if err := tx.First(&to, "id = ?", *dto.LocationID).Error; err != nil {
+				return fmt.Errorf("location not found: %w", err)
+			}
+			if to.WarehouseID != lot.WarehouseID {
+				return fmt.Errorf("location belongs to another warehouse, use a transfer to move stock between warehouses")
+			}
+			move.ToPath = to.Path
+		}
+
+		if err := tx.Model(&lot).Update("location_id", dto.LocationID).Error; err != nil {
+			return fmt.Errorf("failed to update lot location: %w", err)
+		}
+
+		if err := tx.Create(&move).Error; err != nil {
+			return fmt.Errorf("failed to record lot move: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *WarehouseLocationRepo) ListLotMoves(ctx context.Context, lotID uuid.UUID) ([]domain.LotLocationMoveResponse, error) {
+	var moves []models.LotLocationMove
+	if err := r.db.WithContext(ctx).
+		Where("lot_id = ?", lotID).
+		Order("created_at DESC").
+		Find(&moves).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lot moves: %w", err)
+	}
+
+	responses := make([]domain.LotLocationMoveResponse, 0, len(moves))
+	for _, m := range moves {
+		responses = append(responses, domain.LotLocationMoveResponse{
+			ID:           m.ID,
+			LotID:        m.LotID,
+			FromLocation: optionalString(m.FromPath),
+			ToLocation:   optionalString(m.ToPath),
+			FromID:       m.FromLocationID,
+			ToID:         m.ToLocationID,
+			MovedBy:      m.MovedByID,
+			Comment:      m.Comment,
+			CreatedAt:    m.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return responses, nil
+}
+
+func ensureLocationCodeIsFree(tx *gorm.DB, warehouseID uuid.UUID, code string, exceptID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.WarehouseLocation{}).
+		Where("warehouse_id = ? AND code = ? AND id <> ?", warehouseID, code, exceptID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check location code: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("location code %s already exists in this warehouse", code)
+	}
+	return nil
+}
+
+func sameLocation(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func mapToDomainWarehouseLocation(m models.WarehouseLocation) *domain.WarehouseLocation {
+	return &domain.WarehouseLocation{
+		ID:          m.ID,
+		WarehouseID: m.WarehouseID,
+		ParentID:    m.ParentID,
+		Type:        domain.WarehouseLocationType(m.Type),
+		Code:        m.Code,
+		Name:        m.Name,
+		Path:        m.Path,
+	}
+}
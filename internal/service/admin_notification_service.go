@@ -7,6 +7,7 @@ import (
 	"html"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
@@ -109,6 +110,106 @@ func (s *adminNotificationService) NotifyCustomerMessage(ctx context.Context, or
 	}, buildCustomerMessageTelegramBody(order, title, messageText))
 }
 
+func (s *adminNotificationService) NotifySLABreach(ctx context.Context, order *domain.OrderResponse, kind domain.OrderSLAKind, due time.Time, assignee *domain.User) error {
+	if order == nil {
+		return nil
+	}
+
+	title := fmt.Sprintf("Прострочено %s по замовленню #%s", slaKindTitle(kind), shortOrderID(order.ID))
+	assigneeLine := "Не призначено"
+	if assignee != nil {
+		assigneeLine = buildUserLine(assignee)
+	}
+	body := fmt.Sprintf(
+		"%s\nКлієнт: %s\nСтатус: %s\nВідповідальний: %s\nТермін: %s",
+		title,
+		buildCustomerLine(order),
+		order.Status,
+		assigneeLine,
+		due.Format("2006-01-02 15:04"),
+	)
+
+	payload, _ := json.Marshal(map[string]any{
+		"event":       "order_sla_breached",
+		"order_id":    order.ID,
+		"status":      order.Status,
+		"sla":         kind,
+		"due":         due,
+		"assignee_id": order.AssigneeID,
+	})
+
+	telegramBody := fmt.Sprintf(
+		"<b>%s</b>\nКлієнт: %s\nСтатус: %s\nВідповідальний: %s\nТермін: %s",
+		html.EscapeString(title),
+		buildCustomerTelegramLink(order),
+		html.EscapeString(order.Status),
+		html.EscapeString(assigneeLine),
+		due.Format("2006-01-02 15:04"),
+	)
+
+	return s.createAndDispatch(ctx, domain.CreateAdminNotificationDTO{
+		Type:               domain.AdminNotificationTypeSLABreached,
+		Title:              title,
+		Body:               body,
+		OrderID:            pointerToUUID(order.ID),
+		CustomerName:       order.CustomerName,
+		CustomerPhone:      order.CustomerPhone,
+		CustomerUsername:   order.CustomerUsername,
+		CustomerTelegramID: order.CustomerTelegramID,
+		Payload:            payload,
+	}, telegramBody)
+}
+
+func (s *adminNotificationService) NotifyBuyerCancellation(ctx context.Context, order *domain.OrderResponse, reason string) error {
+	if order == nil {
+		return nil
+	}
+
+	title := fmt.Sprintf("Клієнт скасував замовлення #%s", shortOrderID(order.ID))
+	reason = fallbackString(strings.TrimSpace(reason), "Не вказано")
+	body := fmt.Sprintf(
+		"%s\nКлієнт: %s\nТелефон: %s\nТовари: %s\nСума: %.2f грн\nПричина: %s",
+		title,
+		buildCustomerLine(order),
+		fallbackString(order.CustomerPhone, "Не вказано"),
+		buildItemsSummary(order.Items),
+		order.TotalAmount,
+		reason,
+	)
+
+	payload, _ := json.Marshal(map[string]any{
+		"event":          "order_cancelled_by_buyer",
+		"order_id":       order.ID,
+		"items":          order.Items,
+		"total_amount":   order.TotalAmount,
+		"reason":         reason,
+		"customer_name":  order.CustomerName,
+		"customer_phone": order.CustomerPhone,
+	})
+
+	telegramBody := fmt.Sprintf(
+		"<b>%s</b>\nКлієнт: %s\nТелефон: %s\nТовари: %s\nСума: %.2f грн\nПричина: %s",
+		html.EscapeString(title),
+		buildCustomerTelegramLink(order),
+		html.EscapeString(fallbackString(order.CustomerPhone, "Не вказано")),
+		html.EscapeString(buildItemsSummary(order.Items)),
+		order.TotalAmount,
+		html.EscapeString(reason),
+	)
+
+	return s.createAndDispatch(ctx, domain.CreateAdminNotificationDTO{
+		Type:               domain.AdminNotificationTypeBuyerCancelled,
+		Title:              title,
+		Body:               body,
+		OrderID:            pointerToUUID(order.ID),
+		CustomerName:       order.CustomerName,
+		CustomerPhone:      order.CustomerPhone,
+		CustomerUsername:   order.CustomerUsername,
+		CustomerTelegramID: order.CustomerTelegramID,
+		Payload:            payload,
+	}, telegramBody)
+}
+
 func (s *adminNotificationService) List(ctx context.Context, filter domain.AdminNotificationFilter) ([]domain.AdminNotification, int64, error) {
 	if filter.Page <= 0 {
 		filter.Page = 1
@@ -175,6 +276,27 @@ func buildCustomerLine(order *domain.OrderResponse) string {
 	return strings.Join(parts, " • ")
 }
 
+func slaKindTitle(kind domain.OrderSLAKind) string {
+	if kind == domain.OrderSLAFirstResponse {
+		return "першу відповідь"
+	}
+	return "обробку"
+}
+
+func buildUserLine(user *domain.User) string {
+	parts := []string{}
+	if strings.TrimSpace(user.FirstName) != "" {
+		parts = append(parts, user.FirstName)
+	}
+	if strings.TrimSpace(user.Username) != "" {
+		parts = append(parts, "@"+strings.TrimPrefix(user.Username, "@"))
+	}
+	if len(parts) == 0 {
+		return shortOrderID(user.ID)
+	}
+	return strings.Join(parts, " • ")
+}
+
 func buildOrderCreatedTelegramBody(order *domain.OrderResponse, title string) string {
 	return fmt.Sprintf(
 		"<b>%s</b>\nКлієнт: %s\nТелефон: %s\nТовари: %s\nСума: %.2f грн",
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+// BuyerNotificationOptions configures the status messages sent to buyers.
+type BuyerNotificationOptions struct {
+	Disabled      []domain.OrderStatus // Statuses the buyer is not told about
+	PickupAddress string               // Shown when the order is ready for pickup
+	ContactPhone  string               // Shown on confirmations and cancellations
+}
+
+type buyerNotificationService struct {
+	repo     domain.OrderRepository
+	sender   telegram.Sender
+	logger   *slog.Logger
+	disabled map[domain.OrderStatus]bool
+	opts     BuyerNotificationOptions
+}
+
+// NewBuyerNotificationService creates the notifier that messages buyers through the client bot.
+func NewBuyerNotificationService(repo domain.OrderRepository, sender telegram.Sender, logger *slog.Logger, opts BuyerNotificationOptions) domain.OrderStatusNotifier {
+	disabled := make(map[domain.OrderStatus]bool, len(opts.Disabled))
+	for _, status := range opts.Disabled {
+		disabled[status] = true
+	}
+	return &buyerNotificationService{
+		repo:     repo,
+		sender:   sender,
+		logger:   logger,
+		disabled: disabled,
+		opts:     opts,
+	}
+}
+
+// buyerStatusMessage is the data the status templates are rendered with.
+type buyerStatusMessage struct {
+	OrderNumber    string
+	Total          string
+	Balance        string
+	HasBalance     bool // Something is still owed
+	TrackingNumber string
+	PickupAddress  string
+	ContactPhone   string
+	Reason         string
+}
+
+var buyerStatusTemplates = map[domain.OrderStatus]*template.Template{
+	domain.OrderStatusConfirmed: template.Must(template.New("confirmed").Parse(
+		"✅ Ваше замовлення #{{.OrderNumber}} підтверджено.\nСума: {{.Total}} грн" +
+			"{{if .ContactPhone}}\nЯкщо маєте питання, телефонуйте: {{.ContactPhone}}{{end}}")),
+	domain.OrderStatusAwaitingPayment: template.Must(template.New("awaiting_payment").Parse(
+		"💳 Замовлення #{{.OrderNumber}} очікує на оплату.\nДо сплати: {{.Balance}} грн. Після оплати ми одразу візьмемо його в роботу.")),
+	domain.OrderStatusPrepayment: template.Must(template.New("prepayment").Parse(
+		"💳 Передоплату за замовлення #{{.OrderNumber}} отримано." +
+			"{{if .HasBalance}}\nЗалишок до сплати: {{.Balance}} грн{{end}}")),
+	domain.OrderStatusPaid: template.Must(template.New("paid").Parse(
+		"💳 Оплату за замовлення #{{.OrderNumber}} отримано. Дякуємо!")),
+	domain.OrderStatusShipped: template.Must(template.New("shipped").Parse(
+		"🚚 Замовлення #{{.OrderNumber}} відправлено." +
+			"{{if .TrackingNumber}}\nНомер ТТН: {{.TrackingNumber}}{{end}}" +
+			"{{if .HasBalance}}\nДо сплати при отриманні: {{.Balance}} грн{{end}}")),
+	domain.OrderStatusReadyForPickup: template.Must(template.New("ready_for_pickup").Parse(
+		"📍 Замовлення #{{.OrderNumber}} готове до видачі." +
+			"{{if .PickupAddress}}\nАдреса: {{.PickupAddress}}{{end}}" +
+			"{{if .HasBalance}}\nДо сплати при отриманні: {{.Balance}} грн{{end}}")),
+	domain.OrderStatusDone: template.Must(template.New("done").Parse(
+		"🎉 Замовлення #{{.OrderNumber}} виконано. Дякуємо за покупку!")),
+	domain.OrderStatusCancelled: template.Must(template.New("cancelled").Parse(
+		"❌ Замовлення #{{.OrderNumber}} скасовано." +
+			"{{if .Reason}}\nПричина: {{.Reason}}{{end}}" +
+			"{{if .ContactPhone}}\nЯкщо це помилка, зателефонуйте нам: {{.ContactPhone}}{{end}}")),
+	domain.OrderStatusReturned: template.Must(template.New("returned").Parse(
+		"↩️ Повернення за замовленням #{{.OrderNumber}} оформлено.")),
+}
+
+// NotifyStatus renders the template of the status, sends it through the client bot and keeps it in the
+// order thread. Orders without a Telegram buyer and disabled statuses are skipped.
+func (s *buyerNotificationService) NotifyStatus(ctx context.Context, order *domain.OrderResponse, status domain.OrderStatus, comment string) error {
+	if order.CustomerTelegramID == nil || *order.CustomerTelegramID == 0 || s.disabled[status] {
+		return nil
+	}
+	tmpl, ok := buyerStatusTemplates[status]
+	if !ok {
+		return nil
+	}
+
+	data := buyerStatusMessage{
+		OrderNumber:    strings.ToUpper(shortOrderID(order.ID)),
+		Total:          fmt.Sprintf("%.2f", order.TotalAmount),
+		Balance:        fmt.Sprintf("%.2f", order.Balance),
+		HasBalance:     order.Balance > 0,
+		TrackingNumber: order.TrackingNumber,
+		PickupAddress:  s.opts.PickupAddress,
+		ContactPhone:   s.opts.ContactPhone,
+	}
+	if status == domain.OrderStatusCancelled {
+		data.Reason = strings.TrimSpace(comment)
+	}
+
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, data); err != nil {
+		return fmt.Errorf("failed to render buyer status message: %w", err)
+	}
+
+	telegramMessageID, err := s.sender.SendMessage(*order.CustomerTelegramID, message.String())
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.CreateMessage(ctx, domain.CreateOrderMessageDTO{
+		OrderID:            order.ID,
+		CustomerTelegramID: *order.CustomerTelegramID,
+		Direction:          domain.OrderMessageDirectionOutbound,
+		MessageText:        message.String(),
+		TelegramMessageID:  telegramMessageID,
+	})
+	return err
+}
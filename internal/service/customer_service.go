@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type customerService struct {
+	repo   domain.CustomerRepository
+	orders domain.OrderRepository
+	logger *slog.Logger
+}
+
+func NewCustomerService(repo domain.CustomerRepository, orders domain.OrderRepository, logger *slog.Logger) domain.CustomerService {
+	return &customerService{
+		repo:   repo,
+		orders: orders,
+		logger: logger,
+	}
+}
+
+func (s *customerService) LinkOrder(ctx context.Context, orderID uuid.UUID) error {
+	_, err := s.repo.LinkOrder(ctx, orderID)
+	return err
+}
+
+func (s *customerService) ListCustomers(ctx context.Context, filter domain.CustomerFilter) ([]domain.CustomerResponse, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	return s.repo.List(ctx, filter)
+}
+
+func (s *customerService) GetCustomer(ctx context.Context, id uuid.UUID) (*domain.CustomerResponse, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *customerService) UpdateCustomer(ctx context.Context, id uuid.UUID, dto domain.UpdateCustomerDTO) (*domain.CustomerResponse, error) {
+	if err := s.repo.Update(ctx, id, dto); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *customerService) ListCustomerOrders(ctx context.Context, id uuid.UUID, filter domain.OrderFilter) ([]domain.OrderResponse, int64, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	filter.CustomerID = &id
+
+	return s.orders.List(ctx, filter)
+}
+
+func (s *customerService) MergeCustomers(ctx context.Context, targetID uuid.UUID, dto domain.MergeCustomersDTO, userID uuid.UUID) (*domain.CustomerResponse, error) {
+	if err := s.repo.Merge(ctx, targetID, dto.SourceID, userID, dto.Comment); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("customers merged", slog.String("target_id", targetID.String()), slog.String("source_id", dto.SourceID.String()))
+
+	return s.repo.GetByID(ctx, targetID)
+}
+
+// Start links the orders placed before customer records existed. It runs once, in batches.
+func (s *customerService) Start(ctx context.Context) {
+	s.logger.Info("starting customer linking worker")
+
+	go func() {
+		var cursor *uuid.UUID
+		linked := 0
+		for {
+			ids, err := s.repo.ListUnlinkedOrderIDs(ctx, cursor, 200)
+			if err != nil {
+				s.logger.Error("failed to list unlinked orders", slog.String("error", err.Error()))
+				return
+			}
+			if len(ids) == 0 {
+				break
+			}
+
+			for _, id := range ids {
+				if ctx.Err() != nil {
+					return
+				}
+				customerID, err := s.repo.LinkOrder(ctx, id)
+				if err != nil {
+					s.logger.Warn("failed to link order to customer", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+					continue
+				}
+				if customerID != nil {
+					linked++
+				}
+			}
+			cursor = &ids[len(ids)-1]
+		}
+
+		if linked > 0 {
+			s.logger.Info("linked existing orders to customers", slog.Int("orders", linked))
+		}
+	}()
+}
@@ -2,23 +2,31 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/horoshi10v/tires-shop/internal/domain"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/googlesheets"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/spreadsheet"
 )
 
+// orderExportPageSize is how many orders are fetched per query while exporting.
+const orderExportPageSize = 500
+
 type exportService struct {
 	lotRepo    domain.LotRepository
 	reportRepo domain.ReportRepository
+	orderRepo  domain.OrderRepository
 	exporter   googlesheets.Exporter
 	logger     *slog.Logger
 }
 
-func NewExportService(lotRepo domain.LotRepository, reportRepo domain.ReportRepository, exporter googlesheets.Exporter, logger *slog.Logger) domain.ExportService {
+func NewExportService(lotRepo domain.LotRepository, reportRepo domain.ReportRepository, orderRepo domain.OrderRepository, exporter googlesheets.Exporter, logger *slog.Logger) domain.ExportService {
 	return &exportService{
 		lotRepo:    lotRepo,
 		reportRepo: reportRepo,
+		orderRepo:  orderRepo,
 		exporter:   exporter,
 		logger:     logger,
 	}
@@ -67,3 +75,69 @@ func (s *exportService) ExportPnL(ctx context.Context, filter domain.ReportFilte
 
 	return s.exporter.GeneratePnLReport(ctx, pnl)
 }
+
+func (s *exportService) ExportOrders(ctx context.Context, filter domain.OrderFilter, format domain.ExportFormat) (*domain.ExportFile, error) {
+	if format != domain.ExportFormatCSV && format != domain.ExportFormatXLSX {
+		return nil, domain.ErrUnsupportedExportFormat
+	}
+
+	s.logger.Info("generating orders export", slog.String("format", string(format)))
+
+	table := spreadsheet.Table{
+		Name: "Orders",
+		Header: []string{
+			"Order ID", "Created At", "Status", "Channel", "Customer", "Phone", "Promo Code",
+			"Order Total", "Order Discount", "Paid", "Balance", "Payment State", "Tracking Number", "Fiscal Code",
+			"Item ID", "Lot ID", "Brand", "Model", "Quantity", "Price", "Item Discount", "Item Total",
+		},
+	}
+
+	filter.PageSize = orderExportPageSize
+	for filter.Page = 1; ; filter.Page++ {
+		orders, total, err := s.orderRepo.List(ctx, filter)
+		if err != nil {
+			s.logger.Error("failed to fetch orders for export", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		for _, order := range orders {
+			orderCells := []any{
+				order.ID.String(), order.CreatedAt, order.Status, string(order.Channel), order.CustomerName, order.CustomerPhone, order.PromoCode,
+				order.TotalAmount, order.DiscountAmount, order.PaidAmount, order.Balance, string(order.PaymentState), order.TrackingNumber, order.FiscalCode,
+			}
+			if len(order.Items) == 0 {
+				table.Rows = append(table.Rows, orderCells)
+				continue
+			}
+			for _, item := range order.Items {
+				row := append(append([]any{}, orderCells...),
+					item.ID.String(), item.LotID.String(), item.Brand, item.Model, item.Quantity, item.Price, item.Discount, item.Total,
+				)
+				table.Rows = append(table.Rows, row)
+			}
+		}
+
+		if len(orders) == 0 || int64(filter.Page*filter.PageSize) >= total {
+			break
+		}
+	}
+
+	var content []byte
+	var err error
+	contentType := "text/csv; charset=utf-8"
+	if format == domain.ExportFormatXLSX {
+		content, err = spreadsheet.WriteXLSX(table)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		content, err = spreadsheet.WriteCSV(table)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ExportFile{
+		FileName:    fmt.Sprintf("orders-%s.%s", time.Now().Format("20060102-150405"), format),
+		ContentType: contentType,
+		Content:     content,
+	}, nil
+}
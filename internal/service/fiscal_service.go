@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+// fiscalBatchLimit caps how many receipts one queue pass sends.
+const fiscalBatchLimit = 50
+
+// fiscalMaxBackoff caps the delay between attempts of one receipt.
+const fiscalMaxBackoff = 6 * time.Hour
+
+// FiscalOptions configures the fiscalization queue.
+type FiscalOptions struct {
+	Enabled       bool          // Offline sales are not fiscalized without provider credentials
+	RetryInterval time.Duration // Queue polling period and the first retry delay, doubled on every failure
+	MaxAttempts   int           // After that many failures the receipt waits for a manual retry
+	AutoOpenShift bool          // Open the cashier shift when the queue has receipts and no shift is open
+}
+
+type fiscalService struct {
+	provider domain.FiscalProvider
+	receipts domain.FiscalReceiptRepository
+	ledger   domain.OrderLedgerRepository
+	notifier telegram.Notifier
+	logger   *slog.Logger
+	opts     FiscalOptions
+	wake     chan struct{}
+}
+
+func NewFiscalService(
+	provider domain.FiscalProvider,
+	receipts domain.FiscalReceiptRepository,
+	ledger domain.OrderLedgerRepository,
+	notifier telegram.Notifier,
+	logger *slog.Logger,
+	opts FiscalOptions,
+) domain.FiscalService {
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	return &fiscalService{
+		provider: provider,
+		receipts: receipts,
+		ledger:   ledger,
+		notifier: notifier,
+		logger:   logger,
+		opts:     opts,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// FiscalizeSale queues the sale receipt of an offline order. Money booked as cash in the ledger is printed
+// as cash and everything else as cashless; an unpaid remainder of an overridden order counts as cash.
+func (s *fiscalService) FiscalizeSale(ctx context.Context, order *domain.OrderResponse) error {
+	if !s.opts.Enabled || order.Channel != domain.OrderChannelOffline || order.TotalAmount <= 0 {
+		return nil
+	}
+
+	entries, err := s.ledger.ListEntries(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	var cashless float64
+	for _, entry := range entries {
+		if entry.Method == domain.PaymentMethodCash {
+			continue
+		}
+		if entry.Type == domain.LedgerEntryRefund {
+			cashless -= entry.Amount
+		} else {
+			cashless += entry.Amount
+		}
+	}
+	cashless = min(max(cashless, 0), order.TotalAmount)
+
+	var payments []domain.FiscalPayment
+	if cash := order.TotalAmount - cashless; cash > 0.005 {
+		payments = append(payments, domain.FiscalPayment{Type: domain.FiscalPaymentCash, Amount: cash})
+	}
+	if cashless > 0.005 {
+		payments = append(payments, domain.FiscalPayment{Type: domain.FiscalPaymentCashless, Amount: cashless})
+	}
+
+	goods := make([]domain.FiscalGood, 0, len(order.Items))
+	for _, item := range order.Items {
+		goods = append(goods, domain.FiscalGood{
+			Code:     item.LotID.String(),
+			Name:     fiscalGoodName(item.Brand, item.Model),
+			Price:    item.Price,
+			Quantity: item.Quantity,
+			Discount: item.Discount,
+		})
+	}
+
+	return s.enqueue(ctx, domain.CreateFiscalReceiptRecord{
+		OrderID:  order.ID,
+		Provider: s.provider.Name(),
+		Request: domain.FiscalReceiptRequest{
+			Type:     domain.FiscalReceiptSale,
+			Goods:    goods,
+			Payments: payments,
+		},
+	})
+}
+
+// FiscalizeReturn queues the return receipt for the refunded lines. Lines are printed at the sale price,
+// and whatever was not refunded is shown as a discount. Orders sold before fiscalization was enabled
+// have no sale receipt and are skipped.
+func (s *fiscalService) FiscalizeReturn(ctx context.Context, order *domain.OrderResponse, orderReturn *domain.OrderReturnResponse, refundMethod domain.PaymentMethod) error {
+	if !s.opts.Enabled || order.Channel != domain.OrderChannelOffline || orderReturn.RefundAmount <= 0 {
+		return nil
+	}
+
+	sale, err := s.receipts.GetSale(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if sale == nil {
+		s.logger.Warn("order has no sale receipt, return is not fiscalized", slog.String("order_id", order.ID.String()), slog.String("return_id", orderReturn.ID.String()))
+		return nil
+	}
+
+	prices := make(map[uuid.UUID]float64, len(order.Items))
+	for _, item := range order.Items {
+		prices[item.ID] = item.Price
+	}
+
+	goods := make([]domain.FiscalGood, 0, len(orderReturn.Items))
+	for _, item := range orderReturn.Items {
+		if item.RefundAmount <= 0 {
+			continue
+		}
+		price := prices[item.OrderItemID]
+		if gross := price * float64(item.Quantity); gross < item.RefundAmount {
+			price = item.RefundAmount / float64(item.Quantity)
+		}
+		goods = append(goods, domain.FiscalGood{
+			Code:     item.LotID.String(),
+			Name:     fiscalGoodName(item.Brand, item.Model),
+			Price:    price,
+			Quantity: item.Quantity,
+			Discount: max(price*float64(item.Quantity)-item.RefundAmount, 0),
+		})
+	}
+
+	paymentType := domain.FiscalPaymentTypeFor(refundMethod)
+	if refundMethod == "" && len(sale.Request.Payments) > 0 {
+		// Not paid back yet, refund the way the buyer mostly paid.
+		largest := sale.Request.Payments[0]
+		for _, payment := range sale.Request.Payments[1:] {
+			if payment.Amount > largest.Amount {
+				largest = payment
+			}
+		}
+		paymentType = largest.Type
+	}
+
+	returnID := orderReturn.ID
+	return s.enqueue(ctx, domain.CreateFiscalReceiptRecord{
+		OrderID:       order.ID,
+		OrderReturnID: &returnID,
+		Provider:      s.provider.Name(),
+		Request: domain.FiscalReceiptRequest{
+			Type:     domain.FiscalReceiptReturn,
+			Goods:    goods,
+			Payments: []domain.FiscalPayment{{Type: paymentType, Amount: orderReturn.RefundAmount}},
+		},
+	})
+}
+
+func (s *fiscalService) enqueue(ctx context.Context, record domain.CreateFiscalReceiptRecord) error {
+	receipt, created, err := s.receipts.Enqueue(ctx, record)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	s.logger.Info("fiscal receipt queued",
+		slog.String("receipt_id", receipt.ID.String()),
+		slog.String("order_id", receipt.OrderID.String()),
+		slog.String("type", string(receipt.Type)),
+		slog.Float64("amount", receipt.Amount),
+	)
+	s.kick()
+	return nil
+}
+
+func (s *fiscalService) GetShift(ctx context.Context) (*domain.FiscalShift, error) {
+	if !s.opts.Enabled {
+		return nil, domain.ErrFiscalNotConfigured
+	}
+	shift, err := s.provider.CurrentShift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return &domain.FiscalShift{Status: "CLOSED"}, nil
+	}
+	return shift, nil
+}
+
+// OpenShift returns the current shift when it is already open.
+func (s *fiscalService) OpenShift(ctx context.Context) (*domain.FiscalShift, error) {
+	if !s.opts.Enabled {
+		return nil, domain.ErrFiscalNotConfigured
+	}
+	shift, err := s.provider.CurrentShift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if shift != nil {
+		return shift, nil
+	}
+
+	shift, err = s.provider.OpenShift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.kick()
+	return shift, nil
+}
+
+func (s *fiscalService) CloseShift(ctx context.Context) (*domain.FiscalShift, error) {
+	if !s.opts.Enabled {
+		return nil, domain.ErrFiscalNotConfigured
+	}
+	shift, err := s.provider.CloseShift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.notifier.SendAlert("🧾 Касову зміну закрито, Z-звіт сформовано.")
+	return shift, nil
+}
+
+func (s *fiscalService) ListReceipts(ctx context.Context, filter domain.FiscalReceiptFilter) ([]domain.FiscalReceiptResponse, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	return s.receipts.List(ctx, filter)
+}
+
+func (s *fiscalService) RetryReceipt(ctx context.Context, id uuid.UUID) (*domain.FiscalReceiptResponse, error) {
+	if !s.opts.Enabled {
+		return nil, domain.ErrFiscalNotConfigured
+	}
+	receipt, err := s.receipts.Requeue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.kick()
+	return receipt, nil
+}
+
+// Start sends queued receipts in the background, right away when a receipt is queued and every
+// RetryInterval otherwise.
+func (s *fiscalService) Start(ctx context.Context) {
+	if !s.opts.Enabled {
+		s.logger.Info("fiscalization is disabled")
+		return
+	}
+
+	s.logger.Info("starting fiscalization worker", slog.String("provider", s.provider.Name()), slog.Duration("period", s.opts.RetryInterval))
+
+	go func() {
+		ticker := time.NewTicker(s.opts.RetryInterval)
+		defer ticker.Stop()
+
+		for {
+			s.processQueue(ctx)
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("stopping fiscalization worker")
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+func (s *fiscalService) kick() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *fiscalService) processQueue(ctx context.Context) {
+	receipts, err := s.receipts.ListDue(ctx, time.Now(), fiscalBatchLimit)
+	if err != nil {
+		s.logger.Error("failed to list due fiscal receipts", slog.String("error", err.Error()))
+		return
+	}
+	if len(receipts) == 0 {
+		return
+	}
+
+	if s.opts.AutoOpenShift {
+		// Without an open shift every attempt would fail, so the queue waits without spending attempts.
+		if _, err := s.OpenShift(ctx); err != nil {
+			s.logger.Error("failed to open cashier shift", slog.String("error", err.Error()))
+			return
+		}
+	}
+
+	for _, receipt := range receipts {
+		s.processReceipt(ctx, receipt)
+	}
+}
+
+// processReceipt asks the provider first when an earlier attempt may have reached it, so a receipt is
+// never issued twice.
+func (s *fiscalService) processReceipt(ctx context.Context, receipt domain.FiscalReceiptResponse) {
+	if receipt.Type == domain.FiscalReceiptReturn {
+		sale, err := s.receipts.GetSale(ctx, receipt.OrderID)
+		if err != nil {
+			s.logger.Error("failed to fetch sale receipt", slog.String("order_id", receipt.OrderID.String()), slog.String("error", err.Error()))
+			return
+		}
+		if sale == nil || sale.Status != domain.FiscalReceiptDone {
+			if err := s.receipts.Postpone(ctx, receipt.ID, time.Now().Add(s.opts.RetryInterval)); err != nil {
+				s.logger.Error("failed to postpone fiscal receipt", slog.String("receipt_id", receipt.ID.String()), slog.String("error", err.Error()))
+			}
+			return
+		}
+	}
+
+	var result *domain.FiscalReceiptResult
+	var err error
+	if receipt.Attempts > 0 || receipt.Status == domain.FiscalReceiptProcessing {
+		result, err = s.provider.GetReceipt(ctx, receipt.ID)
+	}
+	if err == nil && result == nil {
+		result, err = s.provider.IssueReceipt(ctx, receipt.Request)
+	}
+	if err != nil {
+		s.saveFailure(ctx, receipt, err.Error())
+		return
+	}
+
+	switch result.Status {
+	case domain.FiscalReceiptDone:
+		if err := s.receipts.SaveAttempt(ctx, receipt.ID, domain.FiscalAttempt{
+			Status:      domain.FiscalReceiptDone,
+			ProviderRef: result.ProviderRef,
+			FiscalCode:  result.FiscalCode,
+		}); err != nil {
+			s.logger.Error("fiscal receipt issued but not stored", slog.String("receipt_id", receipt.ID.String()), slog.String("fiscal_code", result.FiscalCode), slog.String("error", err.Error()))
+			return
+		}
+		s.logger.Info("fiscal receipt issued", slog.String("receipt_id", receipt.ID.String()), slog.String("fiscal_code", result.FiscalCode))
+	case domain.FiscalReceiptFailed:
+		s.saveFailure(ctx, receipt, result.Error)
+	default:
+		receipt.Status = domain.FiscalReceiptProcessing
+		s.saveFailure(ctx, receipt, "")
+	}
+}
+
+// saveFailure schedules the next attempt with a doubling delay, or gives up and alerts staff.
+// An empty message means the provider is still processing the receipt.
+func (s *fiscalService) saveFailure(ctx context.Context, receipt domain.FiscalReceiptResponse, message string) {
+	attempts := receipt.Attempts + 1
+	attempt := domain.FiscalAttempt{Status: receipt.Status, Error: message}
+	if attempt.Status != domain.FiscalReceiptProcessing {
+		attempt.Status = domain.FiscalReceiptPending
+	}
+
+	if attempts >= s.opts.MaxAttempts {
+		attempt.Status = domain.FiscalReceiptFailed
+		if attempt.Error == "" {
+			attempt.Error = "fiscal number was not issued in time"
+		}
+	} else {
+		backoff := s.opts.RetryInterval << (attempts - 1)
+		if backoff <= 0 || backoff > fiscalMaxBackoff {
+			backoff = fiscalMaxBackoff
+		}
+		attempt.NextAttemptAt = time.Now().Add(backoff)
+	}
+
+	if err := s.receipts.SaveAttempt(ctx, receipt.ID, attempt); err != nil {
+		s.logger.Error("failed to store fiscal attempt", slog.String("receipt_id", receipt.ID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	if message != "" {
+		s.logger.Warn("fiscal attempt failed", slog.String("receipt_id", receipt.ID.String()), slog.Int("attempt", attempts), slog.String("error", message))
+	}
+	if attempt.Status == domain.FiscalReceiptFailed {
+		s.notifier.SendAlert(fmt.Sprintf("❗️ Не вдалося фіскалізувати чек %s (%s) по замовленню %s: %s",
+			receipt.ID.String(), strings.ToLower(string(receipt.Type)), receipt.OrderID.String(), attempt.Error))
+	}
+}
+
+func fiscalGoodName(brand, model string) string {
+	name := strings.TrimSpace(brand + " " + model)
+	if name == "" {
+		return "Товар"
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/pdf"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
+)
+
+// maxLabelsPerSheet protects the API from rendering the whole inventory by accident.
+const maxLabelsPerSheet = 500
+
+var labelSeasons = map[string]string{
+	"SUMMER":     "Літо",
+	"WINTER":     "Зима",
+	"ALL_SEASON": "Всесезон",
+}
+
+var labelConditions = map[string]string{
+	"NEW":  "Нові",
+	"USED": "Б/В",
+}
+
+type labelService struct {
+	lotRepo  domain.LotRepository
+	qrGen    qrcode.Generator
+	renderer pdf.LabelRenderer
+	logger   *slog.Logger
+}
+
+func NewLabelService(lotRepo domain.LotRepository, qrGen qrcode.Generator, renderer pdf.LabelRenderer, logger *slog.Logger) domain.LabelService {
+	return &labelService{
+		lotRepo:  lotRepo,
+		qrGen:    qrGen,
+		renderer: renderer,
+		logger:   logger,
+	}
+}
+
+func (s *labelService) RenderLotLabel(ctx context.Context, lotID uuid.UUID, template string) ([]byte, error) {
+	lot, err := s.lotRepo.GetByID(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := s.buildLabel(*lot)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderer.Render(template, []domain.LotLabel{label})
+}
+
+// RenderLotLabels prints the lots picked by ID, or by the filter when no IDs are given.
+func (s *labelService) RenderLotLabels(ctx context.Context, dto domain.PrintLabelsDTO, filter domain.LotFilter) ([]byte, error) {
+	filter.Page = 1
+	filter.PageSize = maxLabelsPerSheet
+	if len(dto.LotIDs) > 0 {
+		filter = domain.LotFilter{IDs: dto.LotIDs, Page: 1, PageSize: maxLabelsPerSheet}
+	}
+
+	lots, total, err := s.lotRepo.ListInternal(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to fetch lots for labels", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if total > maxLabelsPerSheet {
+		return nil, fmt.Errorf("too many lots selected: %d, maximum is %d", total, maxLabelsPerSheet)
+	}
+	if len(lots) == 0 {
+		return nil, fmt.Errorf("no lots match the selection")
+	}
+
+	copies := dto.Copies
+	if copies <= 0 {
+		copies = 1
+	}
+
+	labels := make([]domain.LotLabel, 0, len(lots)*copies)
+	for _, lot := range lots {
+		label, err := s.buildLabel(lot)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < copies; i++ {
+			labels = append(labels, label)
+		}
+	}
+
+	s.logger.Info("rendering lot labels", slog.Int("labels", len(labels)), slog.String("template", dto.Template))
+	return s.renderer.Render(dto.Template, labels)
+}
+
+func (s *labelService) ListTemplates() []domain.LabelTemplateInfo {
+	return s.renderer.Templates()
+}
+
+func (s *labelService) buildLabel(lot domain.LotInternalResponse) (domain.LotLabel, error) {
+	// Same payload as GET /lots/:id/qr.
+	qrPNG, err := s.qrGen.GenerateLinkPNG(domain.ScanCode(domain.ScanEntityLot, lot.ID), 256)
+	if err != nil {
+		return domain.LotLabel{}, err
+	}
+
+	return domain.LotLabel{
+		LotID:     lot.ID,
+		Brand:     lot.Brand,
+		Model:     lot.Model,
+		Size:      lot.Params.SizeLabel(lot.Type),
+		Season:    labelSeasons[lot.Params.Season],
+		Condition: labelConditions[lot.Condition],
+		Price:     lot.SellPrice,
+		QRCode:    qrPNG,
+	}, nil
+}
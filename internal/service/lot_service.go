@@ -2,26 +2,32 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/barcode"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
 )
 
 // lotService implements domain.LotService.
 type lotService struct {
-	repo   domain.LotRepository
-	logger *slog.Logger
-	qrGen  qrcode.Generator
+	repo       domain.LotRepository
+	logger     *slog.Logger
+	qrGen      qrcode.Generator
+	barcodeGen barcode.Generator
+	photos     domain.PhotoService
 }
 
 // NewLotService initializes the business logic layer for lots.
-func NewLotService(repo domain.LotRepository, logger *slog.Logger, qrGen qrcode.Generator) domain.LotService {
+func NewLotService(repo domain.LotRepository, logger *slog.Logger, qrGen qrcode.Generator, barcodeGen barcode.Generator, photos domain.PhotoService) domain.LotService {
 	return &lotService{
-		repo:   repo,
-		logger: logger,
-		qrGen:  qrGen,
+		repo:       repo,
+		logger:     logger,
+		qrGen:      qrGen,
+		barcodeGen: barcodeGen,
+		photos:     photos,
 	}
 }
 
@@ -30,7 +36,11 @@ func (s *lotService) CreateLot(ctx context.Context, dto domain.CreateLotDTO) (uu
 	s.logger.Debug("attempting to create new lot", slog.String("brand", dto.Brand))
 
 	// Note: Basic validation (like price > 0) is handled by Gin binding tags in the DTO.
-	// Complex business validations would go here.
+	if dto.Barcode != "" {
+		if err := domain.ValidateEAN(dto.Barcode); err != nil {
+			return uuid.Nil, err
+		}
+	}
 
 	// Call the repository layer to save data
 	id, err := s.repo.Create(ctx, &dto)
@@ -39,19 +49,41 @@ func (s *lotService) CreateLot(ctx context.Context, dto domain.CreateLotDTO) (uu
 		return uuid.Nil, err
 	}
 
+	s.attachPhotos(ctx, id, dto.Photos)
+
 	s.logger.Info("lot created successfully", slog.String("lot_id", id.String()))
 	return id, nil
 }
 
 // UpdateLot handles updating an existing lot.
-func (s *lotService) UpdateLot(ctx context.Context, id uuid.UUID, dto domain.UpdateLotDTO) error {
+func (s *lotService) UpdateLot(ctx context.Context, id uuid.UUID, dto domain.UpdateLotDTO, userID uuid.UUID) error {
 	s.logger.Debug("attempting to update lot", slog.String("lot_id", id.String()))
 
-	if err := s.repo.Update(ctx, id, &dto); err != nil {
+	if dto.Barcode != nil && *dto.Barcode != "" {
+		if err := domain.ValidateEAN(*dto.Barcode); err != nil {
+			return err
+		}
+	}
+
+	var currentPhotos []string
+	if dto.Photos != nil {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		currentPhotos = current.Photos
+	}
+
+	if err := s.repo.Update(ctx, id, &dto, userID); err != nil {
 		s.logger.Error("failed to update lot", slog.String("lot_id", id.String()), slog.String("error", err.Error()))
 		return err
 	}
 
+	if dto.Photos != nil {
+		s.attachPhotos(ctx, id, dto.Photos)
+		s.photos.ReleasePhotos(ctx, subtractPhotos(currentPhotos, dto.Photos))
+	}
+
 	s.logger.Info("lot updated successfully", slog.String("lot_id", id.String()))
 	return nil
 }
@@ -60,15 +92,31 @@ func (s *lotService) UpdateLot(ctx context.Context, id uuid.UUID, dto domain.Upd
 func (s *lotService) DeleteLot(ctx context.Context, id uuid.UUID) error {
 	s.logger.Debug("attempting to delete lot", slog.String("lot_id", id.String()))
 
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		s.logger.Error("failed to delete lot", slog.String("lot_id", id.String()), slog.String("error", err.Error()))
 		return err
 	}
 
+	// Photos still shown in orders or copied to transferred lots are kept.
+	s.photos.ReleasePhotos(ctx, current.Photos)
+
 	s.logger.Info("lot deleted successfully", slog.String("lot_id", id.String()))
 	return nil
 }
 
+// attachPhotos records the lot in the upload registry. A failure only affects the orphan report,
+// since the sweeper checks actual references before deleting anything.
+func (s *lotService) attachPhotos(ctx context.Context, id uuid.UUID, photos []string) {
+	if err := s.photos.AttachToLot(ctx, id, photos); err != nil {
+		s.logger.Warn("failed to attach photos to lot", slog.String("lot_id", id.String()), slog.String("error", err.Error()))
+	}
+}
+
 func (s *lotService) ListPublicLots(ctx context.Context, filter domain.LotFilter) ([]domain.LotPublicResponse, int64, error) {
 	filter = sanitizePagination(filter)
 	s.logger.Debug("fetching public lots", slog.Int("page", filter.Page))
@@ -118,10 +166,8 @@ func (s *lotService) TrackInternalSuggestionSelection(ctx context.Context, sugge
 func (s *lotService) GenerateLotQR(ctx context.Context, id uuid.UUID) ([]byte, error) {
 	s.logger.Info("generating qr code for lot", slog.String("lot_id", id.String()))
 
-	dataToEncode := id.String()
-
-	// PNG 256x256
-	pngBytes, err := s.qrGen.GeneratePNG(dataToEncode, 256)
+	// PNG 256x256 with a deep link, so buyers land on the lot in the Mini App
+	pngBytes, err := s.qrGen.GenerateLinkPNG(domain.ScanCode(domain.ScanEntityLot, id), 256)
 	if err != nil {
 		s.logger.Error("failed to generate qr png", slog.String("error", err.Error()))
 		return nil, err
@@ -130,6 +176,64 @@ func (s *lotService) GenerateLotQR(ctx context.Context, id uuid.UUID) ([]byte, e
 	return pngBytes, nil
 }
 
+// GenerateLotBarcode renders the lot SKU as Code128 for handheld scanners that cannot read QR codes.
+func (s *lotService) GenerateLotBarcode(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	lot, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("generating barcode for lot", slog.String("lot_id", id.String()), slog.String("sku", lot.SKU))
+	return s.barcodeGen.GenerateCode128PNG(lot.SKU, 2, 80)
+}
+
+// FindByBarcode resolves a scanned manufacturer EAN or our own SKU to lots.
+func (s *lotService) FindByBarcode(ctx context.Context, code string) ([]domain.LotInternalResponse, error) {
+	if _, ok := domain.ParseLotSKU(code); ok {
+		lot, err := s.repo.GetBySKU(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.LotInternalResponse{*lot}, nil
+	}
+
+	if err := domain.ValidateEAN(code); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByBarcode(ctx, code)
+}
+
+// PrefillFromBarcode copies the catalog data of the latest lot with the EAN into a new lot draft.
+// Stock, warehouse and photos are left empty because they belong to the physical delivery.
+func (s *lotService) PrefillFromBarcode(ctx context.Context, code string) (*domain.CreateLotDTO, error) {
+	if err := domain.ValidateEAN(code); err != nil {
+		return nil, err
+	}
+
+	lots, err := s.repo.ListByBarcode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, fmt.Errorf("no lots with barcode %s", code)
+	}
+
+	// Lots come newest first, so prices follow the latest delivery.
+	latest := lots[0]
+	return &domain.CreateLotDTO{
+		Type:          latest.Type,
+		Condition:     latest.Condition,
+		Brand:         latest.Brand,
+		Model:         latest.Model,
+		Barcode:       code,
+		Params:        latest.Params,
+		Photos:        []string{},
+		PurchasePrice: latest.PurchasePrice,
+		SellPrice:     latest.SellPrice,
+	}, nil
+}
+
 // Helper function to ensure pagination is valid
 func sanitizePagination(filter domain.LotFilter) domain.LotFilter {
 	if filter.Page <= 0 {
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+// OrderSLAOptions configures order assignment and the SLA timers.
+type OrderSLAOptions struct {
+	SLA           domain.OrderSLA
+	AutoAssign    bool          // Assign online orders round-robin across staff
+	CheckInterval time.Duration // How often breaches are looked for
+}
+
+type orderAssignmentService struct {
+	repo               domain.OrderAssignmentRepository
+	orders             domain.OrderRepository
+	users              domain.UserRepository
+	adminNotifications domain.AdminNotificationService
+	staffSender        telegram.Sender
+	logger             *slog.Logger
+	opts               OrderSLAOptions
+}
+
+func NewOrderAssignmentService(
+	repo domain.OrderAssignmentRepository,
+	orders domain.OrderRepository,
+	users domain.UserRepository,
+	adminNotifications domain.AdminNotificationService,
+	staffSender telegram.Sender,
+	logger *slog.Logger,
+	opts OrderSLAOptions,
+) domain.OrderAssignmentService {
+	if opts.SLA.FirstResponse <= 0 {
+		opts.SLA.FirstResponse = 30 * time.Minute
+	}
+	if opts.SLA.Processing <= 0 {
+		opts.SLA.Processing = 24 * time.Hour
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 5 * time.Minute
+	}
+	return &orderAssignmentService{
+		repo:               repo,
+		orders:             orders,
+		users:              users,
+		adminNotifications: adminNotifications,
+		staffSender:        staffSender,
+		logger:             logger,
+		opts:               opts,
+	}
+}
+
+// StartTracking starts the timers of a new order. Offline orders are served at the counter, so they go to
+// the staff member who placed them and need no first response.
+func (s *orderAssignmentService) StartTracking(ctx context.Context, order *domain.OrderResponse, createdBy *uuid.UUID) error {
+	start := domain.StartOrderSLA{
+		SLA:        s.opts.SLA,
+		AutoAssign: s.opts.AutoAssign,
+	}
+	if order.Channel == domain.OrderChannelOffline && createdBy != nil {
+		start.AssigneeID = createdBy
+		start.Responded = true
+	}
+
+	assigneeID, err := s.repo.StartSLA(ctx, order.ID, start)
+	if err != nil {
+		return err
+	}
+	if assigneeID != nil && start.AssigneeID == nil {
+		s.notifyAssignee(ctx, *assigneeID, order)
+	}
+	return nil
+}
+
+func (s *orderAssignmentService) RecordFirstResponse(ctx context.Context, orderID uuid.UUID) error {
+	return s.repo.MarkFirstResponse(ctx, orderID)
+}
+
+func (s *orderAssignmentService) AssignOrder(ctx context.Context, orderID uuid.UUID, dto domain.AssignOrderDTO, userID uuid.UUID) (*domain.OrderResponse, error) {
+	if dto.AssigneeID != nil {
+		assignee, err := s.users.GetByID(ctx, *dto.AssigneeID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidAssignee, err)
+		}
+		if assignee.Role != domain.RoleStaff && assignee.Role != domain.RoleAdmin {
+			return nil, domain.ErrInvalidAssignee
+		}
+	}
+
+	if err := s.repo.Assign(ctx, orderID, dto.AssigneeID, userID, dto.Comment); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.AssigneeID != nil && *dto.AssigneeID != userID {
+		s.notifyAssignee(ctx, *dto.AssigneeID, order)
+	}
+	return order, nil
+}
+
+// Start runs the escalation worker.
+func (s *orderAssignmentService) Start(ctx context.Context) {
+	s.logger.Info("starting order sla worker",
+		slog.Duration("first_response", s.opts.SLA.FirstResponse),
+		slog.Duration("processing", s.opts.SLA.Processing),
+		slog.Duration("period", s.opts.CheckInterval),
+	)
+
+	go func() {
+		ticker := time.NewTicker(s.opts.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.logger.Info("stopping order sla worker")
+				return
+			case <-ticker.C:
+				s.escalateBreaches(ctx)
+			}
+		}
+	}()
+}
+
+// escalateBreaches tells admins about every timer past due, once per timer.
+func (s *orderAssignmentService) escalateBreaches(ctx context.Context) {
+	breaches, err := s.repo.ListSLABreaches(ctx, time.Now(), 100)
+	if err != nil {
+		s.logger.Error("failed to list order sla breaches", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, breach := range breaches {
+		var assignee *domain.User
+		if breach.Order.AssigneeID != nil {
+			if user, err := s.users.GetByID(ctx, *breach.Order.AssigneeID); err == nil {
+				assignee = user
+			}
+		}
+
+		if err := s.adminNotifications.NotifySLABreach(ctx, &breach.Order, breach.Kind, breach.Due, assignee); err != nil {
+			s.logger.Error("failed to escalate order sla breach",
+				slog.String("order_id", breach.Order.ID.String()),
+				slog.String("sla", string(breach.Kind)),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if err := s.repo.MarkSLAEscalated(ctx, breach.Order.ID, breach.Kind); err != nil {
+			s.logger.Error("failed to mark order sla escalated", slog.String("order_id", breach.Order.ID.String()), slog.String("error", err.Error()))
+			continue
+		}
+
+		s.logger.Warn("order sla breached",
+			slog.String("order_id", breach.Order.ID.String()),
+			slog.String("sla", string(breach.Kind)),
+			slog.Time("due", breach.Due),
+		)
+	}
+}
+
+// notifyAssignee messages the assignee through the staff bot. Failures are only logged.
+func (s *orderAssignmentService) notifyAssignee(ctx context.Context, assigneeID uuid.UUID, order *domain.OrderResponse) {
+	assignee, err := s.users.GetByID(ctx, assigneeID)
+	if err != nil || assignee.TelegramID == 0 {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"<b>Вам призначено замовлення #%s</b>\nКлієнт: %s\nТовари: %s\nСума: %.2f грн",
+		shortOrderID(order.ID),
+		buildCustomerTelegramLink(order),
+		html.EscapeString(buildItemsSummary(order.Items)),
+		order.TotalAmount,
+	)
+	if _, err := s.staffSender.SendHTMLMessage(assignee.TelegramID, message); err != nil {
+		s.logger.Warn("failed to notify order assignee",
+			slog.String("order_id", order.ID.String()),
+			slog.Int64("telegram_id", assignee.TelegramID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
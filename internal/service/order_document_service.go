@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/pdf"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+var orderDocumentPrefixes = map[domain.OrderDocumentType]string{
+	domain.OrderDocumentInvoice:  "INV",
+	domain.OrderDocumentReceipt:  "RCP",
+	domain.OrderDocumentWarranty: "WAR",
+}
+
+var orderDocumentCaptions = map[domain.OrderDocumentType]string{
+	domain.OrderDocumentInvoice:  "🧾 Рахунок на оплату",
+	domain.OrderDocumentReceipt:  "🧾 Товарний чек",
+	domain.OrderDocumentWarranty: "🛡 Гарантійний талон",
+}
+
+type orderDocumentService struct {
+	orders    domain.OrderRepository
+	renderer  pdf.DocumentRenderer
+	botSender telegram.Sender
+	logger    *slog.Logger
+}
+
+func NewOrderDocumentService(orders domain.OrderRepository, renderer pdf.DocumentRenderer, botSender telegram.Sender, logger *slog.Logger) domain.OrderDocumentService {
+	return &orderDocumentService{
+		orders:    orders,
+		renderer:  renderer,
+		botSender: botSender,
+		logger:    logger,
+	}
+}
+
+func (s *orderDocumentService) RenderOrderDocument(ctx context.Context, orderID uuid.UUID, docType domain.OrderDocumentType) (*domain.OrderDocument, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(order, docType)
+}
+
+func (s *orderDocumentService) SendOrderDocument(ctx context.Context, orderID uuid.UUID, docType domain.OrderDocumentType) error {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.CustomerTelegramID == nil || *order.CustomerTelegramID == 0 {
+		return fmt.Errorf("order does not have customer_telegram_id")
+	}
+	return s.send(ctx, order, docType)
+}
+
+// GenerateOrderDocuments sends the buyer the documents of the new status: the invoice or the receipt when the
+// order is shipped, depending on whether anything is still owed, and the receipt with the warranty card when
+// it is done. A done order without payments, e.g. closed by an admin override, only gets the warranty card.
+// Each document is sent on its own, so one failure does not hold back the others; the errors are joined.
+// Orders without a Telegram buyer are skipped, staff can still download the documents.
+func (s *orderDocumentService) GenerateOrderDocuments(ctx context.Context, order *domain.OrderResponse, status domain.OrderStatus) error {
+	if order.CustomerTelegramID == nil || *order.CustomerTelegramID == 0 {
+		return nil
+	}
+
+	var docTypes []domain.OrderDocumentType
+	switch status {
+	case domain.OrderStatusShipped:
+		if order.Balance > 0 {
+			docTypes = []domain.OrderDocumentType{domain.OrderDocumentInvoice}
+		} else {
+			docTypes = []domain.OrderDocumentType{domain.OrderDocumentReceipt}
+		}
+	case domain.OrderStatusDone:
+		if order.PaidAmount > 0 {
+			docTypes = append(docTypes, domain.OrderDocumentReceipt)
+		}
+		docTypes = append(docTypes, domain.OrderDocumentWarranty)
+	}
+
+	var errs []error
+	for _, docType := range docTypes {
+		if err := s.send(ctx, order, docType); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send %s: %w", strings.ToLower(string(docType)), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// render checks that the document applies to the order: an invoice to any live order, a receipt once
+// something is paid and a warranty card once the order is done.
+func (s *orderDocumentService) render(order *domain.OrderResponse, docType domain.OrderDocumentType) (*domain.OrderDocument, error) {
+	status := domain.OrderStatus(order.Status)
+	switch docType {
+	case domain.OrderDocumentInvoice:
+		if status == domain.OrderStatusCancelled || status == domain.OrderStatusReturned {
+			return nil, fmt.Errorf("%w: order is %s", domain.ErrOrderDocumentNotAvailable, order.Status)
+		}
+	case domain.OrderDocumentReceipt:
+		if order.PaidAmount <= 0 {
+			return nil, fmt.Errorf("%w: order is not paid", domain.ErrOrderDocumentNotAvailable)
+		}
+	case domain.OrderDocumentWarranty:
+		if status != domain.OrderStatusDone {
+			return nil, fmt.Errorf("%w: warranty card is issued for completed orders", domain.ErrOrderDocumentNotAvailable)
+		}
+	default:
+		return nil, fmt.Errorf("unknown document type: %s", docType)
+	}
+
+	number := orderDocumentPrefixes[docType] + "-" + strings.ToUpper(order.ID.String()[:8])
+	content, err := s.renderer.RenderOrderDocument(docType, number, time.Now(), *order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OrderDocument{
+		Type:     docType,
+		Number:   number,
+		FileName: fmt.Sprintf("%s-%s.pdf", strings.ToLower(string(docType)), number),
+		Content:  content,
+	}, nil
+}
+
+// send delivers the document through the client bot and keeps a note of it in the order thread.
+func (s *orderDocumentService) send(ctx context.Context, order *domain.OrderResponse, docType domain.OrderDocumentType) error {
+	document, err := s.render(order, docType)
+	if err != nil {
+		return err
+	}
+
+	caption := fmt.Sprintf("%s № %s\nЗамовлення: %s", orderDocumentCaptions[docType], document.Number, order.ID.String())
+	telegramMessageID, err := s.botSender.SendDocument(*order.CustomerTelegramID, document.FileName, document.Content, caption)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("order document sent",
+		slog.String("order_id", order.ID.String()),
+		slog.String("type", string(docType)),
+		slog.String("number", document.Number),
+	)
+
+	_, err = s.orders.CreateMessage(ctx, domain.CreateOrderMessageDTO{
+		OrderID:            order.ID,
+		CustomerTelegramID: *order.CustomerTelegramID,
+		Direction:          domain.OrderMessageDirectionOutbound,
+		MessageText:        caption,
+		TelegramMessageID:  telegramMessageID,
+	})
+	return err
+}
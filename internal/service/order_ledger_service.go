@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+type orderLedgerService struct {
+	repo     domain.OrderLedgerRepository
+	notifier telegram.Notifier
+	logger   *slog.Logger
+}
+
+// NewOrderLedgerService initializes the order payments ledger for cash, card and bank transfer entries.
+func NewOrderLedgerService(repo domain.OrderLedgerRepository, notifier telegram.Notifier, logger *slog.Logger) domain.OrderLedgerService {
+	return &orderLedgerService{repo: repo, notifier: notifier, logger: logger}
+}
+
+func (s *orderLedgerService) RecordEntry(ctx context.Context, orderID, userID uuid.UUID, dto domain.CreateLedgerEntryDTO) (*domain.LedgerEntryResponse, error) {
+	s.logger.Info("recording order ledger entry",
+		slog.String("order_id", orderID.String()),
+		slog.String("type", string(dto.Type)),
+		slog.String("method", string(dto.Method)),
+	)
+
+	entry, err := s.repo.AddEntry(ctx, orderID, userID, dto)
+	if err != nil {
+		s.logger.Error("failed to record order ledger entry", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if entry.Type == domain.LedgerEntryRefund {
+		s.notifier.SendAlert(fmt.Sprintf("↩️ Повернено %.2f грн (%s) по замовленню %s.", entry.Amount, entry.Method, orderID))
+	}
+
+	return entry, nil
+}
+
+func (s *orderLedgerService) GetLedger(ctx context.Context, orderID uuid.UUID) (*domain.OrderLedgerResponse, error) {
+	balance, err := s.repo.GetBalance(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListEntries(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OrderLedgerResponse{OrderBalance: *balance, Entries: entries}, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+type orderNoteService struct {
+	notes       domain.OrderNoteRepository
+	orders      domain.OrderRepository
+	users       domain.UserRepository
+	staffSender telegram.Sender
+	logger      *slog.Logger
+}
+
+// NewOrderNoteService creates the notes service. Mentions go through the staff bot only, the client bot
+// is deliberately not a dependency so notes cannot reach the buyer.
+func NewOrderNoteService(
+	notes domain.OrderNoteRepository,
+	orders domain.OrderRepository,
+	users domain.UserRepository,
+	staffSender telegram.Sender,
+	logger *slog.Logger,
+) domain.OrderNoteService {
+	return &orderNoteService{
+		notes:       notes,
+		orders:      orders,
+		users:       users,
+		staffSender: staffSender,
+		logger:      logger,
+	}
+}
+
+func (s *orderNoteService) AddNote(ctx context.Context, orderID, authorID uuid.UUID, dto domain.CreateOrderNoteDTO) (*domain.OrderNote, error) {
+	text := strings.TrimSpace(dto.Text)
+	if text == "" {
+		return nil, fmt.Errorf("note text is required")
+	}
+
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentioned := make([]domain.User, 0, len(dto.MentionIDs))
+	mentionIDs := make([]uuid.UUID, 0, len(dto.MentionIDs))
+	seen := make(map[uuid.UUID]bool, len(dto.MentionIDs))
+	for _, id := range dto.MentionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := s.users.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidMention, err)
+		}
+		if user.Role != domain.RoleStaff && user.Role != domain.RoleAdmin {
+			return nil, domain.ErrInvalidMention
+		}
+		mentioned = append(mentioned, *user)
+		mentionIDs = append(mentionIDs, id)
+	}
+
+	note, err := s.notes.Create(ctx, domain.CreateOrderNoteRecord{
+		OrderID:    orderID,
+		AuthorID:   authorID,
+		Text:       text,
+		MentionIDs: mentionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range mentioned {
+		if user.ID == authorID {
+			continue
+		}
+		s.notifyMention(user, order, note)
+	}
+
+	return note, nil
+}
+
+func (s *orderNoteService) ListNotes(ctx context.Context, orderID uuid.UUID) ([]domain.OrderNote, error) {
+	return s.notes.List(ctx, orderID)
+}
+
+func (s *orderNoteService) GetTimeline(ctx context.Context, orderID uuid.UUID) ([]domain.OrderTimelineEntry, error) {
+	notes, err := s.notes.List(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.orders.ListMessages(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.notes.ListStatusChanges(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]domain.OrderTimelineEntry, 0, len(notes)+len(messages)+len(changes))
+	for i := range changes {
+		timeline = append(timeline, domain.OrderTimelineEntry{
+			Type:         domain.OrderTimelineStatusChange,
+			CreatedAt:    changes[i].CreatedAt,
+			StatusChange: &changes[i],
+		})
+	}
+	for i := range messages {
+		timeline = append(timeline, domain.OrderTimelineEntry{
+			Type:      domain.OrderTimelineMessage,
+			CreatedAt: messages[i].CreatedAt,
+			Message:   &messages[i],
+		})
+	}
+	for i := range notes {
+		timeline = append(timeline, domain.OrderTimelineEntry{
+			Type:      domain.OrderTimelineNote,
+			CreatedAt: notes[i].CreatedAt,
+			Note:      &notes[i],
+		})
+	}
+
+	// Timestamps share one layout, so they sort as strings. Within the same second a status change comes
+	// before the messages it triggered.
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].CreatedAt < timeline[j].CreatedAt
+	})
+
+	return timeline, nil
+}
+
+// notifyMention messages a mentioned staff member through the staff bot. Failures are only logged.
+func (s *orderNoteService) notifyMention(user domain.User, order *domain.OrderResponse, note *domain.OrderNote) {
+	if user.TelegramID == 0 {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"<b>%s згадав вас у нотатці до замовлення #%s</b>\nКлієнт: %s\n\n%s",
+		html.EscapeString(note.AuthorLabel),
+		shortOrderID(order.ID),
+		html.EscapeString(buildCustomerLine(order)),
+		html.EscapeString(note.Text),
+	)
+	if _, err := s.staffSender.SendHTMLMessage(user.TelegramID, message); err != nil {
+		s.logger.Warn("failed to notify mentioned staff",
+			slog.String("order_id", order.ID.String()),
+			slog.Int64("telegram_id", user.TelegramID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
@@ -16,6 +17,12 @@ type orderService struct {
 	notifier           telegram.Notifier
 	botSender          telegram.Sender
 	adminNotifications domain.AdminNotificationService
+	documents          domain.OrderDocumentGenerator
+	fiscal             domain.OrderFiscalizer
+	sla                domain.OrderSLATracker
+	customers          domain.CustomerLinker
+	buyerNotifier      domain.OrderStatusNotifier
+	cancelPolicy       domain.BuyerCancelPolicy
 }
 
 func NewOrderService(
@@ -24,6 +31,12 @@ func NewOrderService(
 	notifier telegram.Notifier,
 	botSender telegram.Sender,
 	adminNotifications domain.AdminNotificationService,
+	documents domain.OrderDocumentGenerator,
+	fiscal domain.OrderFiscalizer,
+	sla domain.OrderSLATracker,
+	customers domain.CustomerLinker,
+	buyerNotifier domain.OrderStatusNotifier,
+	cancelPolicy domain.BuyerCancelPolicy,
 ) domain.OrderService {
 	return &orderService{
 		repo:               repo,
@@ -31,6 +44,12 @@ func NewOrderService(
 		notifier:           notifier,
 		botSender:          botSender,
 		adminNotifications: adminNotifications,
+		documents:          documents,
+		fiscal:             fiscal,
+		sla:                sla,
+		customers:          customers,
+		buyerNotifier:      buyerNotifier,
+		cancelPolicy:       cancelPolicy,
 	}
 }
 
@@ -48,6 +67,9 @@ func (s *orderService) CreateOrder(ctx context.Context, dto domain.CreateOrderDT
 			}
 		}
 	}
+	if dto.Channel == domain.OrderChannelOffline && dto.PromoCode != "" {
+		return uuid.Nil, fmt.Errorf("%w: promo codes are for online orders only", domain.ErrPromoCodeInvalid)
+	}
 	if dto.Channel != domain.OrderChannelOffline && dto.CustomerPhone == "" {
 		return uuid.Nil, fmt.Errorf("customer_phone is required for online orders")
 	}
@@ -61,15 +83,34 @@ func (s *orderService) CreateOrder(ctx context.Context, dto domain.CreateOrderDT
 
 	s.logger.Info("order successfully created", slog.String("order_id", orderID.String()))
 
+	if s.customers != nil {
+		if err := s.customers.LinkOrder(ctx, orderID); err != nil {
+			s.logger.Warn("failed to link order to customer", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		}
+	}
+
 	// Send a Telegram notification about the new order. This is done asynchronously to avoid blocking the main flow.
 	msg := fmt.Sprintf("📦 Нове замовлення від %s!\nID: %s", dto.CustomerName, orderID.String())
 	s.notifier.SendAlert(msg)
 
+	if s.adminNotifications == nil && s.sla == nil {
+		return orderID, nil
+	}
+
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Warn("failed to fetch new order", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return orderID, nil
+	}
+
+	if s.sla != nil {
+		if err := s.sla.StartTracking(ctx, order, userID); err != nil {
+			s.logger.Warn("failed to start order sla tracking", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		}
+	}
+
 	if s.adminNotifications != nil {
-		order, fetchErr := s.repo.GetByID(ctx, orderID)
-		if fetchErr != nil {
-			s.logger.Warn("failed to fetch order for admin notifications", slog.String("order_id", orderID.String()), slog.String("error", fetchErr.Error()))
-		} else if notifyErr := s.adminNotifications.NotifyNewOrder(ctx, order); notifyErr != nil {
+		if notifyErr := s.adminNotifications.NotifyNewOrder(ctx, order); notifyErr != nil {
 			s.logger.Warn("failed to send new-order admin notifications", slog.String("order_id", orderID.String()), slog.String("error", notifyErr.Error()))
 		}
 	}
@@ -77,19 +118,109 @@ func (s *orderService) CreateOrder(ctx context.Context, dto domain.CreateOrderDT
 	return orderID, nil
 }
 
-func (s *orderService) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status string, userID uuid.UUID, comment string) error {
-	s.logger.Info("updating order status", slog.String("order_id", id.String()), slog.String("new_status", status))
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status domain.OrderStatus, userID uuid.UUID, role domain.UserRole, comment string, overrideBalance bool) error {
+	s.logger.Info("updating order status", slog.String("order_id", id.String()), slog.String("new_status", string(status)))
 
-	if err := s.repo.UpdateStatus(ctx, id, status, userID, comment); err != nil {
+	transition, err := s.repo.UpdateStatus(ctx, id, status, userID, role, comment, overrideBalance)
+	if err != nil {
 		return err
 	}
+	if transition == nil {
+		// Status was already set, nothing happened.
+		return nil
+	}
 
 	msg := fmt.Sprintf("🔄 Статус замовлення %s змінен на: %s. Коментарій: %s", id.String(), status, comment)
 	s.notifier.SendAlert(msg)
 
+	s.applyTransitionEffects(ctx, id, *transition, comment)
+
+	return nil
+}
+
+// CancelMyOrder cancels an order on behalf of its buyer. The reason is stored as the audit comment, echoed
+// in the buyer's confirmation and sent to the admins.
+func (s *orderService) CancelMyOrder(ctx context.Context, id, userID uuid.UUID, dto domain.CancelOrderDTO) error {
+	reason := strings.TrimSpace(dto.Reason)
+
+	transition, err := s.repo.CancelByBuyer(ctx, id, userID, reason, s.cancelPolicy)
+	if err != nil {
+		return err
+	}
+	if transition == nil {
+		return nil
+	}
+
+	s.logger.Info("order cancelled by buyer", slog.String("order_id", id.String()), slog.String("user_id", userID.String()))
+	s.notifier.SendAlert(fmt.Sprintf("❌ Клієнт скасував замовлення %s. Причина: %s", id.String(), reason))
+
+	s.applyTransitionEffects(ctx, id, *transition, reason)
+
+	if s.adminNotifications != nil {
+		order, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			s.logger.Warn("failed to fetch order for cancellation notification", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+			return nil
+		}
+		if err := s.adminNotifications.NotifyBuyerCancellation(ctx, order, reason); err != nil {
+			s.logger.Warn("failed to notify admins about buyer cancellation", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+		}
+	}
+
 	return nil
 }
 
+// ListOrderTransitions returns the statuses the caller may move the order to next.
+func (s *orderService) ListOrderTransitions(ctx context.Context, id uuid.UUID, role domain.UserRole) (*domain.OrderTransitionsResponse, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.OrderStatus(order.Status)
+	return &domain.OrderTransitionsResponse{
+		OrderID:     order.ID,
+		Status:      status,
+		Transitions: domain.AllowedOrderTransitions(status, role),
+	}, nil
+}
+
+// applyTransitionEffects runs the side effects that happen after the status change is committed.
+// Restocking is done by the repository inside the transaction. Failures here are only logged,
+// the status change itself has already succeeded.
+func (s *orderService) applyTransitionEffects(ctx context.Context, id uuid.UUID, transition domain.OrderTransition, comment string) {
+	// An offline sale is fiscalized once it is paid or done, whichever comes first.
+	fiscalize := s.fiscal != nil && (transition.To == domain.OrderStatusPaid || transition.To == domain.OrderStatusDone)
+	notifyBuyer := transition.NotifyBuyer && s.buyerNotifier != nil
+	if !notifyBuyer && !transition.GenerateDocuments && !fiscalize {
+		return
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Warn("failed to fetch order for status side effects", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	if fiscalize {
+		if err := s.fiscal.FiscalizeSale(ctx, order); err != nil {
+			s.logger.Warn("failed to queue sale receipt", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+		}
+	}
+
+	if transition.GenerateDocuments && s.documents != nil {
+		if err := s.documents.GenerateOrderDocuments(ctx, order, transition.To); err != nil {
+			s.logger.Warn("failed to generate order documents", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+		}
+	}
+
+	if notifyBuyer {
+		if err := s.buyerNotifier.NotifyStatus(ctx, order, transition.To, comment); err != nil {
+			s.logger.Warn("failed to notify buyer about order status", slog.String("order_id", id.String()), slog.String("error", err.Error()))
+		}
+	}
+}
+
 func (s *orderService) UpdateOrderItemPrice(ctx context.Context, orderID, itemID, userID uuid.UUID, price float64, comment string) error {
 	s.logger.Info(
 		"updating order item price",
@@ -108,6 +239,98 @@ func (s *orderService) UpdateOrderItemPrice(ctx context.Context, orderID, itemID
 	return nil
 }
 
+func (s *orderService) AddOrderItem(ctx context.Context, orderID, userID uuid.UUID, dto domain.AddOrderItemDTO) error {
+	return s.editOrderItems(ctx, orderID, userID, domain.OrderItemEdit{
+		Op:         domain.OrderItemEditAdd,
+		LotID:      dto.LotID,
+		Quantity:   dto.Quantity,
+		FinalPrice: dto.FinalPrice,
+	}, dto.Comment)
+}
+
+func (s *orderService) UpdateOrderItem(ctx context.Context, orderID, itemID, userID uuid.UUID, dto domain.UpdateOrderItemDTO) error {
+	edit := domain.OrderItemEdit{
+		Op:       domain.OrderItemEditUpdate,
+		ItemID:   itemID,
+		Quantity: dto.Quantity,
+	}
+	if dto.LotID != nil {
+		edit.LotID = *dto.LotID
+	}
+	return s.editOrderItems(ctx, orderID, userID, edit, dto.Comment)
+}
+
+func (s *orderService) RemoveOrderItem(ctx context.Context, orderID, itemID, userID uuid.UUID, comment string) error {
+	return s.editOrderItems(ctx, orderID, userID, domain.OrderItemEdit{
+		Op:     domain.OrderItemEditRemove,
+		ItemID: itemID,
+	}, comment)
+}
+
+func (s *orderService) editOrderItems(ctx context.Context, orderID, userID uuid.UUID, edit domain.OrderItemEdit, comment string) error {
+	s.logger.Info(
+		"editing order items",
+		slog.String("order_id", orderID.String()),
+		slog.String("op", string(edit.Op)),
+	)
+
+	if err := s.repo.EditItems(ctx, orderID, userID, edit, comment); err != nil {
+		s.logger.Error("failed to edit order items", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return err
+	}
+
+	msg := fmt.Sprintf("✏️ Склад замовлення %s змінено. Коментарій: %s", orderID.String(), comment)
+	s.notifier.SendAlert(msg)
+
+	return nil
+}
+
+// CreateOrderReturn registers a return (RMA) on a completed order.
+func (s *orderService) CreateOrderReturn(ctx context.Context, orderID, userID uuid.UUID, dto domain.CreateOrderReturnDTO) (*domain.OrderReturnResponse, error) {
+	s.logger.Info("registering order return", slog.String("order_id", orderID.String()), slog.Int("lines", len(dto.Items)))
+
+	returnID, err := s.repo.CreateReturnTx(ctx, orderID, userID, dto)
+	if err != nil {
+		s.logger.Error("failed to register order return", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	returns, err := s.repo.ListReturns(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range returns {
+		if returns[i].ID == returnID {
+			msg := fmt.Sprintf("↩️ Повернення по замовленню %s, сума %.2f. Причина: %s", orderID.String(), returns[i].RefundAmount, dto.Reason)
+			s.notifier.SendAlert(msg)
+			s.fiscalizeReturn(ctx, orderID, &returns[i], dto.RefundMethod)
+			return &returns[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("order return %s not found after creation", returnID)
+}
+
+// fiscalizeReturn queues the return receipt. The return itself is already registered, failures are only logged.
+func (s *orderService) fiscalizeReturn(ctx context.Context, orderID uuid.UUID, orderReturn *domain.OrderReturnResponse, refundMethod domain.PaymentMethod) {
+	if s.fiscal == nil || orderReturn.RefundAmount <= 0 {
+		return
+	}
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Warn("failed to fetch order for return receipt", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.fiscal.FiscalizeReturn(ctx, order, orderReturn, refundMethod); err != nil {
+		s.logger.Warn("failed to queue return receipt", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+	}
+}
+
+func (s *orderService) ListOrderReturns(ctx context.Context, orderID uuid.UUID) ([]domain.OrderReturnResponse, error) {
+	return s.repo.ListReturns(ctx, orderID)
+}
+
 func (s *orderService) SendOrderMessage(ctx context.Context, id uuid.UUID, message string) error {
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -129,7 +352,16 @@ func (s *orderService) SendOrderMessage(ctx context.Context, id uuid.UUID, messa
 		MessageText:        message,
 		TelegramMessageID:  telegramMessageID,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	if s.sla != nil {
+		if err := s.sla.RecordFirstResponse(ctx, order.ID); err != nil {
+			s.logger.Warn("failed to record order first response", slog.String("order_id", order.ID.String()), slog.String("error", err.Error()))
+		}
+	}
+	return nil
 }
 
 func (s *orderService) ListOrderMessages(ctx context.Context, id uuid.UUID) ([]domain.OrderMessage, error) {
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+// PaymentOptions configures payment links.
+type PaymentOptions struct {
+	CallbackBaseURL   string        // Public base URL of this API, callbacks go to {base}/api/v1/payments/{provider}/callback
+	ResultURL         string        // Where the buyer is sent after paying, optional
+	LinkTTL           time.Duration // Lifetime of a payment link
+	PrepaymentPercent float64       // Default prepayment share of the amount due
+}
+
+// Shown to the buyer by Telegram when a pre-checkout query is declined.
+const checkoutDeclinedMessage = "Цей рахунок більше не дійсний. Зверніться, будь ласка, до магазину."
+
+type paymentService struct {
+	providers    map[string]domain.PaymentProvider
+	payments     domain.PaymentRepository
+	orders       domain.OrderRepository
+	orderService domain.OrderService
+	notifier     telegram.Notifier
+	botSender    telegram.Sender
+	logger       *slog.Logger
+	opts         PaymentOptions
+}
+
+func NewPaymentService(
+	providers []domain.PaymentProvider,
+	payments domain.PaymentRepository,
+	orders domain.OrderRepository,
+	orderService domain.OrderService,
+	notifier telegram.Notifier,
+	botSender telegram.Sender,
+	logger *slog.Logger,
+	opts PaymentOptions,
+) domain.PaymentService {
+	byName := make(map[string]domain.PaymentProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &paymentService{
+		providers:    byName,
+		payments:     payments,
+		orders:       orders,
+		orderService: orderService,
+		notifier:     notifier,
+		botSender:    botSender,
+		logger:       logger,
+		opts:         opts,
+	}
+}
+
+// Providers returns the names of the configured providers.
+func (s *paymentService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (s *paymentService) provider(name string) (domain.PaymentProvider, error) {
+	provider, ok := s.providers[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPaymentProviderUnknown, name)
+	}
+	return provider, nil
+}
+
+// CreatePayment creates a payment link for what is still due on the order, or for a prepayment part of it.
+// Links that are still pending count as due, so the buyer can never be asked for more than the balance.
+func (s *paymentService) CreatePayment(ctx context.Context, orderID uuid.UUID, dto domain.CreatePaymentDTO, userID uuid.UUID) (*domain.PaymentResponse, error) {
+	provider, err := s.provider(dto.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.OrderStatus(order.Status).IsFinal() {
+		return nil, fmt.Errorf("%w: order is %s", domain.ErrPaymentNotAllowed, order.Status)
+	}
+	if dto.SendToBuyer && (order.CustomerTelegramID == nil || *order.CustomerTelegramID == 0) {
+		return nil, fmt.Errorf("order has no buyer telegram chat to send the link to")
+	}
+
+	existing, err := s.payments.ListByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	pending := 0.0
+	for _, payment := range existing {
+		if payment.Status == domain.PaymentStatusPending {
+			pending += payment.Amount
+		}
+	}
+
+	if roundMoney(order.Balance) <= 0 {
+		return nil, fmt.Errorf("%w: order is paid in full", domain.ErrPaymentNotAllowed)
+	}
+	due := roundMoney(order.Balance - pending)
+	if due <= 0 {
+		return nil, fmt.Errorf("%w: pending payment links already cover the balance", domain.ErrPaymentNotAllowed)
+	}
+
+	amount := due
+	description := fmt.Sprintf("Оплата замовлення %s", order.ID.String())
+	if dto.Purpose == domain.PaymentPurposePrepayment {
+		if dto.Amount != nil {
+			amount = roundMoney(*dto.Amount)
+		} else {
+			amount = roundMoney(due * s.opts.PrepaymentPercent / 100)
+		}
+		if amount <= 0 || amount >= due {
+			return nil, fmt.Errorf("prepayment must be between 0 and the amount due (%.2f)", due)
+		}
+		description = fmt.Sprintf("Передоплата за замовлення %s", order.ID.String())
+	} else if dto.Amount != nil {
+		return nil, fmt.Errorf("amount can be set only for a prepayment")
+	}
+
+	expiresAt := time.Now().Add(s.opts.LinkTTL)
+	payment, err := s.payments.Create(ctx, domain.CreatePaymentRecord{
+		OrderID:     orderID,
+		Provider:    provider.Name(),
+		Purpose:     dto.Purpose,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+		CreatedByID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := provider.CreateInvoice(ctx, domain.InvoiceRequest{
+		PaymentID:   payment.ID,
+		OrderID:     orderID,
+		Amount:      amount,
+		Currency:    domain.PaymentCurrency,
+		Description: description,
+		CallbackURL: fmt.Sprintf("%s/api/v1/payments/%s/callback", strings.TrimRight(s.opts.CallbackBaseURL, "/"), strings.ToLower(provider.Name())),
+		ResultURL:   s.opts.ResultURL,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		s.logger.Error("failed to create payment link", slog.String("order_id", orderID.String()), slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+		// Close the attempt so it does not linger as pending.
+		if _, _, closeErr := s.payments.ApplyEvent(ctx, domain.PaymentEvent{
+			PaymentID:     payment.ID,
+			Status:        domain.PaymentStatusFailed,
+			FailureReason: domain.TruncateRunes(err.Error(), 300),
+		}); closeErr != nil {
+			s.logger.Warn("failed to close payment attempt", slog.String("payment_id", payment.ID.String()), slog.String("error", closeErr.Error()))
+		}
+		return nil, err
+	}
+
+	payment, err = s.payments.AttachInvoice(ctx, payment.ID, *invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("payment link created", slog.String("order_id", orderID.String()), slog.String("payment_id", payment.ID.String()), slog.String("provider", provider.Name()), slog.Float64("amount", amount))
+
+	if dto.SendToBuyer {
+		if err := s.sendLink(ctx, order, payment); err != nil {
+			s.logger.Warn("failed to send payment link to buyer", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		}
+	}
+
+	return payment, nil
+}
+
+// sendLink messages the payment link to the buyer and keeps the message in the order thread.
+func (s *paymentService) sendLink(ctx context.Context, order *domain.OrderResponse, payment *domain.PaymentResponse) error {
+	text := "💳 Посилання для оплати замовлення"
+	if payment.Purpose == domain.PaymentPurposePrepayment {
+		text = "💳 Посилання для передоплати замовлення"
+	}
+	message := fmt.Sprintf("%s %s\nСума: %.2f грн\n%s", text, order.ID.String(), payment.Amount, payment.PaymentURL)
+
+	telegramMessageID, err := s.botSender.SendMessage(*order.CustomerTelegramID, message)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.orders.CreateMessage(ctx, domain.CreateOrderMessageDTO{
+		OrderID:            order.ID,
+		CustomerTelegramID: *order.CustomerTelegramID,
+		Direction:          domain.OrderMessageDirectionOutbound,
+		MessageText:        message,
+		TelegramMessageID:  telegramMessageID,
+	})
+	return err
+}
+
+func (s *paymentService) ListPayments(ctx context.Context, orderID uuid.UUID) ([]domain.PaymentResponse, error) {
+	return s.payments.ListByOrderID(ctx, orderID)
+}
+
+// CancelOrderLinks invalidates the links a buyer cancellation expired. A provider that cannot cancel
+// keeps its link payable until it expires, a late payment is then booked and staff are asked to refund it.
+func (s *paymentService) CancelOrderLinks(ctx context.Context, orderID uuid.UUID) {
+	payments, err := s.payments.ListByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("failed to list payments of a cancelled order", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	for _, payment := range payments {
+		if payment.Status != domain.PaymentStatusExpired || payment.FailureReason != domain.PaymentReasonOrderCancelled || payment.ProviderRef == "" {
+			continue
+		}
+		provider, ok := s.providers[payment.Provider]
+		if !ok {
+			continue
+		}
+
+		err := provider.CancelInvoice(ctx, payment.ProviderRef)
+		switch {
+		case errors.Is(err, domain.ErrPaymentCancelNotSupported):
+			s.logger.Info("payment link stays open until it expires", slog.String("payment_id", payment.ID.String()), slog.String("provider", payment.Provider))
+		case err != nil:
+			s.logger.Warn("failed to cancel payment link", slog.String("payment_id", payment.ID.String()), slog.String("provider", payment.Provider), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// HandleCallback verifies and stores a provider notification. A successful payment moves the order to
+// PREPAYMENT or PAID depending on how much of the total is covered.
+func (s *paymentService) HandleCallback(ctx context.Context, providerName string, callback domain.PaymentCallback) error {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return err
+	}
+
+	event, err := provider.ParseCallback(ctx, callback)
+	if err != nil {
+		s.logger.Warn("rejected payment callback", slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+		return err
+	}
+
+	existing, err := s.payments.GetByID(ctx, event.PaymentID)
+	if err != nil {
+		return err
+	}
+	if existing.Provider != provider.Name() {
+		return fmt.Errorf("payment %s was not created through %s", existing.ID, provider.Name())
+	}
+
+	payment, changed, err := s.payments.ApplyEvent(ctx, *event)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	s.logger.Info("payment status changed", slog.String("payment_id", payment.ID.String()), slog.String("order_id", payment.OrderID.String()), slog.String("status", string(payment.Status)))
+
+	switch payment.Status {
+	case domain.PaymentStatusSuccess:
+		if payment.Amount != existing.Amount {
+			s.logger.Warn("provider confirmed a different amount", slog.String("payment_id", payment.ID.String()), slog.Float64("requested", existing.Amount), slog.Float64("paid", payment.Amount))
+		}
+		s.settleOrder(ctx, payment)
+	case domain.PaymentStatusReversed:
+		s.notifier.SendAlert(fmt.Sprintf("↩️ Оплату %.2f грн за замовлення %s повернуто платнику (%s).", payment.Amount, payment.OrderID, payment.Provider))
+	}
+
+	return nil
+}
+
+// settleOrder moves the order along the payment edges of the state machine. Orders that already moved
+// further (ready for pickup, shipped) keep their status, staff only get an alert.
+func (s *paymentService) settleOrder(ctx context.Context, payment *domain.PaymentResponse) {
+	s.notifier.SendAlert(fmt.Sprintf("💳 Отримано оплату %.2f грн за замовлення %s (%s).", payment.Amount, payment.OrderID, payment.Provider))
+
+	order, err := s.orders.GetByID(ctx, payment.OrderID)
+	if err != nil {
+		s.logger.Error("failed to fetch paid order", slog.String("order_id", payment.OrderID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	status := domain.OrderStatus(order.Status)
+	if status.IsFinal() {
+		s.notifier.SendAlert(fmt.Sprintf("⚠️ Оплата надійшла за закрите замовлення %s (%s). Потрібне повернення коштів.", order.ID, status))
+		return
+	}
+
+	target := domain.OrderStatusPrepayment
+	if roundMoney(order.Balance) <= 0 {
+		target = domain.OrderStatusPaid
+	}
+	if status == target {
+		return
+	}
+	if _, ok := domain.FindOrderTransition(status, target); !ok {
+		return
+	}
+
+	comment := fmt.Sprintf("Оплата %s через %s", payment.ID, payment.Provider)
+	if err := s.orderService.UpdateOrderStatus(ctx, order.ID, target, uuid.Nil, domain.RoleSystem, comment, false); err != nil && !errors.Is(err, domain.ErrOrderTransitionNotAllowed) {
+		s.logger.Error("failed to move paid order", slog.String("order_id", order.ID.String()), slog.String("status", string(target)), slog.String("error", err.Error()))
+	}
+}
+
+// ConfirmCheckout answers a pre-checkout query: the buyer may pay only a pending, unexpired payment of an
+// open order, for exactly the amount of the link.
+func (s *paymentService) ConfirmCheckout(ctx context.Context, providerName string, checkout domain.PaymentCheckout) error {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return err
+	}
+	answerer, ok := provider.(domain.PaymentCheckoutAnswerer)
+	if !ok {
+		return fmt.Errorf("provider %s does not confirm checkouts", provider.Name())
+	}
+
+	reason := s.checkCheckout(ctx, provider.Name(), checkout)
+	errorMessage := ""
+	if reason != "" {
+		s.logger.Warn("declined payment checkout", slog.String("payload", checkout.Payload), slog.String("reason", reason))
+		errorMessage = checkoutDeclinedMessage
+	}
+
+	return answerer.AnswerCheckout(ctx, checkout.QueryID, errorMessage)
+}
+
+// checkCheckout returns why the checkout must be declined, or an empty string.
+func (s *paymentService) checkCheckout(ctx context.Context, providerName string, checkout domain.PaymentCheckout) string {
+	paymentID, err := uuid.Parse(checkout.Payload)
+	if err != nil {
+		return "unknown payload"
+	}
+	payment, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return "payment not found"
+	}
+
+	switch {
+	case payment.Provider != providerName:
+		return "payment belongs to another provider"
+	case payment.Status != domain.PaymentStatusPending:
+		return "payment is " + string(payment.Status)
+	case checkout.Currency != payment.Currency:
+		return "currency mismatch"
+	case math.Abs(checkout.Amount-payment.Amount) >= 0.01:
+		return "amount mismatch"
+	}
+
+	if payment.ExpiresAt != "" {
+		expiresAt, err := time.ParseInLocation("2006-01-02 15:04:05", payment.ExpiresAt, time.Local)
+		if err == nil && time.Now().After(expiresAt) {
+			return "payment link expired"
+		}
+	}
+
+	order, err := s.orders.GetByID(ctx, payment.OrderID)
+	if err != nil {
+		return "order not found"
+	}
+	if domain.OrderStatus(order.Status).IsFinal() {
+		return "order is " + order.Status
+	}
+	return ""
+}
+
+// roundMoney rounds to kopecks.
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,593 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+const (
+	// maxOrphansPerSweep keeps a single sweep short; the rest is picked up by the next run.
+	maxOrphansPerSweep = 500
+
+	// Direct uploads outside these bounds are rejected before decoding, which protects against decompression bombs.
+	minPhotoDimension = 200
+	maxPhotoDimension = 12000
+
+	photoProcessingQueueSize = 100
+	photoReprocessBatchSize  = 100
+)
+
+// PhotoOptions configures the photo pipeline.
+type PhotoOptions struct {
+	OrphanGrace   time.Duration // How long an unattached upload is kept, e.g. while the lot form is still open
+	SweepInterval time.Duration // How often orphans are removed, 0 disables the sweeper
+	PresignTTL    time.Duration // Lifetime of presigned upload URLs
+	MaxUploadSize int64         // Bytes
+	Workers       int           // Parallel rendition jobs for direct uploads
+}
+
+type photoService struct {
+	storage  domain.StorageService
+	repo     domain.PhotoAssetRepository
+	sessions domain.PhotoUploadSessionRepository
+	logger   *slog.Logger
+	opts     PhotoOptions
+	jobs     chan uuid.UUID
+
+	reprocessMu sync.Mutex
+	reprocess   domain.PhotoReprocessStatus
+}
+
+func NewPhotoService(
+	storage domain.StorageService,
+	repo domain.PhotoAssetRepository,
+	sessions domain.PhotoUploadSessionRepository,
+	logger *slog.Logger,
+	opts PhotoOptions,
+) domain.PhotoService {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+
+	return &photoService{
+		storage:  storage,
+		repo:     repo,
+		sessions: sessions,
+		logger:   logger,
+		opts:     opts,
+		jobs:     make(chan uuid.UUID, photoProcessingQueueSize),
+	}
+}
+
+// UploadPhoto stores all renditions and registers the upload, so lot responses can expose every size
+// and abandoned uploads can be swept later.
+func (s *photoService) UploadPhoto(ctx context.Context, file io.Reader, originalFilename string, uploadedByID uuid.UUID) (*domain.PhotoAsset, error) {
+	asset, err := s.storage.UploadPhoto(ctx, file, originalFilename)
+	if err != nil {
+		s.logger.Error("failed to upload photo", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, asset, uploadedByID); err != nil {
+		s.logger.Error("failed to save photo renditions", slog.String("url", asset.URL), slog.String("error", err.Error()))
+		// Do not leave unreferenced files behind.
+		if delErr := s.storage.DeletePhoto(ctx, asset.URL); delErr != nil {
+			s.logger.Warn("failed to clean up uploaded photo", slog.String("url", asset.URL), slog.String("error", delErr.Error()))
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func (s *photoService) DeletePhoto(ctx context.Context, url string) error {
+	if err := s.storage.DeletePhoto(ctx, url); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteByURL(ctx, url)
+}
+
+func (s *photoService) AttachToLot(ctx context.Context, lotID uuid.UUID, urls []string) error {
+	return s.repo.AttachToLot(ctx, lotID, urls)
+}
+
+// ReleasePhotos is best effort: a photo that fails to delete here is picked up by the orphan sweeper.
+func (s *photoService) ReleasePhotos(ctx context.Context, urls []string) {
+	for _, url := range urls {
+		// Transfers copy photos to the destination lot and orders snapshot them, so a removed photo may still be in use.
+		referenced, err := s.repo.IsReferenced(ctx, url)
+		if err != nil {
+			s.logger.Warn("failed to check photo references", slog.String("url", url), slog.String("error", err.Error()))
+			continue
+		}
+		if referenced {
+			continue
+		}
+
+		if err := s.DeletePhoto(ctx, url); err != nil {
+			s.logger.Warn("failed to delete released photo", slog.String("url", url), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// SweepOrphans finds registered uploads that nothing references after the grace period.
+// With dryRun the photos are only reported.
+func (s *photoService) SweepOrphans(ctx context.Context, dryRun bool) (*domain.PhotoSweepReport, error) {
+	orphans, err := s.repo.ListOrphans(ctx, time.Now().Add(-s.opts.OrphanGrace), maxOrphansPerSweep)
+	if err != nil {
+		s.logger.Error("failed to list orphaned photos", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	report := &domain.PhotoSweepReport{
+		DryRun:      dryRun,
+		GracePeriod: s.opts.OrphanGrace.String(),
+		Photos:      orphans,
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, orphan := range orphans {
+		if err := s.DeletePhoto(ctx, orphan.URL); err != nil {
+			s.logger.Warn("failed to delete orphaned photo", slog.String("url", orphan.URL), slog.String("error", err.Error()))
+			report.Failed = append(report.Failed, orphan.URL)
+			continue
+		}
+		report.Deleted++
+	}
+
+	if len(orphans) > 0 {
+		s.logger.Info("orphaned photos swept", slog.Int("deleted", report.Deleted), slog.Int("failed", len(report.Failed)))
+	}
+
+	return report, nil
+}
+
+// SweepUnregisteredPhotos lists every photo in storage and removes the ones that are neither registered nor
+// referenced after the grace period. The regular sweeper only sees the registry, so this pass is run once
+// to clean up files stored before the registry existed. With dryRun the photos are only reported.
+func (s *photoService) SweepUnregisteredPhotos(ctx context.Context, dryRun bool) (*domain.PhotoSweepReport, error) {
+	stored, err := s.storage.ListPhotos(ctx)
+	if err != nil {
+		s.logger.Error("failed to list stored photos", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	uploadedBefore := time.Now().Add(-s.opts.OrphanGrace)
+	modified := make(map[string]time.Time, len(stored))
+	urls := make([]string, 0, len(stored))
+	for _, photo := range stored {
+		if photo.ModifiedAt.Before(uploadedBefore) {
+			modified[photo.URL] = photo.ModifiedAt
+			urls = append(urls, photo.URL)
+		}
+	}
+
+	orphans, err := s.repo.FilterUnregisteredOrphans(ctx, urls)
+	if err != nil {
+		s.logger.Error("failed to filter unregistered photos", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if len(orphans) > maxOrphansPerSweep {
+		orphans = orphans[:maxOrphansPerSweep]
+	}
+
+	report := &domain.PhotoSweepReport{
+		DryRun:      dryRun,
+		GracePeriod: s.opts.OrphanGrace.String(),
+		Photos:      make([]domain.PhotoUpload, 0, len(orphans)),
+	}
+	for _, url := range orphans {
+		report.Photos = append(report.Photos, domain.PhotoUpload{URL: url, UploadedAt: modified[url].Format("2006-01-02 15:04:05")})
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, url := range orphans {
+		if err := s.storage.DeletePhoto(ctx, url); err != nil {
+			s.logger.Warn("failed to delete unregistered photo", slog.String("url", url), slog.String("error", err.Error()))
+			report.Failed = append(report.Failed, url)
+			continue
+		}
+		report.Deleted++
+	}
+
+	s.logger.Info("unregistered photos swept", slog.Int("stored", len(stored)), slog.Int("deleted", report.Deleted), slog.Int("failed", len(report.Failed)))
+	return report, nil
+}
+
+// PresignUpload registers a pending upload and signs a POST form for a raw object under "uploads/".
+// The form only accepts the declared content type and at most the declared size.
+func (s *photoService) PresignUpload(ctx context.Context, dto domain.PresignPhotoUploadDTO, userID uuid.UUID) (*domain.PresignedPhotoUpload, error) {
+	if dto.Size > s.opts.MaxUploadSize {
+		return nil, fmt.Errorf("file is too large: %d bytes, maximum is %d", dto.Size, s.opts.MaxUploadSize)
+	}
+
+	session := &domain.PhotoUploadSession{
+		ID:           uuid.New(),
+		Status:       domain.PhotoUploadStatusPending,
+		ContentType:  dto.ContentType,
+		UploadedByID: userID,
+	}
+	session.ObjectKey = "uploads/" + session.ID.String()
+	expiresAt := time.Now().Add(s.opts.PresignTTL)
+
+	presigned, err := s.storage.PresignUpload(ctx, session.ObjectKey, dto.ContentType, dto.Size, s.opts.PresignTTL)
+	if err != nil {
+		s.logger.Error("failed to presign photo upload", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := s.sessions.Create(ctx, session, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &domain.PresignedPhotoUpload{
+		UploadID:  session.ID,
+		ObjectKey: session.ObjectKey,
+		UploadURL: presigned.URL,
+		Method:    http.MethodPost,
+		FormData:  presigned.FormData,
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+		MaxSize:   s.opts.MaxUploadSize,
+	}, nil
+}
+
+// FinalizeUpload checks the raw object right away and leaves the heavy resizing to the workers.
+// A rejected object is deleted and the session is marked FAILED, an expired one is marked EXPIRED.
+// When the queue is full the session goes back to PENDING, so the client can finalize again.
+func (s *photoService) FinalizeUpload(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.PhotoUploadSession, error) {
+	session, err := s.sessions.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UploadedByID != userID {
+		return nil, fmt.Errorf("photo upload belongs to another user")
+	}
+	if session.Status != domain.PhotoUploadStatusPending {
+		return session, nil
+	}
+	if time.Now().After(session.Deadline) {
+		s.expireUpload(ctx, *session)
+		return nil, fmt.Errorf("photo upload has expired, request a new upload url")
+	}
+
+	if err := s.validateUploadedObject(ctx, session); err != nil {
+		s.logger.Warn("rejected direct photo upload", slog.String("upload_id", id.String()), slog.String("error", err.Error()))
+		if delErr := s.storage.DeleteObject(ctx, session.ObjectKey); delErr != nil {
+			s.logger.Warn("failed to delete rejected upload", slog.String("object", session.ObjectKey), slog.String("error", delErr.Error()))
+		}
+		pending := []domain.PhotoUploadStatus{domain.PhotoUploadStatusPending}
+		if updErr := s.sessions.UpdateStatus(ctx, id, pending, domain.PhotoUploadStatusFailed, "", err.Error()); updErr != nil {
+			return nil, updErr
+		}
+		return nil, err
+	}
+
+	pending := []domain.PhotoUploadStatus{domain.PhotoUploadStatusPending}
+	if err := s.sessions.UpdateStatus(ctx, id, pending, domain.PhotoUploadStatusProcessing, "", ""); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.jobs <- id:
+	default:
+		s.logger.Warn("photo processing queue is full", slog.String("upload_id", id.String()))
+		processing := []domain.PhotoUploadStatus{domain.PhotoUploadStatusProcessing}
+		if err := s.sessions.UpdateStatus(ctx, id, processing, domain.PhotoUploadStatusPending, "", ""); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("photo processing queue is full, finalize the upload again later")
+	}
+
+	session.Status = domain.PhotoUploadStatusProcessing
+	return session, nil
+}
+
+func (s *photoService) GetUpload(ctx context.Context, id uuid.UUID) (*domain.PhotoUploadSession, error) {
+	session, err := s.sessions.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status == domain.PhotoUploadStatusReady {
+		session.Photo, err = s.repo.GetByURL(ctx, session.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+func (s *photoService) validateUploadedObject(ctx context.Context, session *domain.PhotoUploadSession) error {
+	info, err := s.storage.InspectObject(ctx, session.ObjectKey)
+	if err != nil {
+		return err
+	}
+
+	if info.Size > s.opts.MaxUploadSize {
+		return fmt.Errorf("file is too large: %d bytes, maximum is %d", info.Size, s.opts.MaxUploadSize)
+	}
+	if info.ContentType != "image/jpeg" && info.ContentType != "image/png" {
+		return fmt.Errorf("unsupported file type: %s", info.ContentType)
+	}
+	if info.Width < minPhotoDimension || info.Height < minPhotoDimension {
+		return fmt.Errorf("image is too small: %dx%d, minimum is %dpx", info.Width, info.Height, minPhotoDimension)
+	}
+	if info.Width > maxPhotoDimension || info.Height > maxPhotoDimension {
+		return fmt.Errorf("image is too large: %dx%d, maximum is %dpx", info.Width, info.Height, maxPhotoDimension)
+	}
+
+	return nil
+}
+
+// processUpload renders the raw object into the regular rendition set and removes the raw object.
+func (s *photoService) processUpload(ctx context.Context, id uuid.UUID) {
+	processing := []domain.PhotoUploadStatus{domain.PhotoUploadStatusProcessing}
+
+	session, err := s.sessions.GetByID(ctx, id)
+	if err != nil || session.Status != domain.PhotoUploadStatusProcessing {
+		return
+	}
+
+	fail := func(err error) {
+		s.logger.Error("failed to process direct photo upload", slog.String("upload_id", id.String()), slog.String("error", err.Error()))
+		if updErr := s.sessions.UpdateStatus(ctx, id, processing, domain.PhotoUploadStatusFailed, "", err.Error()); updErr != nil {
+			s.logger.Warn("failed to mark photo upload as failed", slog.String("upload_id", id.String()), slog.String("error", updErr.Error()))
+		}
+	}
+
+	object, err := s.storage.OpenObject(ctx, session.ObjectKey)
+	if err != nil {
+		fail(err)
+		return
+	}
+	asset, err := s.UploadPhoto(ctx, object, session.ObjectKey, session.UploadedByID)
+	object.Close()
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if err := s.sessions.UpdateStatus(ctx, id, processing, domain.PhotoUploadStatusReady, asset.URL, ""); err != nil {
+		s.logger.Warn("failed to mark photo upload as ready", slog.String("upload_id", id.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	if err := s.storage.DeleteObject(ctx, session.ObjectKey); err != nil {
+		s.logger.Warn("failed to delete raw upload", slog.String("object", session.ObjectKey), slog.String("error", err.Error()))
+	}
+}
+
+// expireUploads removes raw objects of presigned URLs that were never finalized.
+func (s *photoService) expireUploads(ctx context.Context) {
+	expired, err := s.sessions.ListExpired(ctx, time.Now())
+	if err != nil {
+		s.logger.Warn("failed to list expired photo uploads", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, session := range expired {
+		s.expireUpload(ctx, session)
+	}
+}
+
+func (s *photoService) expireUpload(ctx context.Context, session domain.PhotoUploadSession) {
+	// The object may not exist if the client never uploaded anything.
+	_ = s.storage.DeleteObject(ctx, session.ObjectKey)
+	pending := []domain.PhotoUploadStatus{domain.PhotoUploadStatusPending}
+	if err := s.sessions.UpdateStatus(ctx, session.ID, pending, domain.PhotoUploadStatusExpired, "", ""); err != nil {
+		s.logger.Warn("failed to expire photo upload", slog.String("upload_id", session.ID.String()), slog.String("error", err.Error()))
+	}
+}
+
+// StartReprocess re-renders registered photos and converts single files uploaded before renditions existed.
+// Those were never registered, so they are found by listing storage.
+func (s *photoService) StartReprocess(ctx context.Context) (*domain.PhotoReprocessStatus, error) {
+	total, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.storage.ListPhotos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var legacy []string
+	for _, photo := range stored {
+		if photo.Legacy {
+			legacy = append(legacy, photo.URL)
+		}
+	}
+	total += int64(len(legacy))
+
+	s.reprocessMu.Lock()
+	defer s.reprocessMu.Unlock()
+
+	if s.reprocess.Running {
+		return nil, fmt.Errorf("photo reprocessing is already running")
+	}
+	s.reprocess = domain.PhotoReprocessStatus{
+		Running:   true,
+		Total:     total,
+		StartedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	s.logger.Info("starting photo reprocessing", slog.Int64("total", total))
+
+	// The job outlives the request that started it.
+	go s.runReprocess(context.WithoutCancel(ctx), legacy)
+
+	status := s.reprocess
+	return &status, nil
+}
+
+func (s *photoService) ReprocessStatus() domain.PhotoReprocessStatus {
+	s.reprocessMu.Lock()
+	defer s.reprocessMu.Unlock()
+
+	status := s.reprocess
+	status.Failed = append([]string(nil), s.reprocess.Failed...)
+	return status
+}
+
+func (s *photoService) runReprocess(ctx context.Context, legacy []string) {
+	afterURL := ""
+	for {
+		urls, err := s.repo.ListURLs(ctx, afterURL, photoReprocessBatchSize)
+		if err != nil {
+			s.logger.Error("photo reprocessing stopped", slog.String("error", err.Error()))
+			break
+		}
+		if len(urls) == 0 {
+			break
+		}
+
+		for _, url := range urls {
+			err := s.reprocessPhoto(ctx, url)
+
+			s.reprocessMu.Lock()
+			s.reprocess.Processed++
+			if err != nil {
+				s.reprocess.Failed = append(s.reprocess.Failed, url)
+			}
+			s.reprocessMu.Unlock()
+
+			if err != nil {
+				s.logger.Warn("failed to reprocess photo", slog.String("url", url), slog.String("error", err.Error()))
+			}
+		}
+		afterURL = urls[len(urls)-1]
+	}
+
+	for _, url := range legacy {
+		err := s.convertLegacyPhoto(ctx, url)
+
+		s.reprocessMu.Lock()
+		s.reprocess.Processed++
+		if err != nil {
+			s.reprocess.Failed = append(s.reprocess.Failed, url)
+		}
+		s.reprocessMu.Unlock()
+
+		if err != nil {
+			s.logger.Warn("failed to convert legacy photo", slog.String("url", url), slog.String("error", err.Error()))
+		}
+	}
+
+	s.reprocessMu.Lock()
+	s.reprocess.Running = false
+	s.reprocess.FinishedAt = time.Now().Format("2006-01-02 15:04:05")
+	s.logger.Info("photo reprocessing finished", slog.Int("processed", s.reprocess.Processed), slog.Int("failed", len(s.reprocess.Failed)))
+	s.reprocessMu.Unlock()
+}
+
+// convertLegacyPhoto renders a single legacy file into a rendition set, moves every reference to the new URL
+// and only then deletes the old file, so a failure leaves the photo where it was.
+func (s *photoService) convertLegacyPhoto(ctx context.Context, url string) error {
+	asset, err := s.storage.ReprocessPhoto(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ReplaceLegacyPhoto(ctx, url, asset); err != nil {
+		return err
+	}
+
+	return s.storage.DeletePhoto(ctx, url)
+}
+
+func (s *photoService) reprocessPhoto(ctx context.Context, url string) error {
+	asset, err := s.storage.ReprocessPhoto(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdateRenditions(ctx, asset)
+}
+
+// Start runs the rendition workers for direct uploads, the upload expiry and the orphan sweeper in the background
+// until ctx is cancelled. Uploads left PROCESSING by a previous run are queued again. Expiry runs every
+// PresignTTL regardless of the sweeper, so raw objects never outlive their URL by much.
+func (s *photoService) Start(ctx context.Context) {
+	for i := 0; i < s.opts.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.jobs:
+					s.processUpload(ctx, id)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		unfinished, err := s.sessions.ListByStatus(ctx, domain.PhotoUploadStatusProcessing)
+		if err != nil {
+			s.logger.Warn("failed to resume photo uploads", slog.String("error", err.Error()))
+			return
+		}
+		for _, session := range unfinished {
+			select {
+			case s.jobs <- session.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if s.opts.PresignTTL > 0 {
+		go func() {
+			ticker := time.NewTicker(s.opts.PresignTTL)
+			defer ticker.Stop()
+
+			for {
+				s.expireUploads(ctx)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	if s.opts.SweepInterval <= 0 {
+		s.logger.Info("orphaned photo sweeper is disabled")
+		return
+	}
+
+	s.logger.Info("starting orphaned photo sweeper", slog.Duration("period", s.opts.SweepInterval), slog.Duration("grace", s.opts.OrphanGrace))
+
+	go func() {
+		ticker := time.NewTicker(s.opts.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.SweepOrphans(ctx, false); err != nil {
+				s.logger.Warn("orphaned photo sweep failed", slog.String("error", err.Error()))
+			}
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("stopping orphaned photo sweeper")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
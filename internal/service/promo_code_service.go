@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type promoCodeService struct {
+	repo   domain.PromoCodeRepository
+	logger *slog.Logger
+}
+
+// NewPromoCodeService initializes promo code management. Codes are redeemed by the order repository
+// while the order is created, so the service only maintains them.
+func NewPromoCodeService(repo domain.PromoCodeRepository, logger *slog.Logger) domain.PromoCodeService {
+	return &promoCodeService{repo: repo, logger: logger}
+}
+
+func (s *promoCodeService) CreatePromoCode(ctx context.Context, dto domain.CreatePromoCodeDTO, userID uuid.UUID) (*domain.PromoCodeResponse, error) {
+	if err := validatePromoValue(dto.Type, dto.Value); err != nil {
+		return nil, err
+	}
+
+	promo, err := s.repo.Create(ctx, dto, userID)
+	if err != nil {
+		s.logger.Error("failed to create promo code", slog.String("code", dto.Code), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.Info("promo code created", slog.String("code", promo.Code), slog.String("promo_code_id", promo.ID.String()))
+	return promo, nil
+}
+
+func (s *promoCodeService) UpdatePromoCode(ctx context.Context, id uuid.UUID, dto domain.UpdatePromoCodeDTO) (*domain.PromoCodeResponse, error) {
+	if err := validatePromoValue(dto.Type, dto.Value); err != nil {
+		return nil, err
+	}
+
+	promo, err := s.repo.Update(ctx, id, dto)
+	if err != nil {
+		s.logger.Error("failed to update promo code", slog.String("promo_code_id", id.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+	return promo, nil
+}
+
+func (s *promoCodeService) GetPromoCode(ctx context.Context, id uuid.UUID) (*domain.PromoCodeResponse, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *promoCodeService) ListPromoCodes(ctx context.Context, filter domain.PromoCodeFilter) ([]domain.PromoCodeResponse, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func (s *promoCodeService) GetPromoCodeUsage(ctx context.Context, id uuid.UUID) (*domain.PromoCodeUsageReport, error) {
+	return s.repo.GetUsage(ctx, id)
+}
+
+func validatePromoValue(discountType domain.PromoDiscountType, value float64) error {
+	if discountType == domain.PromoDiscountPercent && value > 100 {
+		return fmt.Errorf("percentage discount cannot exceed 100")
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type scanService struct {
+	lotRepo             domain.LotRepository
+	transferRepo        domain.TransferRepository
+	storageContractRepo domain.StorageContractRepository
+	locationRepo        domain.WarehouseLocationRepository
+	logger              *slog.Logger
+}
+
+func NewScanService(
+	lotRepo domain.LotRepository,
+	transferRepo domain.TransferRepository,
+	storageContractRepo domain.StorageContractRepository,
+	locationRepo domain.WarehouseLocationRepository,
+	logger *slog.Logger,
+) domain.ScanService {
+	return &scanService{
+		lotRepo:             lotRepo,
+		transferRepo:        transferRepo,
+		storageContractRepo: storageContractRepo,
+		locationRepo:        locationRepo,
+		logger:              logger,
+	}
+}
+
+// Resolve returns the entity behind a scanned code. Guests and buyers may only open active lots;
+// everything else, including internal lot details, is reserved for staff.
+func (s *scanService) Resolve(ctx context.Context, code string, role string) (*domain.ScanResult, error) {
+	isStaff := role == string(domain.RoleAdmin) || role == string(domain.RoleStaff)
+	code = strings.TrimSpace(code)
+
+	// Linear barcodes carry no prefix: our Code128 SKU or a manufacturer EAN.
+	if _, ok := domain.ParseLotSKU(code); ok {
+		lot, err := s.lotRepo.GetBySKU(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		return s.lotResult(lot, isStaff)
+	}
+	if domain.IsEAN(code) {
+		return s.resolveBarcode(ctx, code, isStaff)
+	}
+
+	entity, id, err := domain.ParseScanCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if entity != domain.ScanEntityLot && !isStaff {
+		return nil, domain.ErrScanForbidden
+	}
+
+	s.logger.Debug("resolving scan code", slog.String("type", string(entity)), slog.String("id", id.String()))
+
+	result := &domain.ScanResult{Type: entity, ID: id}
+
+	switch entity {
+	case domain.ScanEntityLot:
+		lot, err := s.lotRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return s.lotResult(lot, isStaff)
+
+	case domain.ScanEntityTransfer:
+		result.Transfer, err = s.transferRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.ScanEntityStorageContract:
+		result.StorageContract, err = s.storageContractRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.ScanEntityLocation:
+		result.Location, err = s.locationRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		lots, _, err := s.lotRepo.ListInternal(ctx, domain.LotFilter{
+			Page:       1,
+			PageSize:   100,
+			Status:     string(domain.LotStatusActive),
+			LocationID: id.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.LocationLots = lots
+	}
+
+	return result, nil
+}
+
+func (s *scanService) lotResult(lot *domain.LotInternalResponse, isStaff bool) (*domain.ScanResult, error) {
+	result := &domain.ScanResult{Type: domain.ScanEntityLot, ID: lot.ID}
+	if isStaff {
+		result.InternalLot = lot
+		return result, nil
+	}
+	if lot.Status != string(domain.LotStatusActive) {
+		return nil, fmt.Errorf("lot is no longer available")
+	}
+	result.Lot = &lot.LotPublicResponse
+	return result, nil
+}
+
+// resolveBarcode lists lots sharing a manufacturer EAN. Buyers only see what is on sale.
+func (s *scanService) resolveBarcode(ctx context.Context, code string, isStaff bool) (*domain.ScanResult, error) {
+	lots, err := s.lotRepo.ListByBarcode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ScanResult{Type: domain.ScanEntityBarcode, Barcode: code}
+	if isStaff {
+		result.InternalLots = lots
+	} else {
+		for _, lot := range lots {
+			if lot.Status == string(domain.LotStatusActive) && lot.CurrentQuantity > 0 {
+				result.Lots = append(result.Lots, lot.LotPublicResponse)
+			}
+		}
+	}
+
+	if len(result.InternalLots) == 0 && len(result.Lots) == 0 {
+		return nil, fmt.Errorf("no lots with barcode %s", code)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+// trackingBatchLimit caps how many shipments one polling pass checks.
+const trackingBatchLimit = 500
+
+const defaultShipmentDescription = "Шини та диски"
+
+type shippingService struct {
+	provider      domain.ShippingProvider
+	shipments     domain.ShipmentRepository
+	orders        domain.OrderRepository
+	lots          domain.LotRepository
+	notifier      telegram.Notifier
+	botSender     telegram.Sender
+	logger        *slog.Logger
+	trackInterval time.Duration
+}
+
+func NewShippingService(
+	provider domain.ShippingProvider,
+	shipments domain.ShipmentRepository,
+	orders domain.OrderRepository,
+	lots domain.LotRepository,
+	notifier telegram.Notifier,
+	botSender telegram.Sender,
+	logger *slog.Logger,
+	trackInterval time.Duration,
+) domain.ShippingService {
+	return &shippingService{
+		provider:      provider,
+		shipments:     shipments,
+		orders:        orders,
+		lots:          lots,
+		notifier:      notifier,
+		botSender:     botSender,
+		logger:        logger,
+		trackInterval: trackInterval,
+	}
+}
+
+func (s *shippingService) SearchCities(ctx context.Context, query string) ([]domain.ShippingCity, error) {
+	return s.provider.SearchCities(ctx, query)
+}
+
+func (s *shippingService) ListBranches(ctx context.Context, cityRef string, query string) ([]domain.ShippingBranch, error) {
+	return s.provider.ListBranches(ctx, cityRef, query)
+}
+
+// CreateShipment creates a waybill for the order with one seat per tire or rim and stores the tracking number.
+func (s *shippingService) CreateShipment(ctx context.Context, orderID uuid.UUID, dto domain.CreateShipmentDTO, userID uuid.UUID) (*domain.ShipmentResponse, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.OrderStatus(order.Status)
+	if status.IsFinal() || status == domain.OrderStatusDone || status == domain.OrderStatusShipped {
+		return nil, fmt.Errorf("cannot ship an order in status %s", status)
+	}
+
+	existing, err := s.shipments.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrShipmentExists, existing.TrackingNumber)
+	}
+
+	firstName, lastName := splitRecipientName(order.CustomerName)
+	if dto.RecipientFirstName != "" {
+		firstName = dto.RecipientFirstName
+	}
+	if dto.RecipientLastName != "" {
+		lastName = dto.RecipientLastName
+	}
+	if firstName == "" || lastName == "" {
+		return nil, fmt.Errorf("recipient first and last name are required")
+	}
+
+	phone := order.CustomerPhone
+	if dto.RecipientPhone != "" {
+		phone = dto.RecipientPhone
+	}
+	phone = normalizeShippingPhone(phone)
+	if len(phone) != 12 {
+		return nil, fmt.Errorf("recipient phone must be a Ukrainian number, got %q", phone)
+	}
+
+	parcels, err := s.buildParcels(ctx, order.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	payer := dto.Payer
+	if payer == "" {
+		payer = domain.ShippingPayerRecipient
+	}
+	description := dto.Description
+	if description == "" {
+		description = defaultShipmentDescription
+	}
+	var cashOnDelivery float64
+	if dto.CashOnDelivery {
+		if order.Balance < 0.005 {
+			return nil, fmt.Errorf("%w: balance is %.2f", domain.ErrNothingToCollect, order.Balance)
+		}
+		cashOnDelivery = order.Balance
+	}
+
+	s.logger.Info("creating waybill", slog.String("order_id", orderID.String()), slog.String("provider", s.provider.Name()), slog.Int("seats", len(parcels)))
+
+	waybill, err := s.provider.CreateWaybill(ctx, domain.WaybillRequest{
+		RecipientFirstName: firstName,
+		RecipientLastName:  lastName,
+		RecipientPhone:     phone,
+		CityRef:            dto.CityRef,
+		BranchRef:          dto.BranchRef,
+		Description:        description,
+		DeclaredValue:      order.TotalAmount,
+		CashOnDelivery:     cashOnDelivery,
+		Payer:              payer,
+		Parcels:            parcels,
+	})
+	if err != nil {
+		s.logger.Error("failed to create waybill", slog.String("order_id", orderID.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	shipment, err := s.shipments.Create(ctx, domain.CreateShipmentRecord{
+		OrderID:        orderID,
+		Provider:       s.provider.Name(),
+		Waybill:        *waybill,
+		RecipientName:  firstName + " " + lastName,
+		RecipientPhone: phone,
+		CityRef:        dto.CityRef,
+		CityName:       dto.CityName,
+		BranchRef:      dto.BranchRef,
+		BranchName:     dto.BranchName,
+		Parcels:        parcels,
+		CashOnDelivery: cashOnDelivery,
+		CreatedByID:    userID,
+	})
+	if err != nil {
+		// The waybill exists at the carrier at this point, keep its number in the logs for manual recovery.
+		s.logger.Error("waybill created but not stored", slog.String("order_id", orderID.String()), slog.String("tracking_number", waybill.TrackingNumber), slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.notifier.SendAlert(fmt.Sprintf("🚚 Створено ТТН %s для замовлення %s", waybill.TrackingNumber, orderID.String()))
+
+	return shipment, nil
+}
+
+func (s *shippingService) GetShipment(ctx context.Context, orderID uuid.UUID) (*domain.ShipmentResponse, error) {
+	shipment, err := s.shipments.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if shipment == nil {
+		return nil, fmt.Errorf("order %s has no shipment", orderID)
+	}
+	return shipment, nil
+}
+
+// RefreshShipment polls the carrier for one order right away instead of waiting for the tracker.
+func (s *shippingService) RefreshShipment(ctx context.Context, orderID uuid.UUID) (*domain.ShipmentResponse, error) {
+	shipment, err := s.GetShipment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.trackShipments(ctx, []domain.ShipmentResponse{*shipment}); err != nil {
+		return nil, err
+	}
+
+	return s.GetShipment(ctx, orderID)
+}
+
+// Start polls tracking statuses of active shipments in the background.
+func (s *shippingService) Start(ctx context.Context) {
+	if s.trackInterval <= 0 {
+		s.logger.Info("shipment tracking is disabled")
+		return
+	}
+
+	s.logger.Info("starting shipment tracking worker", slog.String("provider", s.provider.Name()), slog.Duration("period", s.trackInterval))
+
+	go func() {
+		ticker := time.NewTicker(s.trackInterval)
+		defer ticker.Stop()
+
+		for {
+			s.processTracking(ctx)
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("stopping shipment tracking worker")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *shippingService) processTracking(ctx context.Context) {
+	shipments, err := s.shipments.ListActive(ctx, s.provider.Name(), trackingBatchLimit)
+	if err != nil {
+		s.logger.Error("failed to list active shipments", slog.String("error", err.Error()))
+		return
+	}
+	if len(shipments) == 0 {
+		return
+	}
+
+	if err := s.trackShipments(ctx, shipments); err != nil {
+		s.logger.Error("failed to track shipments", slog.String("error", err.Error()))
+	}
+}
+
+// trackShipments fetches statuses, stores changes and notifies buyers when the delivery state moves.
+func (s *shippingService) trackShipments(ctx context.Context, shipments []domain.ShipmentResponse) error {
+	queries := make([]domain.TrackingQuery, 0, len(shipments))
+	byNumber := make(map[string]domain.ShipmentResponse, len(shipments))
+	for _, shipment := range shipments {
+		queries = append(queries, domain.TrackingQuery{TrackingNumber: shipment.TrackingNumber, Phone: shipment.RecipientPhone})
+		byNumber[shipment.TrackingNumber] = shipment
+	}
+
+	statuses, err := s.provider.Track(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	updated := make(map[uuid.UUID]bool, len(statuses))
+	for _, status := range statuses {
+		shipment, ok := byNumber[status.TrackingNumber]
+		if !ok || status.StatusCode == shipment.StatusCode {
+			continue
+		}
+
+		if err := s.shipments.UpdateStatus(ctx, shipment.ID, status); err != nil {
+			s.logger.Warn("failed to store shipment status", slog.String("tracking_number", status.TrackingNumber), slog.String("error", err.Error()))
+			continue
+		}
+		updated[shipment.ID] = true
+
+		if status.State != shipment.State {
+			if err := s.notifyBuyer(ctx, shipment, status); err != nil {
+				s.logger.Warn("failed to notify buyer about shipment", slog.String("order_id", shipment.OrderID.String()), slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	// Shipments the carrier had nothing new for still go to the back of the polling queue.
+	checked := make([]uuid.UUID, 0, len(shipments))
+	for _, shipment := range shipments {
+		if !updated[shipment.ID] {
+			checked = append(checked, shipment.ID)
+		}
+	}
+	return s.shipments.MarkChecked(ctx, checked)
+}
+
+var shipmentStateBuyerMessages = map[domain.ShipmentState]string{
+	domain.ShipmentStateInTransit: "🚚 Ваша посилка в дорозі.",
+	domain.ShipmentStateArrived:   "📦 Ваша посилка прибула у відділення.",
+	domain.ShipmentStateDelivered: "✅ Посилку отримано. Дякуємо за покупку!",
+	domain.ShipmentStateReturned:  "↩️ Посилка повертається відправнику.",
+}
+
+func (s *shippingService) notifyBuyer(ctx context.Context, shipment domain.ShipmentResponse, status domain.TrackingStatus) error {
+	text, ok := shipmentStateBuyerMessages[status.State]
+	if !ok {
+		return nil
+	}
+
+	order, err := s.orders.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.CustomerTelegramID == nil || *order.CustomerTelegramID == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s\nТТН: %s\nСтатус: %s", text, shipment.TrackingNumber, status.Status)
+	telegramMessageID, err := s.botSender.SendMessage(*order.CustomerTelegramID, message)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.orders.CreateMessage(ctx, domain.CreateOrderMessageDTO{
+		OrderID:            order.ID,
+		CustomerTelegramID: *order.CustomerTelegramID,
+		Direction:          domain.OrderMessageDirectionOutbound,
+		MessageText:        message,
+		TelegramMessageID:  telegramMessageID,
+	})
+	return err
+}
+
+// buildParcels makes one seat per tire or rim unit and one seat per accessory line.
+func (s *shippingService) buildParcels(ctx context.Context, items []domain.OrderItemResponse) ([]domain.ShippingParcel, error) {
+	lotIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		lotIDs = append(lotIDs, item.LotID)
+	}
+
+	lots, _, err := s.lots.ListInternal(ctx, domain.LotFilter{IDs: lotIDs, Page: 1, PageSize: len(lotIDs)})
+	if err != nil {
+		return nil, err
+	}
+	lotsByID := make(map[uuid.UUID]domain.LotInternalResponse, len(lots))
+	for _, lot := range lots {
+		lotsByID[lot.ID] = lot
+	}
+
+	parcels := make([]domain.ShippingParcel, 0, len(items))
+	for _, item := range items {
+		lot, ok := lotsByID[item.LotID]
+		if !ok || lot.Type == "ACCESSORY" {
+			parcels = append(parcels, domain.ParcelForLot("", domain.LotParams{}))
+			continue
+		}
+
+		parcel := domain.ParcelForLot(lot.Type, lot.Params)
+		for i := 0; i < item.Quantity; i++ {
+			parcels = append(parcels, parcel)
+		}
+	}
+
+	return parcels, nil
+}
+
+// splitRecipientName takes "Ім'я Прізвище" as typed by the buyer. Carriers require both parts.
+func splitRecipientName(name string) (string, string) {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return parts[0], ""
+	default:
+		return parts[0], strings.Join(parts[1:], " ")
+	}
+}
+
+// normalizeShippingPhone converts 0XXXXXXXXX, +380XXXXXXXXX and similar to 380XXXXXXXXX.
+func normalizeShippingPhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	switch {
+	case len(normalized) == 10 && strings.HasPrefix(normalized, "0"):
+		return "38" + normalized
+	case len(normalized) == 11 && strings.HasPrefix(normalized, "80"):
+		return "3" + normalized
+	}
+	return normalized
+}
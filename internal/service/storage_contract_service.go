@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
+)
+
+type storageContractService struct {
+	repo           domain.StorageContractRepository
+	photos         domain.PhotoService
+	qrGen          qrcode.Generator
+	botSender      telegram.Sender
+	logger         *slog.Logger
+	reminderLead   time.Duration
+	reminderPeriod time.Duration
+}
+
+// NewStorageContractService initializes the seasonal tire storage module.
+// reminderLead defines how long before the end date the customer gets a bot reminder,
+// reminderPeriod defines how often the background worker checks for due contracts.
+func NewStorageContractService(
+	repo domain.StorageContractRepository,
+	photos domain.PhotoService,
+	qrGen qrcode.Generator,
+	botSender telegram.Sender,
+	logger *slog.Logger,
+	reminderLead time.Duration,
+	reminderPeriod time.Duration,
+) domain.StorageContractService {
+	return &storageContractService{
+		repo:           repo,
+		photos:         photos,
+		qrGen:          qrGen,
+		botSender:      botSender,
+		logger:         logger,
+		reminderLead:   reminderLead,
+		reminderPeriod: reminderPeriod,
+	}
+}
+
+func (s *storageContractService) CreateContract(ctx context.Context, dto domain.CreateStorageContractDTO, userID uuid.UUID) (uuid.UUID, error) {
+	if dto.EndDate < dto.StartDate {
+		return uuid.Nil, fmt.Errorf("end_date must not be before start_date")
+	}
+
+	s.logger.Info("creating storage contract", slog.String("vehicle_plate", dto.VehiclePlate))
+
+	id, err := s.repo.Create(ctx, dto, userID)
+	if err != nil {
+		s.logger.Error("failed to create storage contract", slog.String("error", err.Error()))
+		return uuid.Nil, err
+	}
+
+	s.logger.Info("storage contract created", slog.String("contract_id", id.String()))
+	return id, nil
+}
+
+func (s *storageContractService) UpdateContract(ctx context.Context, id uuid.UUID, dto domain.UpdateStorageContractDTO) error {
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if dto.EndDate != nil && *dto.EndDate < current.StartDate {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+
+	if err := s.repo.Update(ctx, id, dto); err != nil {
+		s.logger.Error("failed to update storage contract", slog.String("contract_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+
+	// Photos removed from the contract are dropped from storage unless something else still shows them.
+	if dto.Photos != nil {
+		s.photos.ReleasePhotos(ctx, subtractPhotos(current.Photos, dto.Photos))
+	}
+
+	return nil
+}
+
+func (s *storageContractService) ReturnContract(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) error {
+	s.logger.Info("returning stored tires to customer", slog.String("contract_id", id.String()))
+
+	if err := s.repo.MarkReturned(ctx, id, userID, comment); err != nil {
+		s.logger.Error("failed to return storage contract", slog.String("contract_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *storageContractService) DeleteContract(ctx context.Context, id uuid.UUID) error {
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Warn("deleting storage contract", slog.String("contract_id", id.String()))
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.photos.ReleasePhotos(ctx, current.Photos)
+	return nil
+}
+
+func (s *storageContractService) GetContract(ctx context.Context, id uuid.UUID) (*domain.StorageContractResponse, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *storageContractService) ListContracts(ctx context.Context, filter domain.StorageContractFilter) ([]domain.StorageContractResponse, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	s.logger.Debug("fetching storage contracts list", slog.Int("page", filter.Page))
+	return s.repo.List(ctx, filter)
+}
+
+func (s *storageContractService) GenerateContractQR(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("generating qr code for storage contract", slog.String("contract_id", id.String()))
+
+	pngBytes, err := s.qrGen.GenerateLinkPNG(domain.ScanCode(domain.ScanEntityStorageContract, id), 256)
+	if err != nil {
+		s.logger.Error("failed to generate qr png", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return pngBytes, nil
+}
+
+// Start runs a background worker that flags overdue contracts and reminds customers
+// through the client bot that their storage period is about to end.
+func (s *storageContractService) Start(ctx context.Context) {
+	if s.reminderPeriod <= 0 {
+		s.logger.Info("storage contract reminders are disabled")
+		return
+	}
+
+	s.logger.Info("starting storage contract reminder worker", slog.Duration("period", s.reminderPeriod))
+
+	go func() {
+		ticker := time.NewTicker(s.reminderPeriod)
+		defer ticker.Stop()
+
+		for {
+			s.processReminders(ctx)
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("stopping storage contract reminder worker")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *storageContractService) processReminders(ctx context.Context) {
+	if overdue, err := s.repo.MarkOverdue(ctx); err != nil {
+		s.logger.Warn("failed to mark overdue storage contracts", slog.String("error", err.Error()))
+	} else if overdue > 0 {
+		s.logger.Info("storage contracts marked as overdue", slog.Int64("count", overdue))
+	}
+
+	deadline := time.Now().UTC().Add(s.reminderLead).Format("2006-01-02")
+	contracts, err := s.repo.ListDueForReminder(ctx, deadline)
+	if err != nil {
+		s.logger.Warn("failed to fetch storage contracts for reminders", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, contract := range contracts {
+		if _, err := s.botSender.SendMessage(*contract.CustomerTelegramID, buildStorageReminderMessage(contract)); err != nil {
+			s.logger.Warn("failed to send storage contract reminder",
+				slog.String("contract_id", contract.ID.String()),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		if err := s.repo.MarkReminderSent(ctx, contract.ID, contract.Status == domain.StorageContractStatusOverdue); err != nil {
+			s.logger.Warn("failed to mark storage contract reminder", slog.String("contract_id", contract.ID.String()), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func buildStorageReminderMessage(contract domain.StorageContractResponse) string {
+	if contract.Status == domain.StorageContractStatusOverdue {
+		return fmt.Sprintf(
+			"Вітаємо, %s! Термін зберігання ваших шин (%s, авто %s) завершився %s. Будь ласка, заберіть їх або продовжте зберігання.",
+			contract.CustomerName,
+			contract.TireDescription,
+			contract.VehiclePlate,
+			contract.EndDate,
+		)
+	}
+
+	return fmt.Sprintf(
+		"Вітаємо, %s! Нагадуємо, що термін зберігання ваших шин (%s, авто %s) завершується %s. Запишіться на заміну шин або продовжте зберігання.",
+		contract.CustomerName,
+		contract.TireDescription,
+		contract.VehiclePlate,
+		contract.EndDate,
+	)
+}
+
+// subtractPhotos returns the photos from current that are missing in updated.
+func subtractPhotos(current []string, updated []string) []string {
+	kept := make(map[string]struct{}, len(updated))
+	for _, photo := range updated {
+		kept[photo] = struct{}{}
+	}
+
+	removed := make([]string, 0)
+	for _, photo := range current {
+		if _, ok := kept[photo]; !ok {
+			removed = append(removed, photo)
+		}
+	}
+	return removed
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
 	"github.com/horoshi10v/tires-shop/internal/infrastructure/telegram"
 )
 
@@ -14,10 +15,11 @@ type transferService struct {
 	repo     domain.TransferRepository
 	logger   *slog.Logger
 	notifier telegram.Notifier
+	qrGen    qrcode.Generator
 }
 
-func NewTransferService(repo domain.TransferRepository, logger *slog.Logger, notifier telegram.Notifier) domain.TransferService {
-	return &transferService{repo: repo, logger: logger, notifier: notifier}
+func NewTransferService(repo domain.TransferRepository, logger *slog.Logger, notifier telegram.Notifier, qrGen qrcode.Generator) domain.TransferService {
+	return &transferService{repo: repo, logger: logger, notifier: notifier, qrGen: qrGen}
 }
 
 func (s *transferService) CreateTransfer(ctx context.Context, dto domain.CreateTransferDTO, userID uuid.UUID) (uuid.UUID, error) {
@@ -79,3 +81,18 @@ func (s *transferService) GetTransfer(ctx context.Context, id uuid.UUID) (*domai
 	s.logger.Debug("fishing transfer details", slog.String("id", id.String()))
 	return s.repo.GetByID(ctx, id)
 }
+
+// GenerateTransferQR returns a label for the transfer paperwork, so the receiving warehouse can open it by scanning.
+func (s *transferService) GenerateTransferQR(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	pngBytes, err := s.qrGen.GenerateLinkPNG(domain.ScanCode(domain.ScanEntityTransfer, id), 256)
+	if err != nil {
+		s.logger.Error("failed to generate qr png", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return pngBytes, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+	"github.com/horoshi10v/tires-shop/internal/infrastructure/qrcode"
+)
+
+type warehouseLocationService struct {
+	repo   domain.WarehouseLocationRepository
+	logger *slog.Logger
+	qrGen  qrcode.Generator
+}
+
+func NewWarehouseLocationService(repo domain.WarehouseLocationRepository, logger *slog.Logger, qrGen qrcode.Generator) domain.WarehouseLocationService {
+	return &warehouseLocationService{
+		repo:   repo,
+		logger: logger,
+		qrGen:  qrGen,
+	}
+}
+
+// CreateLocation adds a node to the warehouse hierarchy. A child must sit deeper than its parent
+// (e.g. a BIN on a SHELF), while top-level nodes may be of any type for small warehouses.
+func (s *warehouseLocationService) CreateLocation(ctx context.Context, warehouseID uuid.UUID, dto domain.CreateWarehouseLocationDTO) (uuid.UUID, error) {
+	if dto.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *dto.ParentID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if domain.WarehouseLocationType(dto.Type).Depth() <= parent.Type.Depth() {
+			return uuid.Nil, fmt.Errorf("%s cannot be placed inside %s", dto.Type, parent.Type)
+		}
+	}
+
+	s.logger.Info("creating warehouse location",
+		slog.String("warehouse_id", warehouseID.String()),
+		slog.String("code", dto.Code),
+	)
+
+	id, err := s.repo.Create(ctx, warehouseID, dto)
+	if err != nil {
+		s.logger.Error("failed to create warehouse location", slog.String("error", err.Error()))
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (s *warehouseLocationService) UpdateLocation(ctx context.Context, id uuid.UUID, dto domain.UpdateWarehouseLocationDTO) error {
+	s.logger.Info("updating warehouse location", slog.String("location_id", id.String()))
+	return s.repo.Update(ctx, id, dto)
+}
+
+func (s *warehouseLocationService) DeleteLocation(ctx context.Context, id uuid.UUID) error {
+	s.logger.Warn("attempting to delete warehouse location", slog.String("location_id", id.String()))
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *warehouseLocationService) ListLocations(ctx context.Context, warehouseID uuid.UUID) ([]domain.WarehouseLocation, error) {
+	return s.repo.ListByWarehouse(ctx, warehouseID)
+}
+
+func (s *warehouseLocationService) GenerateLocationQR(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	location, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("generating qr code for warehouse location", slog.String("location_id", id.String()))
+
+	pngBytes, err := s.qrGen.GenerateLinkPNG(domain.ScanCode(domain.ScanEntityLocation, location.ID), 256)
+	if err != nil {
+		s.logger.Error("failed to generate qr png", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return pngBytes, nil
+}
+
+func (s *warehouseLocationService) MoveLot(ctx context.Context, lotID uuid.UUID, dto domain.MoveLotDTO, userID uuid.UUID) error {
+	s.logger.Info("moving lot to another location", slog.String("lot_id", lotID.String()))
+
+	if err := s.repo.MoveLot(ctx, lotID, dto, userID); err != nil {
+		s.logger.Error("failed to move lot", slog.String("lot_id", lotID.String()), slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func (s *warehouseLocationService) ListLotMoves(ctx context.Context, lotID uuid.UUID) ([]domain.LotLocationMoveResponse, error) {
+	return s.repo.ListLotMoves(ctx, lotID)
+}
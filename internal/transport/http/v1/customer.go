@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type CustomerHandler struct {
+	service domain.CustomerService
+}
+
+func NewCustomerHandler(service domain.CustomerService) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// List returns customers with their lifetime stats.
+//
+//	@Summary      List Customers
+//	@Tags         customers
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        page       query     int     false  "Page number" default(1)
+//	@Param        page_size  query     int     false  "Items per page" default(20)
+//	@Param        search     query     string  false  "Name, username or phone"
+//	@Param        tag        query     string  false  "Tag, e.g. VIP or PROBLEMATIC"
+//	@Success      200        {array}   domain.CustomerResponse
+//	@Router       /staff/customers [get]
+func (h *CustomerHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	customers, total, err := h.service.ListCustomers(c.Request.Context(), domain.CustomerFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   c.Query("search"),
+		Tag:      c.Query("tag"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list customers"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	c.JSON(http.StatusOK, customers)
+}
+
+// Get returns a customer with their lifetime stats.
+//
+//	@Summary      Get Customer
+//	@Tags         customers
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Customer ID"
+//	@Success      200  {object}  domain.CustomerResponse
+//	@Failure      404  {object}  map[string]string "Customer not found"
+//	@Router       /staff/customers/{id} [get]
+func (h *CustomerHandler) Get(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id format"})
+		return
+	}
+
+	customer, err := h.service.GetCustomer(c.Request.Context(), customerID)
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+// Update changes the name, notes or tags of a customer.
+//
+//	@Summary      Update Customer
+//	@Tags         customers
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                    true  "Customer ID"
+//	@Param        data  body      domain.UpdateCustomerDTO  true  "Fields to change"
+//	@Success      200   {object}  domain.CustomerResponse
+//	@Failure      404   {object}  map[string]string "Customer not found"
+//	@Router       /staff/customers/{id} [patch]
+func (h *CustomerHandler) Update(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id format"})
+		return
+	}
+
+	var req domain.UpdateCustomerDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	customer, err := h.service.UpdateCustomer(c.Request.Context(), customerID, req)
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+// ListOrders returns the order history of a customer.
+//
+//	@Summary      List Customer Orders
+//	@Tags         customers
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id         path      string  true   "Customer ID"
+//	@Param        page       query     int     false  "Page number" default(1)
+//	@Param        page_size  query     int     false  "Items per page" default(20)
+//	@Param        status     query     string  false  "Filter by status"
+//	@Success      200        {array}   domain.OrderResponse
+//	@Failure      404        {object}  map[string]string "Customer not found"
+//	@Router       /staff/customers/{id}/orders [get]
+func (h *CustomerHandler) ListOrders(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id format"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	orders, total, err := h.service.ListCustomerOrders(c.Request.Context(), customerID, domain.OrderFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Status:   c.Query("status"),
+	})
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+	if orders == nil {
+		orders = []domain.OrderResponse{}
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	c.JSON(http.StatusOK, orders)
+}
+
+// Merge merges a duplicate customer into the one in the path.
+//
+//	@Summary      Merge Customers
+//	@Description  Moves the orders of source_id to this customer, fills its missing contacts, joins tags and notes, and deletes the duplicate.
+//	@Tags         customers
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                    true  "Customer ID to keep"
+//	@Param        data  body      domain.MergeCustomersDTO  true  "Duplicate to merge"
+//	@Success      200   {object}  domain.CustomerResponse
+//	@Failure      400   {object}  map[string]string "Cannot merge a customer into itself"
+//	@Failure      404   {object}  map[string]string "Customer not found"
+//	@Router       /staff/customers/{id}/merge [post]
+func (h *CustomerHandler) Merge(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id format"})
+		return
+	}
+
+	var req domain.MergeCustomersDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	customer, err := h.service.MergeCustomers(c.Request.Context(), customerID, req, userID)
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+func respondCustomerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCustomerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomerMergeSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
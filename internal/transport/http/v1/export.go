@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -74,3 +75,38 @@ func (h *ExportHandler) ExportPnL(c *gin.Context) {
 		"url":     sheetURL,
 	})
 }
+
+// ExportOrders downloads the filtered orders with their item lines.
+//
+//	@Summary      Export Orders to CSV or XLSX
+//	@Description  Accepts the filters and sorting of the staff order list. Each item line is a row, with the order columns repeated.
+//	@Tags         exports
+//	@Produce      text/csv
+//	@Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Security     RoleAuth
+//	@Param        format  query     string  false  "csv or xlsx" default(csv)
+//	@Success      200     {file}    binary
+//	@Failure      400     {object}  map[string]string "Invalid filter or format"
+//	@Router       /staff/orders/export [get]
+func (h *ExportHandler) ExportOrders(c *gin.Context) {
+	filter, err := buildOrderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := domain.ExportFormat(c.DefaultQuery("format", string(domain.ExportFormatCSV)))
+
+	file, err := h.service.ExportOrders(c.Request.Context(), filter, format)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnsupportedExportFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export orders"})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+file.FileName)
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type FiscalHandler struct {
+	service domain.FiscalService
+}
+
+func NewFiscalHandler(service domain.FiscalService) *FiscalHandler {
+	return &FiscalHandler{service: service}
+}
+
+// GetShift returns the current cashier shift.
+//
+//	@Summary      Get Cashier Shift
+//	@Tags         fiscal
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.FiscalShift
+//	@Failure      503  {object}  map[string]string "Fiscalization is not configured"
+//	@Router       /staff/fiscal/shift [get]
+func (h *FiscalHandler) GetShift(c *gin.Context) {
+	shift, err := h.service.GetShift(c.Request.Context())
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// OpenShift opens the cashier shift, or returns the one already open.
+//
+//	@Summary      Open Cashier Shift
+//	@Tags         fiscal
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.FiscalShift
+//	@Failure      502  {object}  map[string]string "Provider error"
+//	@Failure      503  {object}  map[string]string "Fiscalization is not configured"
+//	@Router       /staff/fiscal/shift/open [post]
+func (h *FiscalHandler) OpenShift(c *gin.Context) {
+	shift, err := h.service.OpenShift(c.Request.Context())
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// CloseShift closes the cashier shift with a Z-report.
+//
+//	@Summary      Close Cashier Shift
+//	@Tags         fiscal
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.FiscalShift
+//	@Failure      502  {object}  map[string]string "Provider error"
+//	@Failure      503  {object}  map[string]string "Fiscalization is not configured"
+//	@Router       /staff/fiscal/shift/close [post]
+func (h *FiscalHandler) CloseShift(c *gin.Context) {
+	shift, err := h.service.CloseShift(c.Request.Context())
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// ListReceipts returns the fiscal receipts queue.
+//
+//	@Summary      List Fiscal Receipts
+//	@Tags         fiscal
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        page       query     int     false  "Page number" default(1)
+//	@Param        page_size  query     int     false  "Items per page" default(20)
+//	@Param        status     query     string  false  "PENDING, PROCESSING, DONE or FAILED"
+//	@Param        order_id   query     string  false  "Order ID"
+//	@Success      200        {array}   domain.FiscalReceiptResponse
+//	@Router       /staff/fiscal/receipts [get]
+func (h *FiscalHandler) ListReceipts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter := domain.FiscalReceiptFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Status:   domain.FiscalReceiptStatus(c.Query("status")),
+	}
+	if raw := c.Query("order_id"); raw != "" {
+		orderID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+			return
+		}
+		filter.OrderID = &orderID
+	}
+
+	receipts, total, err := h.service.ListReceipts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list fiscal receipts"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	c.JSON(http.StatusOK, receipts)
+}
+
+// RetryReceipt queues a failed receipt again.
+//
+//	@Summary      Retry Fiscal Receipt
+//	@Tags         fiscal
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Fiscal receipt ID"
+//	@Success      200  {object}  domain.FiscalReceiptResponse
+//	@Failure      409  {object}  map[string]string "Receipt is not failed"
+//	@Router       /staff/fiscal/receipts/{id}/retry [post]
+func (h *FiscalHandler) RetryReceipt(c *gin.Context) {
+	receiptID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid receipt id format"})
+		return
+	}
+
+	receipt, err := h.service.RetryReceipt(c.Request.Context(), receiptID)
+	if err != nil {
+		respondFiscalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+func respondFiscalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrFiscalNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFiscalReceiptNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFiscalProvider):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
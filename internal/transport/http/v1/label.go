@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type LabelHandler struct {
+	service domain.LabelService
+}
+
+func NewLabelHandler(service domain.LabelService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+// GetLotLabel renders a price tag for a single lot.
+//
+//	@Summary      Lot Price Tag PDF
+//	@Tags         labels
+//	@Produce      application/pdf
+//	@Security     RoleAuth
+//	@Param        id        path      string  true   "Lot ID"
+//	@Param        template  query     string  false  "Label template name" default(a4-3x8)
+//	@Success      200       {file}    binary
+//	@Router       /staff/lots/{id}/label [get]
+func (h *LabelHandler) GetLotLabel(c *gin.Context) {
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lot id format"})
+		return
+	}
+
+	pdfBytes, err := h.service.RenderLotLabel(c.Request.Context(), lotID, c.Query("template"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=label-"+lotID.String()+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// PrintLabels renders a label sheet for many lots.
+//
+//	@Summary      Lot Price Tags PDF
+//	@Description  Lots are selected by lot_ids in the body, or by the regular lot query filters when lot_ids is empty.
+//	@Tags         labels
+//	@Accept       json
+//	@Produce      application/pdf
+//	@Security     RoleAuth
+//	@Param        data    body      domain.PrintLabelsDTO  true   "Selection and template"
+//	@Param        search  query     string                 false  "Search by brand or model"
+//	@Param        brand   query     string                 false  "Filter by brand name"
+//	@Param        status  query     string                 false  "Filter by status"
+//	@Success      200     {file}    binary
+//	@Router       /staff/lots/labels [post]
+func (h *LabelHandler) PrintLabels(c *gin.Context) {
+	var req domain.PrintLabelsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	pdfBytes, err := h.service.RenderLotLabels(c.Request.Context(), req, buildLotFilter(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=labels.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// ListTemplates returns the available label templates.
+//
+//	@Summary      List Label Templates
+//	@Tags         labels
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {array}  domain.LabelTemplateInfo
+//	@Router       /staff/labels/templates [get]
+func (h *LabelHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.ListTemplates())
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -28,6 +29,10 @@ func (h *LotHandler) Create(c *gin.Context) {
 	}
 
 	id, err := h.service.CreateLot(c.Request.Context(), req)
+	if errors.Is(err, domain.ErrInvalidBarcode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
@@ -50,7 +55,14 @@ func (h *LotHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UpdateLot(c.Request.Context(), lotID, req); err != nil {
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	err = h.service.UpdateLot(c.Request.Context(), lotID, req, userID)
+	if errors.Is(err, domain.ErrInvalidBarcode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update lot", "details": err.Error()})
 		return
 	}
@@ -301,6 +313,7 @@ func buildLotFilter(c *gin.Context) domain.LotFilter {
 		SortBy:            c.Query("sort_by"),
 		SortOrder:         c.Query("sort_order"),
 		Status:            c.Query("status"),
+		LocationID:        c.Query("location_id"),
 		Brand:             c.Query("brand"),
 		Type:              c.Query("type"),
 		Search:            c.Query("search"),
@@ -351,3 +364,79 @@ func (h *LotHandler) GetQR(c *gin.Context) {
 
 	c.Data(http.StatusOK, "image/png", pngBytes)
 }
+
+// GetBarcode renders the lot SKU as a Code128 PNG.
+//
+//	@Summary      Lot SKU Barcode
+//	@Tags         lots
+//	@Produce      image/png
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Lot ID"
+//	@Success      200  {file}    binary
+//	@Router       /staff/lots/{id}/barcode [get]
+func (h *LotHandler) GetBarcode(c *gin.Context) {
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lot id format"})
+		return
+	}
+
+	pngBytes, err := h.service.GenerateLotBarcode(c.Request.Context(), lotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate barcode"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", pngBytes)
+}
+
+// FindByBarcode looks lots up by a scanned EAN or SKU.
+//
+//	@Summary      Find Lots By Barcode
+//	@Description  Accepts a manufacturer EAN-8/UPC-A/EAN-13 or a lot SKU printed as Code128.
+//	@Tags         lots
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        code  path      string  true  "EAN or SKU"
+//	@Success      200   {array}   domain.LotInternalResponse
+//	@Router       /staff/lots/by-barcode/{code} [get]
+func (h *LotHandler) FindByBarcode(c *gin.Context) {
+	lots, err := h.service.FindByBarcode(c.Request.Context(), c.Param("code"))
+	if errors.Is(err, domain.ErrInvalidBarcode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrAmbiguousSKU) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "lot not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lots)
+}
+
+// PrefillFromBarcode returns a lot draft copied from the latest lot with the same EAN.
+//
+//	@Summary      Prefill Lot By Barcode
+//	@Tags         lots
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        barcode  query     string  true  "Manufacturer EAN"
+//	@Success      200      {object}  domain.CreateLotDTO
+//	@Router       /staff/lots/prefill [get]
+func (h *LotHandler) PrefillFromBarcode(c *gin.Context) {
+	draft, err := h.service.PrefillFromBarcode(c.Request.Context(), c.Query("barcode"))
+	if errors.Is(err, domain.ErrInvalidBarcode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
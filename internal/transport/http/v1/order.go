@@ -1,8 +1,13 @@
 package v1
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -20,20 +25,35 @@ type telegramWebhookMessage struct {
 	ReplyToMessage *struct {
 		MessageID int64 `json:"message_id"`
 	} `json:"reply_to_message"`
+	SuccessfulPayment json.RawMessage `json:"successful_payment"`
+}
+
+type telegramPreCheckoutQuery struct {
+	ID             string `json:"id"`
+	Currency       string `json:"currency"`
+	TotalAmount    int64  `json:"total_amount"`
+	InvoicePayload string `json:"invoice_payload"`
 }
 
 type telegramWebhookUpdate struct {
-	Message *telegramWebhookMessage `json:"message"`
+	Message          *telegramWebhookMessage   `json:"message"`
+	PreCheckoutQuery *telegramPreCheckoutQuery `json:"pre_checkout_query"`
 }
 
 type OrderHandler struct {
 	service         domain.OrderService
+	payments        domain.PaymentService
+	webhookSecret   string
 	guestOrderGuard *guestOrderGuard
 }
 
-func NewOrderHandler(service domain.OrderService) *OrderHandler {
+// NewOrderHandler creates the order handler. The client bot webhook also carries Telegram Payments
+// updates, which are passed to payments. An empty webhookSecret disables the secret token check.
+func NewOrderHandler(service domain.OrderService, payments domain.PaymentService, webhookSecret string) *OrderHandler {
 	return &OrderHandler{
 		service:         service,
+		payments:        payments,
+		webhookSecret:   webhookSecret,
 		guestOrderGuard: newGuestOrderGuard(),
 	}
 }
@@ -41,7 +61,7 @@ func NewOrderHandler(service domain.OrderService) *OrderHandler {
 // Create handles the HTTP request to create an order.
 //
 //	@Summary      Create a new order
-//	@Description  Place a new order with customer details and tire items.
+//	@Description  Place a new order with customer details and tire items. Online orders may carry a promo_code, the discount is spread over the qualifying items.
 //	@Tags         orders
 //	@Accept       json
 //	@Produce      json
@@ -98,7 +118,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 // UpdateStatus handles the HTTP request to change an order's status.
 //
 //	@Summary      Update Order Status
-//	@Description  Change the status of an existing order (e.g., NEW, PREPAYMENT, DONE, CANCELLED).
+//	@Description  Move the order along the status state machine. Only transitions listed by GET /staff/orders/{id}/transitions for the caller's role are accepted. DONE requires a settled balance unless an admin sends override_balance.
 //	@Tags         orders
 //	@Accept       json
 //	@Produce      json
@@ -109,8 +129,10 @@ func (h *OrderHandler) Create(c *gin.Context) {
 //	@Failure      400   {object}  map[string]string "Bad Request"
 //	@Failure      401   {object}  map[string]string "Unauthorized"
 //	@Failure      403   {object}  map[string]string "Forbidden"
+//	@Failure      404   {object}  map[string]string "Order not found"
+//	@Failure      409   {object}  map[string]string "Transition not allowed or balance not settled"
 //	@Failure      500   {object}  map[string]string "Internal Server Error"
-//	@Router       /staff/orders/{id}/status [put]
+//	@Router       /staff/orders/{id}/status [patch]
 func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	orderIDParam := c.Param("id")
 	orderID, err := uuid.Parse(orderIDParam)
@@ -132,14 +154,59 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	}
 	userID := userIDVal.(uuid.UUID)
 
-	if err := h.service.UpdateOrderStatus(c.Request.Context(), orderID, req.Status, userID, req.Comment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	role, _ := c.Get("userRole")
+	roleName, _ := role.(string)
+
+	if err := h.service.UpdateOrderStatus(c.Request.Context(), orderID, req.Status, userID, domain.UserRole(roleName), req.Comment, req.OverrideBalance); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOrderBalanceOutstanding):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOrderTransitionForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOrderTransitionNotAllowed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "order status updated and logged"})
 }
 
+// ListTransitions returns the statuses the caller may move the order to next.
+//
+//	@Summary      List Allowed Order Transitions
+//	@Description  Returns the current status and the outgoing transitions allowed for the caller's role, with their side effects.
+//	@Tags         orders
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {object}  domain.OrderTransitionsResponse
+//	@Failure      400  {object}  map[string]string "Bad Request"
+//	@Failure      404  {object}  map[string]string "Not Found"
+//	@Router       /staff/orders/{id}/transitions [get]
+func (h *OrderHandler) ListTransitions(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	role, _ := c.Get("userRole")
+	roleName, _ := role.(string)
+
+	transitions, err := h.service.ListOrderTransitions(c.Request.Context(), orderID, domain.UserRole(roleName))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
+
 func (h *OrderHandler) UpdateItemPrice(c *gin.Context) {
 	orderID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -180,6 +247,194 @@ func (h *OrderHandler) UpdateItemPrice(c *gin.Context) {
 	c.JSON(http.StatusOK, order)
 }
 
+// AddItem adds a lot to an open order.
+//
+//	@Summary      Add Order Item
+//	@Description  Deducts stock from the lot and recomputes the order total. Adding a lot already in the order increases its quantity.
+//	@Tags         orders-staff
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                  true  "Order ID"
+//	@Param        data  body      domain.AddOrderItemDTO  true  "Lot and quantity"
+//	@Success      200   {object}  domain.OrderResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Order items are locked"
+//	@Router       /staff/orders/{id}/items [post]
+func (h *OrderHandler) AddItem(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.AddOrderItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+	h.respondItemsEdit(c, orderID, h.service.AddOrderItem(c.Request.Context(), orderID, userID, req))
+}
+
+// UpdateItem changes the quantity of an order item or swaps it to another lot.
+//
+//	@Summary      Update Order Item
+//	@Description  Rebalances stock for the quantity difference. Passing lot_id swaps the item to that lot at its current sell price.
+//	@Tags         orders-staff
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id      path      string                     true  "Order ID"
+//	@Param        itemId  path      string                     true  "Order item ID"
+//	@Param        data    body      domain.UpdateOrderItemDTO  true  "New quantity and optional lot"
+//	@Success      200     {object}  domain.OrderResponse
+//	@Failure      400     {object}  map[string]string "Bad Request"
+//	@Failure      409     {object}  map[string]string "Order items are locked"
+//	@Router       /staff/orders/{id}/items/{itemId} [patch]
+func (h *OrderHandler) UpdateItem(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order item id format"})
+		return
+	}
+
+	var req domain.UpdateOrderItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+	h.respondItemsEdit(c, orderID, h.service.UpdateOrderItem(c.Request.Context(), orderID, itemID, userID, req))
+}
+
+// RemoveItem removes an item from an open order and returns it to stock.
+//
+//	@Summary      Remove Order Item
+//	@Tags         orders-staff
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id       path      string  true   "Order ID"
+//	@Param        itemId   path      string  true   "Order item ID"
+//	@Param        comment  query     string  false  "Reason for the audit log"
+//	@Success      200      {object}  domain.OrderResponse
+//	@Failure      400      {object}  map[string]string "Bad Request"
+//	@Failure      409      {object}  map[string]string "Order items are locked"
+//	@Router       /staff/orders/{id}/items/{itemId} [delete]
+func (h *OrderHandler) RemoveItem(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order item id format"})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+	h.respondItemsEdit(c, orderID, h.service.RemoveOrderItem(c.Request.Context(), orderID, itemID, userID, c.Query("comment")))
+}
+
+// respondItemsEdit maps the result of an item edit and returns the updated order.
+func (h *OrderHandler) respondItemsEdit(c *gin.Context, orderID uuid.UUID, err error) {
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderItemsLocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.service.GetOrderByID(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "items updated but failed to fetch order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CreateReturn registers a return (RMA) on a completed order.
+//
+//	@Summary      Create Order Return
+//	@Description  Returns selected quantities of DONE order items. RESTOCK puts units back into their lot, WRITE_OFF does not. The refund defaults to the sale price of the returned units and is subtracted from P&L in the period of the return.
+//	@Tags         orders-staff
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                       true  "Order ID"
+//	@Param        data  body      domain.CreateOrderReturnDTO  true  "Returned items, refund and reason"
+//	@Success      201   {object}  domain.OrderReturnResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Order is not completed"
+//	@Router       /staff/orders/{id}/returns [post]
+func (h *OrderHandler) CreateReturn(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CreateOrderReturnDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	orderReturn, err := h.service.CreateOrderReturn(c.Request.Context(), orderID, userID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrReturnNotAllowed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, orderReturn)
+}
+
+// ListReturns lists returns registered on an order.
+//
+//	@Summary      List Order Returns
+//	@Tags         orders-staff
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {array}   domain.OrderReturnResponse
+//	@Failure      400  {object}  map[string]string "Bad Request"
+//	@Failure      500  {object}  map[string]string "Internal Server Error"
+//	@Router       /staff/orders/{id}/returns [get]
+func (h *OrderHandler) ListReturns(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	returns, err := h.service.ListOrderReturns(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, returns)
+}
+
 // SendMessage sends a direct Telegram bot message to the order customer.
 //
 //	@Summary      Send Order Message
@@ -249,12 +504,29 @@ func (h *OrderHandler) ListMessages(c *gin.Context) {
 }
 
 func (h *OrderHandler) HandleClientBotWebhook(c *gin.Context) {
+	if h.webhookSecret != "" {
+		token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.webhookSecret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook secret"})
+			return
+		}
+	}
+
 	var update telegramWebhookUpdate
 	if err := c.ShouldBindJSON(&update); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook payload"})
 		return
 	}
 
+	if update.PreCheckoutQuery != nil {
+		h.handlePreCheckout(c, update.PreCheckoutQuery)
+		return
+	}
+	if update.Message != nil && len(update.Message.SuccessfulPayment) > 0 {
+		h.handleSuccessfulPayment(c, update.Message.SuccessfulPayment)
+		return
+	}
+
 	if update.Message == nil || update.Message.Chat.ID == 0 || update.Message.Text == "" {
 		c.JSON(http.StatusOK, gin.H{"message": "ignored"})
 		return
@@ -279,31 +551,80 @@ func (h *OrderHandler) HandleClientBotWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "accepted"})
 }
 
+// handlePreCheckout confirms or declines a Telegram Payments checkout. Telegram waits only 10 seconds for the answer.
+func (h *OrderHandler) handlePreCheckout(c *gin.Context, query *telegramPreCheckoutQuery) {
+	if h.payments == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "ignored"})
+		return
+	}
+
+	if err := h.payments.ConfirmCheckout(c.Request.Context(), domain.PaymentProviderTelegram, domain.PaymentCheckout{
+		QueryID:  query.ID,
+		Payload:  query.InvoicePayload,
+		Currency: query.Currency,
+		Amount:   float64(query.TotalAmount) / 100,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "accepted"})
+}
+
+func (h *OrderHandler) handleSuccessfulPayment(c *gin.Context, body json.RawMessage) {
+	if h.payments == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "ignored"})
+		return
+	}
+
+	if err := h.payments.HandleCallback(c.Request.Context(), domain.PaymentProviderTelegram, domain.PaymentCallback{
+		Body:   body,
+		Header: c.Request.Header,
+	}); err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "accepted"})
+}
+
 // List handles listing orders with filters.
 //
 //	@Summary      List Orders
-//	@Description  Get a paginated list of orders, filterable by status or customer.
+//	@Description  Get a paginated list of orders, filterable by status, customer, assignee, overdue SLA, dates, totals, items, creator and payment state.
 //	@Tags         orders-staff
 //	@Produce      json
 //	@Security     RoleAuth
-//	@Param        page      query     int     false  "Page number" default(1)
-//	@Param        page_size query     int     false  "Items per page" default(10)
-//	@Param        status    query     string  false  "Filter by status"
-//	@Param        customer  query     string  false  "Search by customer name or phone"
-//	@Success      200       {array}   domain.OrderResponse
-//	@Failure      500       {object}  map[string]string "Internal Server Error"
+//	@Param        page           query     int     false  "Page number" default(1)
+//	@Param        page_size      query     int     false  "Items per page" default(10)
+//	@Param        status         query     string  false  "Filter by status"
+//	@Param        channel        query     string  false  "ONLINE or OFFLINE"
+//	@Param        customer       query     string  false  "Search by customer name or phone"
+//	@Param        assignee_id    query     string  false  "Assignee ID, 'me' or 'none' for unassigned orders"
+//	@Param        overdue        query     bool    false  "Only orders with an SLA timer past due"
+//	@Param        start_date     query     string  false  "Placed on or after (YYYY-MM-DD)"
+//	@Param        end_date       query     string  false  "Placed on or before (YYYY-MM-DD)"
+//	@Param        min_total      query     number  false  "Minimum order total"
+//	@Param        max_total      query     number  false  "Maximum order total"
+//	@Param        created_by     query     string  false  "User who placed the order, 'me' for yourself"
+//	@Param        payment_state  query     string  false  "UNPAID, PARTIALLY_PAID, PAID or REFUND_DUE"
+//	@Param        lot_id         query     string  false  "Orders containing the lot"
+//	@Param        brand          query     string  false  "Orders containing an item of the brand"
+//	@Param        width          query     number  false  "Orders containing an item of the width"
+//	@Param        profile        query     number  false  "Orders containing an item of the profile"
+//	@Param        diameter       query     number  false  "Orders containing an item of the diameter"
+//	@Param        warehouse_id   query     string  false  "Orders containing an item stored in the warehouse"
+//	@Param        sort_by        query     string  false  "created_at, total_amount, status or customer_name"
+//	@Param        sort_order     query     string  false  "asc or desc" default(desc)
+//	@Success      200            {array}   domain.OrderResponse
+//	@Failure      400            {object}  map[string]string "Invalid filter"
+//	@Failure      500            {object}  map[string]string "Internal Server Error"
 //	@Router       /staff/orders [get]
 func (h *OrderHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	status := c.Query("status")
-	customer := c.Query("customer")
-
-	filter := domain.OrderFilter{
-		Page:     page,
-		PageSize: pageSize,
-		Status:   status,
-		Customer: customer,
+	filter, err := buildOrderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	orders, total, err := h.service.ListOrders(c.Request.Context(), filter)
@@ -321,6 +642,168 @@ func (h *OrderHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, orders)
 }
 
+// buildOrderFilter reads the staff order search from the query string. It is shared by the list and the export.
+func buildOrderFilter(c *gin.Context) (domain.OrderFilter, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	overdue, _ := strconv.ParseBool(c.Query("overdue"))
+	width, _ := strconv.ParseFloat(c.Query("width"), 64)
+	profile, _ := strconv.ParseFloat(c.Query("profile"), 64)
+	diameter, _ := strconv.ParseFloat(c.Query("diameter"), 64)
+
+	filter := domain.OrderFilter{
+		Page:         page,
+		PageSize:     pageSize,
+		Status:       c.Query("status"),
+		Channel:      domain.OrderChannel(strings.ToUpper(c.Query("channel"))),
+		Customer:     c.Query("customer"),
+		Overdue:      overdue,
+		PaymentState: domain.OrderPaymentState(strings.ToUpper(c.Query("payment_state"))),
+		Brand:        c.Query("brand"),
+		Width:        width,
+		Profile:      profile,
+		Diameter:     diameter,
+		SortBy:       c.Query("sort_by"),
+		SortOrder:    c.Query("sort_order"),
+	}
+
+	switch filter.PaymentState {
+	case "", domain.OrderPaymentUnpaid, domain.OrderPaymentPartial, domain.OrderPaymentPaid, domain.OrderPaymentRefundDue:
+	default:
+		return filter, errors.New("invalid payment state")
+	}
+
+	switch raw := c.Query("assignee_id"); raw {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		userID := c.MustGet("userID").(uuid.UUID)
+		filter.AssigneeID = &userID
+	default:
+		assigneeID, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.New("invalid assignee id format")
+		}
+		filter.AssigneeID = &assigneeID
+	}
+
+	switch raw := c.Query("created_by"); raw {
+	case "":
+	case "me":
+		userID := c.MustGet("userID").(uuid.UUID)
+		filter.CreatedBy = &userID
+	default:
+		createdBy, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.New("invalid creator id format")
+		}
+		filter.CreatedBy = &createdBy
+	}
+
+	if raw := c.Query("lot_id"); raw != "" {
+		lotID, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.New("invalid lot id format")
+		}
+		filter.LotID = &lotID
+	}
+	if raw := c.Query("warehouse_id"); raw != "" {
+		warehouseID, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.New("invalid warehouse id format")
+		}
+		filter.WarehouseID = &warehouseID
+	}
+
+	if raw := c.Query("start_date"); raw != "" {
+		startDate, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return filter, errors.New("invalid start date, use YYYY-MM-DD")
+		}
+		filter.StartDate = &startDate
+	}
+	if raw := c.Query("end_date"); raw != "" {
+		endDate, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return filter, errors.New("invalid end date, use YYYY-MM-DD")
+		}
+		// Include the whole end day
+		endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+		filter.EndDate = &endDate
+	}
+
+	if raw := c.Query("min_total"); raw != "" {
+		minTotal, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return filter, errors.New("invalid min total")
+		}
+		filter.MinTotal = &minTotal
+	}
+	if raw := c.Query("max_total"); raw != "" {
+		maxTotal, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return filter, errors.New("invalid max total")
+		}
+		filter.MaxTotal = &maxTotal
+	}
+
+	return filter, nil
+}
+
+// CancelMyOrder lets the buyer cancel their own order.
+//
+//	@Summary      Cancel My Order
+//	@Description  Cancel an order of the current user. Allowed only in the statuses and time windows set by BUYER_CANCEL_WINDOWS; the reason is sent to the admins.
+//	@Tags         orders
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                 true  "Order ID"
+//	@Param        data  body      domain.CancelOrderDTO  true  "Cancellation reason"
+//	@Success      200   {object}  map[string]string "OK"
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      404   {object}  map[string]string "Order not found"
+//	@Failure      409   {object}  map[string]string "Order can no longer be cancelled"
+//	@Router       /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelMyOrder(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CancelOrderDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user identification missing"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+
+	if err := h.service.CancelMyOrder(c.Request.Context(), orderID, userID, req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrOrderCancelNotAllowed),
+			errors.Is(err, domain.ErrOrderTransitionNotAllowed),
+			errors.Is(err, domain.ErrOrderTransitionForbidden):
+			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrOrderCancelNotAllowed.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel the order"})
+		}
+		return
+	}
+	h.payments.CancelOrderLinks(c.Request.Context(), orderID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "order cancelled"})
+}
+
 // ListMyOrders handles listing orders for the authenticated user.
 //
 //	@Summary      My Orders History
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type OrderAssignmentHandler struct {
+	service domain.OrderAssignmentService
+}
+
+func NewOrderAssignmentHandler(service domain.OrderAssignmentService) *OrderAssignmentHandler {
+	return &OrderAssignmentHandler{service: service}
+}
+
+// Assign sets or clears the order assignee.
+//
+//	@Summary      Assign Order
+//	@Description  Assign the order to a staff member or an admin, or unassign it with a null assignee_id. The assignee is told through the staff bot.
+//	@Tags         orders-staff
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                 true  "Order ID"
+//	@Param        data  body      domain.AssignOrderDTO  true  "Assignee"
+//	@Success      200   {object}  domain.OrderResponse
+//	@Failure      400   {object}  map[string]string "Invalid assignee"
+//	@Router       /staff/orders/{id}/assignee [put]
+func (h *OrderAssignmentHandler) Assign(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.AssignOrderDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	order, err := h.service.AssignOrder(c.Request.Context(), orderID, req, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAssignee) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type OrderDocumentHandler struct {
+	service domain.OrderDocumentService
+}
+
+func NewOrderDocumentHandler(service domain.OrderDocumentService) *OrderDocumentHandler {
+	return &OrderDocumentHandler{service: service}
+}
+
+// GetDocument renders an order document as PDF.
+//
+//	@Summary      Order Document PDF
+//	@Description  Renders an invoice, a receipt (paid orders) or a warranty card (completed orders) with the company requisites and the payment status.
+//	@Tags         orders
+//	@Produce      application/pdf
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Order ID"
+//	@Param        type  path      string  true  "Document type" Enums(invoice, receipt, warranty)
+//	@Success      200   {file}    binary
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Document is not available for the order"
+//	@Router       /staff/orders/{id}/documents/{type} [get]
+func (h *OrderDocumentHandler) GetDocument(c *gin.Context) {
+	orderID, docType, ok := parseOrderDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.service.RenderOrderDocument(c.Request.Context(), orderID, docType)
+	if err != nil {
+		writeOrderDocumentError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename="+document.FileName)
+	c.Data(http.StatusOK, "application/pdf", document.Content)
+}
+
+// SendDocument sends an order document to the buyer through the client bot.
+//
+//	@Summary      Send Order Document to Buyer
+//	@Tags         orders
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Order ID"
+//	@Param        type  path      string  true  "Document type" Enums(invoice, receipt, warranty)
+//	@Success      200   {object}  map[string]string
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Document is not available for the order"
+//	@Router       /staff/orders/{id}/documents/{type}/send [post]
+func (h *OrderDocumentHandler) SendDocument(c *gin.Context) {
+	orderID, docType, ok := parseOrderDocumentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SendOrderDocument(c.Request.Context(), orderID, docType); err != nil {
+		writeOrderDocumentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "sent"})
+}
+
+func parseOrderDocumentParams(c *gin.Context) (uuid.UUID, domain.OrderDocumentType, bool) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return uuid.Nil, "", false
+	}
+
+	docType, ok := domain.ParseOrderDocumentType(c.Param("type"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document type, expected invoice, receipt or warranty"})
+		return uuid.Nil, "", false
+	}
+
+	return orderID, docType, true
+}
+
+func writeOrderDocumentError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrOrderDocumentNotAvailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
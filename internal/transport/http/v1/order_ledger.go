@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type OrderLedgerHandler struct {
+	service domain.OrderLedgerService
+}
+
+func NewOrderLedgerHandler(service domain.OrderLedgerService) *OrderLedgerHandler {
+	return &OrderLedgerHandler{service: service}
+}
+
+// CreateEntry records money taken from the buyer or paid back.
+//
+//	@Summary      Record Order Payment or Refund
+//	@Description  Records a cash, card terminal or bank transfer payment, or a refund. Payments cannot exceed the balance and refunds cannot exceed what was paid. Online payments are recorded by provider callbacks.
+//	@Tags         orders
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                       true  "Order ID"
+//	@Param        data  body      domain.CreateLedgerEntryDTO  true  "Ledger entry"
+//	@Success      201   {object}  domain.LedgerEntryResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Amount exceeds the balance or the paid amount"
+//	@Router       /staff/orders/{id}/ledger [post]
+func (h *OrderLedgerHandler) CreateEntry(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CreateLedgerEntryDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	entry, err := h.service.RecordEntry(c.Request.Context(), orderID, userID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrLedgerEntryNotAllowed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetLedger returns the payments and refunds of an order with its balance.
+//
+//	@Summary      Get Order Ledger
+//	@Tags         orders
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {object}  domain.OrderLedgerResponse
+//	@Failure      404  {object}  map[string]string "Not Found"
+//	@Router       /staff/orders/{id}/ledger [get]
+func (h *OrderLedgerHandler) GetLedger(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	ledger, err := h.service.GetLedger(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type OrderNoteHandler struct {
+	service domain.OrderNoteService
+}
+
+func NewOrderNoteHandler(service domain.OrderNoteService) *OrderNoteHandler {
+	return &OrderNoteHandler{service: service}
+}
+
+// AddNote adds an internal note to an order.
+//
+//	@Summary      Add Order Note
+//	@Description  Add a staff-only note. Mentioned staff get a message from the staff bot; notes are never sent to the buyer.
+//	@Tags         orders-staff
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                     true  "Order ID"
+//	@Param        data  body      domain.CreateOrderNoteDTO  true  "Note"
+//	@Success      201   {object}  domain.OrderNote
+//	@Failure      400   {object}  map[string]string "Invalid note or mention"
+//	@Router       /staff/orders/{id}/notes [post]
+func (h *OrderNoteHandler) AddNote(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CreateOrderNoteDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	note, err := h.service.AddNote(c.Request.Context(), orderID, userID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMention) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// ListNotes returns the internal notes of an order.
+//
+//	@Summary      List Order Notes
+//	@Tags         orders-staff
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {array}   domain.OrderNote
+//	@Router       /staff/orders/{id}/notes [get]
+func (h *OrderNoteHandler) ListNotes(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	notes, err := h.service.ListNotes(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list order notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// GetTimeline returns the order history.
+//
+//	@Summary      Get Order Timeline
+//	@Description  Notes, buyer messages and status changes of an order, oldest first.
+//	@Tags         orders-staff
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {array}   domain.OrderTimelineEntry
+//	@Router       /staff/orders/{id}/timeline [get]
+func (h *OrderNoteHandler) GetTimeline(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	timeline, err := h.service.GetTimeline(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build order timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
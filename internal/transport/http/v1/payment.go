@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+// Provider callbacks are small JSON or form bodies.
+const maxPaymentCallbackSize = 64 << 10
+
+type PaymentHandler struct {
+	service domain.PaymentService
+}
+
+func NewPaymentHandler(service domain.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// ListProviders lists the configured payment providers.
+//
+//	@Summary      List Payment Providers
+//	@Tags         payments
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {array}  string
+//	@Router       /staff/payments/providers [get]
+func (h *PaymentHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Providers())
+}
+
+// CreatePayment creates a payment link for an order.
+//
+//	@Summary      Create Order Payment Link
+//	@Description  Creates a payment link for the amount still due, or for a prepayment part of it. With send_to_buyer the link is messaged through the client bot.
+//	@Tags         payments
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                   true  "Order ID"
+//	@Param        data  body      domain.CreatePaymentDTO  true  "Provider and amount"
+//	@Success      201   {object}  domain.PaymentResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Order does not accept payments"
+//	@Failure      502   {object}  map[string]string "Provider error"
+//	@Router       /staff/orders/{id}/payments [post]
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CreatePaymentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	payment, err := h.service.CreatePayment(c.Request.Context(), orderID, req, userID)
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// ListPayments lists the payment attempts of an order.
+//
+//	@Summary      List Order Payments
+//	@Tags         payments
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {array}   domain.PaymentResponse
+//	@Failure      400  {object}  map[string]string "Bad Request"
+//	@Router       /staff/orders/{id}/payments [get]
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	payments, err := h.service.ListPayments(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// Callback receives a signed provider notification.
+//
+//	@Summary      Payment Provider Callback
+//	@Description  Server-to-server notification of LiqPay or Monobank. The signature is verified before anything is stored, repeated notifications are ignored.
+//	@Tags         payments
+//	@Accept       json
+//	@Produce      json
+//	@Param        provider  path      string  true  "Provider name, e.g. liqpay or monobank"
+//	@Success      200       {object}  map[string]string
+//	@Failure      401       {object}  map[string]string "Invalid signature"
+//	@Failure      404       {object}  map[string]string "Unknown provider"
+//	@Router       /payments/{provider}/callback [post]
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPaymentCallbackSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read callback body"})
+		return
+	}
+
+	err = h.service.HandleCallback(c.Request.Context(), c.Param("provider"), domain.PaymentCallback{
+		Body:   body,
+		Header: c.Request.Header,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentProviderUnknown) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "accepted"})
+}
+
+func respondPaymentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPaymentSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPaymentNotAllowed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPaymentProvider):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
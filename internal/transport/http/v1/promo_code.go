@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type PromoCodeHandler struct {
+	service domain.PromoCodeService
+}
+
+func NewPromoCodeHandler(service domain.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{service: service}
+}
+
+// Create creates a promo code.
+//
+//	@Summary      Create Promo Code
+//	@Description  Percentage or fixed discount for online orders. Empty lot_types, brands and conditions match any item, empty dates leave the code open-ended, zero limits mean unlimited.
+//	@Tags         promo-codes
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        data  body      domain.CreatePromoCodeDTO  true  "Promo code rules"
+//	@Success      201   {object}  domain.PromoCodeResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Code already exists"
+//	@Router       /admin/promo-codes [post]
+func (h *PromoCodeHandler) Create(c *gin.Context) {
+	var req domain.CreatePromoCodeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	promo, err := h.service.CreatePromoCode(c.Request.Context(), req, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPromoCodeExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promo)
+}
+
+// Update replaces the rules of a promo code.
+//
+//	@Summary      Update Promo Code
+//	@Description  Replaces the rules, dates, limits and the active flag. Orders already placed keep their discount.
+//	@Tags         promo-codes
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                     true  "Promo Code ID"
+//	@Param        data  body      domain.UpdatePromoCodeDTO  true  "Promo code rules"
+//	@Success      200   {object}  domain.PromoCodeResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Router       /admin/promo-codes/{id} [put]
+func (h *PromoCodeHandler) Update(c *gin.Context) {
+	promoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promo code id format"})
+		return
+	}
+
+	var req domain.UpdatePromoCodeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	promo, err := h.service.UpdatePromoCode(c.Request.Context(), promoID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, promo)
+}
+
+// GetByID retrieves a promo code with its usage counters.
+//
+//	@Summary      Get Promo Code
+//	@Tags         promo-codes
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Promo Code ID"
+//	@Success      200  {object}  domain.PromoCodeResponse
+//	@Failure      404  {object}  map[string]string "Not Found"
+//	@Router       /admin/promo-codes/{id} [get]
+func (h *PromoCodeHandler) GetByID(c *gin.Context) {
+	promoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promo code id format"})
+		return
+	}
+
+	promo, err := h.service.GetPromoCode(c.Request.Context(), promoID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "promo code not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, promo)
+}
+
+// List retrieves promo codes.
+//
+//	@Summary      List Promo Codes
+//	@Tags         promo-codes
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        page       query     int     false  "Page number" default(1)
+//	@Param        page_size  query     int     false  "Items per page" default(20)
+//	@Param        search     query     string  false  "Code prefix"
+//	@Param        active     query     bool    false  "Filter by active flag"
+//	@Success      200        {array}   domain.PromoCodeResponse
+//	@Router       /admin/promo-codes [get]
+func (h *PromoCodeHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter := domain.PromoCodeFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   c.Query("search"),
+	}
+	if raw := c.Query("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid active value"})
+			return
+		}
+		filter.Active = &active
+	}
+
+	promos, total, err := h.service.ListPromoCodes(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list promo codes"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	c.JSON(http.StatusOK, promos)
+}
+
+// GetUsage reports the orders placed with a promo code.
+//
+//	@Summary      Promo Code Usage
+//	@Description  Orders placed with the code and what they brought in. Cancelled orders are listed but not counted.
+//	@Tags         promo-codes
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Promo Code ID"
+//	@Success      200  {object}  domain.PromoCodeUsageReport
+//	@Failure      404  {object}  map[string]string "Not Found"
+//	@Router       /admin/promo-codes/{id}/usage [get]
+func (h *PromoCodeHandler) GetUsage(c *gin.Context) {
+	promoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promo code id format"})
+		return
+	}
+
+	report, err := h.service.GetPromoCodeUsage(c.Request.Context(), promoID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "promo code not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
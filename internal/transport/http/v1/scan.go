@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type ScanHandler struct {
+	service domain.ScanService
+}
+
+func NewScanHandler(service domain.ScanService) *ScanHandler {
+	return &ScanHandler{service: service}
+}
+
+// Resolve tells what a scanned QR code refers to.
+//
+//	@Summary      Resolve Scanned Code
+//	@Description  Accepts a scan code (lot_<id>, transfer_<id>, storage_<id>, bin_<id>) or a legacy bare lot UUID.
+//	@Description  Guests and buyers can only resolve active lots; staff get internal details for every entity.
+//	@Tags         scan
+//	@Produce      json
+//	@Param        code  path      string  true  "Scan code"
+//	@Success      200   {object}  domain.ScanResult
+//	@Failure      403   {object}  map[string]string
+//	@Failure      404   {object}  map[string]string
+//	@Failure      409   {object}  map[string]string
+//	@Router       /scan/{code} [get]
+func (h *ScanHandler) Resolve(c *gin.Context) {
+	role := ""
+	if val, exists := c.Get("userRole"); exists {
+		role, _ = val.(string)
+	}
+
+	result, err := h.service.Resolve(c.Request.Context(), c.Param("code"), role)
+	if err != nil {
+		if errors.Is(err, domain.ErrScanForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrAmbiguousSKU) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type ShippingHandler struct {
+	service domain.ShippingService
+}
+
+func NewShippingHandler(service domain.ShippingService) *ShippingHandler {
+	return &ShippingHandler{service: service}
+}
+
+// SearchCities looks up carrier cities by name.
+//
+//	@Summary      Search Shipping Cities
+//	@Tags         shipping
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        q    query     string  true  "City name prefix"
+//	@Success      200  {array}   domain.ShippingCity
+//	@Failure      502  {object}  map[string]string "Carrier error"
+//	@Failure      503  {object}  map[string]string "Shipping is not configured"
+//	@Router       /staff/shipping/cities [get]
+func (h *ShippingHandler) SearchCities(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	cities, err := h.service.SearchCities(c.Request.Context(), query)
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cities)
+}
+
+// ListBranches lists carrier branches in a city.
+//
+//	@Summary      List Shipping Branches
+//	@Tags         shipping
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        city_ref  query     string  true   "City ref from the city search"
+//	@Param        q         query     string  false  "Branch number or address"
+//	@Success      200       {array}   domain.ShippingBranch
+//	@Failure      502       {object}  map[string]string "Carrier error"
+//	@Failure      503       {object}  map[string]string "Shipping is not configured"
+//	@Router       /staff/shipping/branches [get]
+func (h *ShippingHandler) ListBranches(c *gin.Context) {
+	cityRef := c.Query("city_ref")
+	if cityRef == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "city_ref is required"})
+		return
+	}
+
+	branches, err := h.service.ListBranches(c.Request.Context(), cityRef, c.Query("q"))
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, branches)
+}
+
+// CreateShipment creates a waybill for an order.
+//
+//	@Summary      Create Order Waybill
+//	@Description  Creates a Nova Poshta waybill with one seat per tire or rim, sized from the lot params, and stores the tracking number on the order.
+//	@Tags         shipping
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                    true  "Order ID"
+//	@Param        data  body      domain.CreateShipmentDTO  true  "Destination and recipient"
+//	@Success      201   {object}  domain.ShipmentResponse
+//	@Failure      400   {object}  map[string]string "Bad Request"
+//	@Failure      409   {object}  map[string]string "Waybill already exists or nothing to collect"
+//	@Failure      502   {object}  map[string]string "Carrier error"
+//	@Failure      503   {object}  map[string]string "Shipping is not configured"
+//	@Router       /staff/orders/{id}/shipment [post]
+func (h *ShippingHandler) CreateShipment(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	var req domain.CreateShipmentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	shipment, err := h.service.CreateShipment(c.Request.Context(), orderID, req, userID)
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// GetShipment returns the waybill and last tracking status of an order.
+//
+//	@Summary      Get Order Shipment
+//	@Tags         shipping
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {object}  domain.ShipmentResponse
+//	@Failure      404  {object}  map[string]string "Not Found"
+//	@Router       /staff/orders/{id}/shipment [get]
+func (h *ShippingHandler) GetShipment(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	shipment, err := h.service.GetShipment(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// RefreshShipment polls the carrier for the order right away.
+//
+//	@Summary      Refresh Order Tracking
+//	@Tags         shipping
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Order ID"
+//	@Success      200  {object}  domain.ShipmentResponse
+//	@Failure      400  {object}  map[string]string "Bad Request"
+//	@Failure      502  {object}  map[string]string "Carrier error"
+//	@Router       /staff/orders/{id}/shipment/refresh [post]
+func (h *ShippingHandler) RefreshShipment(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id format"})
+		return
+	}
+
+	shipment, err := h.service.RefreshShipment(c.Request.Context(), orderID)
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+func respondShippingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrShippingNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrShipmentExists), errors.Is(err, domain.ErrNothingToCollect):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrShippingProvider):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
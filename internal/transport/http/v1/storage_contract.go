@@ -0,0 +1,232 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type StorageContractHandler struct {
+	service domain.StorageContractService
+}
+
+func NewStorageContractHandler(service domain.StorageContractService) *StorageContractHandler {
+	return &StorageContractHandler{service: service}
+}
+
+// Create takes customer tires into seasonal storage.
+//
+//	@Summary      Create Storage Contract
+//	@Description  Registers customer tires kept in the warehouse between seasons (Status: STORED).
+//	@Tags         storage-contracts
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        data  body      domain.CreateStorageContractDTO  true  "Storage contract details"
+//	@Success      201   {object}  map[string]interface{}
+//	@Failure      400   {object}  map[string]string
+//	@Router       /staff/storage-contracts [post]
+func (h *StorageContractHandler) Create(c *gin.Context) {
+	var req domain.CreateStorageContractDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	id, err := h.service.CreateContract(c.Request.Context(), req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "storage contract created", "contract_id": id})
+}
+
+// Update changes a storage contract while the tires are still stored.
+//
+//	@Summary      Update Storage Contract
+//	@Tags         storage-contracts
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                           true  "Storage Contract ID"
+//	@Param        data  body      domain.UpdateStorageContractDTO  true  "Fields to update"
+//	@Success      200   {object}  map[string]string
+//	@Router       /staff/storage-contracts/{id} [put]
+func (h *StorageContractHandler) Update(c *gin.Context) {
+	contractID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage contract id"})
+		return
+	}
+
+	var req domain.UpdateStorageContractDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdateContract(c.Request.Context(), contractID, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update storage contract", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "storage contract updated successfully"})
+}
+
+// Return closes the contract when the customer picks the tires up.
+//
+//	@Summary      Return Stored Tires
+//	@Description  Hands the tires back to the customer (Status: RETURNED).
+//	@Tags         storage-contracts
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                           true  "Storage Contract ID"
+//	@Param        data  body      domain.ReturnStorageContractDTO  false "Optional comment"
+//	@Success      200   {object}  map[string]string
+//	@Router       /staff/storage-contracts/{id}/return [post]
+func (h *StorageContractHandler) Return(c *gin.Context) {
+	contractID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage contract id"})
+		return
+	}
+
+	var req domain.ReturnStorageContractDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+			return
+		}
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.ReturnContract(c.Request.Context(), contractID, userID, req.Comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "stored tires returned to customer"})
+}
+
+// Delete removes a storage contract created by mistake.
+//
+//	@Summary      Delete Storage Contract
+//	@Tags         storage-contracts
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Storage Contract ID"
+//	@Success      200   {object}  map[string]string
+//	@Router       /admin/storage-contracts/{id} [delete]
+func (h *StorageContractHandler) Delete(c *gin.Context) {
+	contractID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage contract id"})
+		return
+	}
+
+	if err := h.service.DeleteContract(c.Request.Context(), contractID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete storage contract", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "storage contract deleted successfully"})
+}
+
+// List retrieves storage contracts.
+//
+//	@Summary      List Storage Contracts
+//	@Description  Get paginated list of storage contracts ordered by end date.
+//	@Tags         storage-contracts
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        page          query     int     false  "Page number" default(1)
+//	@Param        page_size     query     int     false  "Items per page" default(10)
+//	@Param        status        query     string  false  "Filter by status (STORED, RETURNED, OVERDUE)"
+//	@Param        warehouse_id  query     string  false  "Filter by warehouse"
+//	@Param        search        query     string  false  "Search by customer name, phone or vehicle plate"
+//	@Success      200           {array}   domain.StorageContractResponse
+//	@Router       /staff/storage-contracts [get]
+func (h *StorageContractHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := domain.StorageContractFilter{
+		Page:        page,
+		PageSize:    pageSize,
+		Status:      c.Query("status"),
+		WarehouseID: c.Query("warehouse_id"),
+		Search:      c.Query("search"),
+	}
+
+	contracts, total, err := h.service.ListContracts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage contracts"})
+		return
+	}
+
+	if contracts == nil {
+		contracts = []domain.StorageContractResponse{}
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("Access-Control-Expose-Headers", "X-Total-Count")
+	c.JSON(http.StatusOK, contracts)
+}
+
+// GetByID retrieves a single storage contract.
+//
+//	@Summary      Get Storage Contract
+//	@Tags         storage-contracts
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Storage Contract ID"
+//	@Success      200   {object}  domain.StorageContractResponse
+//	@Router       /staff/storage-contracts/{id} [get]
+func (h *StorageContractHandler) GetByID(c *gin.Context) {
+	contractID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage contract id"})
+		return
+	}
+
+	contract, err := h.service.GetContract(c.Request.Context(), contractID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "storage contract not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// GetQR returns a QR label for the stored tire set.
+//
+//	@Summary      Storage Contract QR Label
+//	@Tags         storage-contracts
+//	@Produce      png
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Storage Contract ID"
+//	@Success      200   {file}    binary
+//	@Router       /staff/storage-contracts/{id}/qr [get]
+func (h *StorageContractHandler) GetQR(c *gin.Context) {
+	contractID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid storage contract id"})
+		return
+	}
+
+	pngBytes, err := h.service.GenerateContractQR(c.Request.Context(), contractID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate qr code"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", pngBytes)
+}
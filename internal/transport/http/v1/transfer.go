@@ -166,3 +166,28 @@ func (h *TransferHandler) GetByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, transfer)
 }
+
+// GetQR returns a QR label for the transfer.
+//
+//	@Summary      Transfer QR Label
+//	@Tags         transfers
+//	@Produce      png
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Transfer ID"
+//	@Success      200   {file}    binary
+//	@Router       /staff/transfers/{id}/qr [get]
+func (h *TransferHandler) GetQR(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transfer id"})
+		return
+	}
+
+	pngBytes, err := h.service.GenerateTransferQR(c.Request.Context(), transferID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate qr code"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", pngBytes)
+}
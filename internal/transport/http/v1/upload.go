@@ -4,28 +4,30 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/horoshi10v/tires-shop/internal/domain"
 )
 
 type UploadHandler struct {
-	storage domain.StorageService
+	photos domain.PhotoService
 }
 
 // NewUploadHandler creates a new handler for media uploads.
-func NewUploadHandler(storage domain.StorageService) *UploadHandler {
-	return &UploadHandler{storage: storage}
+func NewUploadHandler(photos domain.PhotoService) *UploadHandler {
+	return &UploadHandler{photos: photos}
 }
 
 // UploadPhoto handles multipart/form-data image uploads from clients.
 //
 //	@Summary      Upload a photo
-//	@Description  Compresses the image to JPEG, uploads to MinIO, and returns the public URL.
+//	@Description  Renders thumb, card and full JPEG sizes, uploads them to MinIO,
+//	@Description  and returns the full-size URL together with the rendition set.
 //	@Tags         media
 //	@Accept       multipart/form-data
 //	@Produce      json
 //	@Security     RoleAuth
 //	@Param        file  formData  file  true  "Image file to upload"
-//	@Success      200   {object}  map[string]string "Returns the public URL of the uploaded image"
+//	@Success      200   {object}  map[string]interface{} "Returns the public URL of the uploaded image and its renditions"
 //	@Router       /staff/lots/upload [post]
 func (h *UploadHandler) UploadPhoto(c *gin.Context) {
 	fileHeader, err := c.FormFile("file")
@@ -41,7 +43,9 @@ func (h *UploadHandler) UploadPhoto(c *gin.Context) {
 	}
 	defer file.Close()
 
-	fileURL, err := h.storage.UploadPhoto(c.Request.Context(), file, fileHeader.Filename)
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	asset, err := h.photos.UploadPhoto(c.Request.Context(), file, fileHeader.Filename, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process and upload photo"})
 		return
@@ -49,10 +53,95 @@ func (h *UploadHandler) UploadPhoto(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
-		"url":     fileURL,
+		"url":     asset.URL,
+		"photo":   asset,
 	})
 }
 
+// PresignUpload issues a presigned URL for uploading a photo straight to MinIO.
+//
+//	@Summary      Presign a photo upload
+//	@Description  The client POSTs a multipart form to upload_url with every form_data field and the file last,
+//	@Description  in a field named "file", then calls the finalize endpoint.
+//	@Tags         media
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        request  body      domain.PresignPhotoUploadDTO  true  "Declared file type and size"
+//	@Success      200      {object}  domain.PresignedPhotoUpload
+//	@Router       /staff/lots/uploads/presign [post]
+func (h *UploadHandler) PresignUpload(c *gin.Context) {
+	var req domain.PresignPhotoUploadDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	upload, err := h.photos.PresignUpload(c.Request.Context(), req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// FinalizeUpload validates a direct upload and queues rendition processing.
+//
+//	@Summary      Finalize a photo upload
+//	@Description  Checks the uploaded object (type, size, dimensions) and starts generating renditions in the background.
+//	@Description  Poll the upload until its status is READY to get the photo URL.
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Upload ID"
+//	@Success      202  {object}  domain.PhotoUploadSession
+//	@Router       /staff/lots/uploads/{id}/finalize [post]
+func (h *UploadHandler) FinalizeUpload(c *gin.Context) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload id format"})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	session, err := h.photos.FinalizeUpload(c.Request.Context(), uploadID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, session)
+}
+
+// GetUpload returns the status of a direct upload, with the rendition set once it is READY.
+//
+//	@Summary      Get photo upload status
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id   path      string  true  "Upload ID"
+//	@Success      200  {object}  domain.PhotoUploadSession
+//	@Router       /staff/lots/uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload id format"})
+		return
+	}
+
+	session, err := h.photos.GetUpload(c.Request.Context(), uploadID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
 // DeletePhotoRequest is the payload for deleting a photo.
 type DeletePhotoRequest struct {
 	URL string `json:"url" binding:"required,url"`
@@ -61,7 +150,7 @@ type DeletePhotoRequest struct {
 // DeletePhoto removes a previously uploaded image from storage.
 //
 //	@Summary      Delete a photo
-//	@Description  Removes the image from MinIO using its public URL.
+//	@Description  Removes the image and all its renditions from MinIO using its public URL.
 //	@Tags         media
 //	@Accept       json
 //	@Produce      json
@@ -76,10 +165,117 @@ func (h *UploadHandler) DeletePhoto(c *gin.Context) {
 		return
 	}
 
-	if err := h.storage.DeletePhoto(c.Request.Context(), req.URL); err != nil {
+	if err := h.photos.DeletePhoto(c.Request.Context(), req.URL); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete photo"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "photo deleted successfully"})
 }
+
+// ListOrphans reports unattached uploads older than the grace period without deleting them.
+//
+//	@Summary      Orphaned Photos Report
+//	@Description  Dry run of the orphan sweeper: photos that no lot, storage contract or order item references.
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.PhotoSweepReport
+//	@Router       /admin/photos/orphans [get]
+func (h *UploadHandler) ListOrphans(c *gin.Context) {
+	report, err := h.photos.SweepOrphans(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build orphan report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SweepOrphans deletes unattached uploads older than the grace period right away.
+//
+//	@Summary      Sweep Orphaned Photos
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.PhotoSweepReport
+//	@Router       /admin/photos/orphans/sweep [post]
+func (h *UploadHandler) SweepOrphans(c *gin.Context) {
+	report, err := h.photos.SweepOrphans(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sweep orphaned photos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListUnregisteredOrphans reports photos in storage that were never registered and that nothing references.
+//
+//	@Summary      Unregistered Photos Report
+//	@Description  Dry run of the one-off sweep over storage for photos uploaded before the upload registry existed.
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.PhotoSweepReport
+//	@Router       /admin/photos/orphans/unregistered [get]
+func (h *UploadHandler) ListUnregisteredOrphans(c *gin.Context) {
+	report, err := h.photos.SweepUnregisteredPhotos(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build unregistered photo report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SweepUnregisteredOrphans deletes photos in storage that were never registered and that nothing references.
+//
+//	@Summary      Sweep Unregistered Photos
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.PhotoSweepReport
+//	@Router       /admin/photos/orphans/unregistered/sweep [post]
+func (h *UploadHandler) SweepUnregisteredOrphans(c *gin.Context) {
+	report, err := h.photos.SweepUnregisteredPhotos(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sweep unregistered photos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StartReprocess re-renders all registered photos with the current watermark settings and converts legacy single files.
+//
+//	@Summary      Reprocess Photos
+//	@Description  Starts a background job; renditions are rewritten in place, so photo URLs do not change.
+//	@Description  Single lots/<id>.jpg files from before renditions existed become lots/<id>/ rendition sets, and lots,
+//	@Description  storage contracts and order items are moved to the new URL.
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      202  {object}  domain.PhotoReprocessStatus
+//	@Router       /admin/photos/reprocess [post]
+func (h *UploadHandler) StartReprocess(c *gin.Context) {
+	status, err := h.photos.StartReprocess(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// GetReprocessStatus returns the progress of the last reprocessing job.
+//
+//	@Summary      Photo Reprocessing Status
+//	@Tags         media
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Success      200  {object}  domain.PhotoReprocessStatus
+//	@Router       /admin/photos/reprocess [get]
+func (h *UploadHandler) GetReprocessStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.photos.ReprocessStatus())
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/horoshi10v/tires-shop/internal/domain"
+)
+
+type WarehouseLocationHandler struct {
+	service domain.WarehouseLocationService
+}
+
+func NewWarehouseLocationHandler(service domain.WarehouseLocationService) *WarehouseLocationHandler {
+	return &WarehouseLocationHandler{service: service}
+}
+
+// Create adds a zone, rack, shelf or bin to a warehouse.
+//
+//	@Summary      Create Warehouse Location
+//	@Tags         warehouse-locations
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                             true  "Warehouse ID"
+//	@Param        data  body      domain.CreateWarehouseLocationDTO  true  "Location details"
+//	@Success      201   {object}  map[string]interface{}
+//	@Router       /admin/warehouses/{id}/locations [post]
+func (h *WarehouseLocationHandler) Create(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+		return
+	}
+
+	var req domain.CreateWarehouseLocationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.service.CreateLocation(c.Request.Context(), warehouseID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "location created", "location_id": id})
+}
+
+// List retrieves all locations of a warehouse ordered by path.
+//
+//	@Summary      List Warehouse Locations
+//	@Tags         warehouse-locations
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Warehouse ID"
+//	@Success      200   {array}   domain.WarehouseLocation
+//	@Router       /staff/warehouses/{id}/locations [get]
+func (h *WarehouseLocationHandler) List(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+		return
+	}
+
+	locations, err := h.service.ListLocations(c.Request.Context(), warehouseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch warehouse locations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, locations)
+}
+
+// Update renames a location. Changing the code also updates the path of nested locations.
+//
+//	@Summary      Update Warehouse Location
+//	@Tags         warehouse-locations
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string                             true  "Location ID"
+//	@Param        data  body      domain.UpdateWarehouseLocationDTO  true  "Updated details"
+//	@Success      200   {object}  map[string]string
+//	@Router       /admin/warehouse-locations/{id} [put]
+func (h *WarehouseLocationHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+		return
+	}
+
+	var req domain.UpdateWarehouseLocationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdateLocation(c.Request.Context(), id, req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "location updated"})
+}
+
+// Delete removes an empty location.
+//
+//	@Summary      Delete Warehouse Location
+//	@Tags         warehouse-locations
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Location ID"
+//	@Success      200   {object}  map[string]string
+//	@Router       /admin/warehouse-locations/{id} [delete]
+func (h *WarehouseLocationHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+		return
+	}
+
+	if err := h.service.DeleteLocation(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "location deleted"})
+}
+
+// GetQR returns a printable QR label for a location.
+//
+//	@Summary      Warehouse Location QR Label
+//	@Tags         warehouse-locations
+//	@Produce      png
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Location ID"
+//	@Success      200   {file}    binary
+//	@Router       /staff/warehouse-locations/{id}/qr [get]
+func (h *WarehouseLocationHandler) GetQR(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+		return
+	}
+
+	pngBytes, err := h.service.GenerateLocationQR(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate qr code"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", pngBytes)
+}
+
+// MoveLot puts a lot into another location of the same warehouse.
+//
+//	@Summary      Move Lot
+//	@Description  Changes the bin/shelf of a lot and records the move in its history. Send null location_id to unassign.
+//	@Tags         warehouse-locations
+//	@Accept       json
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string             true  "Lot ID"
+//	@Param        data  body      domain.MoveLotDTO  true  "Target location"
+//	@Success      200   {object}  map[string]string
+//	@Router       /staff/lots/{id}/move [post]
+func (h *WarehouseLocationHandler) MoveLot(c *gin.Context) {
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lot id format"})
+		return
+	}
+
+	var req domain.MoveLotDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(uuid.UUID)
+
+	if err := h.service.MoveLot(c.Request.Context(), lotID, req, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lot moved successfully"})
+}
+
+// ListLotMoves returns the placement history of a lot, newest first.
+//
+//	@Summary      Lot Location History
+//	@Tags         warehouse-locations
+//	@Produce      json
+//	@Security     RoleAuth
+//	@Param        id    path      string  true  "Lot ID"
+//	@Success      200   {array}   domain.LotLocationMoveResponse
+//	@Router       /staff/lots/{id}/moves [get]
+func (h *WarehouseLocationHandler) ListLotMoves(c *gin.Context) {
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lot id format"})
+		return
+	}
+
+	moves, err := h.service.ListLotMoves(c.Request.Context(), lotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch lot moves"})
+		return
+	}
+
+	c.JSON(http.StatusOK, moves)
+}